@@ -0,0 +1,199 @@
+package main
+
+// Operator commands for platform administration.
+//
+// Usage (from backend/ directory, with the same env / config.yaml as the API):
+//
+//   go run ./cmd/admin bootstrap -email admin@example.com -name "Platform Admin"
+//
+// bootstrap creates the first admin account in the users table. The password
+// is read from -password, then ADMIN_PASSWORD; if neither is set a random one
+// is generated and printed once. The account has to change its password on
+// first login. The command refuses to run once any admin exists.
+//
+//   go run ./cmd/admin jwt-keygen -dir keys -alg EdDSA
+//
+// jwt-keygen writes a new token signing key (EdDSA or RS256) into the
+// JWT_KEYS_DIR directory. Running instances publish it in their JWKS on the
+// next reload and start signing with it after JWT_KEY_ACTIVATION_DELAY.
+//
+//   go run ./cmd/admin config print -redacted
+//
+// config print writes the effective configuration (defaults, config file,
+// environment and *_FILE secrets) as KEY=value lines, with secrets masked
+// when -redacted is given, then reports any setting the API would refuse and
+// exits with status 1 if there is one.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+	"github.com/example/global-trade-hub/backend/internal/database"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "bootstrap":
+		bootstrap(os.Args[2:])
+	case "jwt-keygen":
+		jwtKeygen(os.Args[2:])
+	case "config":
+		if len(os.Args) < 3 || os.Args[2] != "print" {
+			usage()
+			os.Exit(2)
+		}
+		configPrint(os.Args[3:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin bootstrap -email <email> [-name <full name>] [-password <password>]")
+	fmt.Fprintln(os.Stderr, "       admin jwt-keygen [-dir <keys dir>] [-alg EdDSA|RS256]")
+	fmt.Fprintln(os.Stderr, "       admin config print [-redacted]")
+}
+
+func bootstrap(args []string) {
+	fs := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	email := fs.String("email", "", "admin email address (required)")
+	name := fs.String("name", "Platform Administrator", "admin full name")
+	password := fs.String("password", "", "initial password (default: $ADMIN_PASSWORD or a generated one)")
+	_ = fs.Parse(args)
+
+	if *email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	generated := false
+	if *password == "" {
+		*password = os.Getenv("ADMIN_PASSWORD")
+	}
+	if *password == "" {
+		p, err := randomPassword()
+		if err != nil {
+			log.Fatalf("failed to generate password: %v", err)
+		}
+		*password = p
+		generated = true
+	}
+	if len(*password) < 8 {
+		log.Fatalf("password must be at least 8 characters")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// Make sure the users table (and its newer columns) exist.
+	if err := database.AutoMigrate(cfg); err != nil {
+		log.Fatalf("failed to run database migrations: %v", err)
+	}
+
+	db, err := database.OpenMySQL(cfg)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	keys, err := jwtkeys.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
+
+	authService := auth.NewService(
+		auth.NewMySQLUserRepository(db),
+		auth.NewMySQLRefreshTokenRepository(db),
+		auth.NewMySQLActionTokenRepository(db),
+		auth.NewMySQLMFARepository(db),
+		keys,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	u, err := authService.BootstrapAdmin(ctx, auth.BootstrapAdminInput{
+		Email:    *email,
+		Password: *password,
+		FullName: *name,
+	})
+	if err != nil {
+		if errors.Is(err, auth.ErrAdminAlreadyExists) {
+			log.Fatalf("bootstrap refused: %v", err)
+		}
+		log.Fatalf("failed to create admin: %v", err)
+	}
+
+	log.Printf("created admin %s (%s)", u.Email, u.ID)
+	if generated {
+		fmt.Printf("initial password: %s\n", *password)
+	}
+	log.Println("the password must be changed on first login")
+}
+
+func jwtKeygen(args []string) {
+	fs := flag.NewFlagSet("jwt-keygen", flag.ExitOnError)
+	dir := fs.String("dir", os.Getenv("JWT_KEYS_DIR"), "key directory (default: $JWT_KEYS_DIR)")
+	alg := fs.String("alg", jwtkeys.AlgEdDSA, "signing algorithm: EdDSA or RS256")
+	_ = fs.Parse(args)
+
+	if *dir == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	kid, err := jwtkeys.GenerateKeyFile(*dir, *alg)
+	if err != nil {
+		log.Fatalf("failed to generate key: %v", err)
+	}
+	log.Printf("created %s key %s in %s", *alg, kid, *dir)
+}
+
+func configPrint(args []string) {
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	redact := fs.Bool("redacted", false, "mask secrets")
+	_ = fs.Parse(args)
+
+	cfg, err := config.Read()
+	if err != nil {
+		log.Fatalf("failed to read config: %v", err)
+	}
+	if err := cfg.WriteEnv(os.Stdout, *redact); err != nil {
+		log.Fatalf("failed to print config: %v", err)
+	}
+
+	var verr *config.ValidationError
+	if err := cfg.Validate(); errors.As(err, &verr) {
+		for _, fe := range verr.Errors {
+			fmt.Fprintln(os.Stderr, "invalid:", fe)
+		}
+		os.Exit(1)
+	}
+}
+
+// randomPassword returns a URL-safe random password with 144 bits of entropy.
+func randomPassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
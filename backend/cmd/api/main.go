@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,7 +13,9 @@ import (
 
 	"github.com/example/global-trade-hub/backend/internal/config"
 	"github.com/example/global-trade-hub/backend/internal/database"
+	"github.com/example/global-trade-hub/backend/internal/domain/account"
 	"github.com/example/global-trade-hub/backend/internal/domain/admin"
+	"github.com/example/global-trade-hub/backend/internal/domain/apikey"
 	"github.com/example/global-trade-hub/backend/internal/domain/auth"
 	"github.com/example/global-trade-hub/backend/internal/domain/category"
 	"github.com/example/global-trade-hub/backend/internal/domain/cms"
@@ -26,6 +30,16 @@ import (
 	"github.com/example/global-trade-hub/backend/internal/domain/subscription"
 	"github.com/example/global-trade-hub/backend/internal/domain/supplier"
 	"github.com/example/global-trade-hub/backend/internal/domain/verification"
+	"github.com/example/global-trade-hub/backend/internal/health"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/idempotency"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+	"github.com/example/global-trade-hub/backend/internal/mail"
+	"github.com/example/global-trade-hub/backend/internal/metrics"
+	"github.com/example/global-trade-hub/backend/internal/oidc"
+	"github.com/example/global-trade-hub/backend/internal/ratelimit"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 	httpi "github.com/example/global-trade-hub/backend/internal/http"
 )
 
@@ -36,36 +50,153 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
-	// Initialize base logger
-	logger := log.New(os.Stdout, "[api] ", log.LstdFlags|log.Lshortfile)
+	// Initialize base logger; request handlers get a copy carrying the
+	// request ID and caller (see logging.FromContext).
+	logger, err := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("failed to configure logging: %v", err)
+	}
+	slog.SetDefault(logger)
+	logger.Info("configuration loaded", "env", cfg.AppEnv, "file", cfg.ConfigFile)
+
+	// Tracing: span exporter and W3C trace context propagation.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal(logger, "failed to configure tracing", err)
+	}
 
 	// Run automatic database migrations (ensures core tables like users exist)
 	if err := database.AutoMigrate(cfg); err != nil {
-		logger.Fatalf("failed to run database migrations: %v", err)
+		fatal(logger, "failed to run database migrations", err)
 	}
 
 	// Initialize database connection
 	db, err := database.OpenMySQL(cfg)
 	if err != nil {
-		logger.Fatalf("failed to connect to database: %v", err)
+		fatal(logger, "failed to connect to database", err)
 	}
 	defer db.Close()
 
+	// Prometheus metrics: HTTP traffic, connection pool and business counters.
+	m := metrics.New(db)
+	if cfg.MetricsAddr == "" && cfg.MetricsToken == "" {
+		logger.Warn("METRICS_ADDR and METRICS_TOKEN not set: metrics are collected but not exposed")
+	}
+
+	// Readiness checks; subsystems below add their own.
+	probes := health.New(cfg.HealthCheckTimeout)
+	probes.WithToken(cfg.HealthToken)
+	probes.Register("database", health.Ping(db))
+	probes.Register("schema", health.CheckerFunc(func(ctx context.Context) error {
+		return database.CheckSchema(ctx, db)
+	}))
+	probes.Register("database_pool", health.PoolSaturation(db, 0.9))
+
 	// Initialize repositories & services (domain layer)
 	authRepo := auth.NewMySQLUserRepository(db)
-	authService := auth.NewService(authRepo, cfg.JWTSecret, cfg.JWTIssuer)
+	refreshTokenRepo := auth.NewMySQLRefreshTokenRepository(db)
+	actionTokenRepo := auth.NewMySQLActionTokenRepository(db)
+	mfaRepo := auth.NewMySQLMFARepository(db)
+	// Token signing keys; rotated by adding/removing files in JWT_KEYS_DIR.
+	keys, err := jwtkeys.New(cfg)
+	if err != nil {
+		fatal(logger, "failed to load JWT keys", err)
+	}
+	if keys.Symmetric() {
+		logger.Warn("JWT_KEYS_DIR not set: signing tokens with JWT_SECRET (HS256); other services cannot verify them via JWKS")
+	}
+	keysCtx, stopKeys := context.WithCancel(context.Background())
+	defer stopKeys()
+	if cfg.JWTKeysDir != "" && cfg.JWTKeysReloadInterval > 0 {
+		watcher := health.NewHeartbeat(3 * cfg.JWTKeysReloadInterval)
+		probes.Register("jwt_keys_watcher", watcher)
+		keysCtx = health.WithHeartbeat(keysCtx, watcher)
+	}
+	go keys.Watch(keysCtx, cfg.JWTKeysReloadInterval, logger)
+
+	authService := auth.NewService(authRepo, refreshTokenRepo, actionTokenRepo, mfaRepo, keys)
+	authService.WithTokenTTL(cfg.JWTAccessTokenTTL, cfg.JWTRefreshTokenTTL)
+	authService.WithMFAPolicy(cfg.MFAIssuer, cfg.MFARequiredRoles)
+
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		fatal(logger, "failed to configure mailer", err)
+	}
+	authService.WithMailer(mailer, cfg.AppBaseURL)
+
+	oidcProviders, err := oidc.New(cfg)
+	if err != nil {
+		fatal(logger, "failed to configure OIDC providers", err)
+	}
+	authService.WithOIDC(oidcProviders, auth.NewMySQLIdentityRepository(db))
+	authService.WithInvitations(auth.NewMySQLInvitationRepository(db))
+	authService.WithImpersonation(auth.NewMySQLImpersonationRepository(db), cfg.ImpersonationTokenTTL)
+
+	throttlePolicy := auth.DefaultLoginThrottlePolicy()
+	throttlePolicy.Window = cfg.LoginAttemptWindow
+	throttlePolicy.MaxAccountFailures = cfg.LoginMaxAttempts
+	throttlePolicy.MaxIPFailures = cfg.LoginMaxAttemptsPerIP
+	throttlePolicy.LockoutDuration = cfg.LoginLockoutDuration
+	var attemptStore auth.LoginAttemptStore
+	switch cfg.LoginThrottleStore {
+	case "mysql":
+		attemptStore = auth.NewMySQLLoginAttemptStore(db, throttlePolicy.Window)
+	case "memory", "":
+		attemptStore = auth.NewMemoryLoginAttemptStore(throttlePolicy.Window)
+	default:
+		fatal(logger, "invalid configuration", fmt.Errorf("unknown LOGIN_THROTTLE_STORE %q", cfg.LoginThrottleStore))
+	}
+	authService.WithLoginThrottle(attemptStore, throttlePolicy)
+	authService.WithMetrics(m)
+
+	// API rate limits; "none" leaves the API unthrottled.
+	var rateLimitStore ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "mysql":
+		rateLimitStore = ratelimit.NewMySQLStore(db, 24*time.Hour)
+	case "memory", "":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "none":
+	default:
+		fatal(logger, "invalid configuration", fmt.Errorf("unknown RATE_LIMIT_STORE %q", cfg.RateLimitStore))
+	}
+
+	// Idempotency-Key records; "none" ignores the header.
+	var idempotencyStore idempotency.Store
+	switch cfg.IdempotencyStore {
+	case "mysql":
+		idempotencyStore = idempotency.NewMySQLStore(db)
+	case "memory", "":
+		idempotencyStore = idempotency.NewMemoryStore()
+	case "none":
+	default:
+		fatal(logger, "invalid configuration", fmt.Errorf("unknown IDEMPOTENCY_STORE %q", cfg.IdempotencyStore))
+	}
+
+	// Public catalog responses; services drop the entities they change.
+	cache := httpcache.New(cfg.HTTPCacheEntries)
+	cache.WithMetrics(m)
 
 	productRepo := product.NewMySQLProductRepository(db)
 	productService := product.NewService(productRepo)
+	productService.WithCache(cache)
 
 	supplierRepo := supplier.NewMySQLSupplierRepository(db)
-	supplierService := supplier.NewService(supplierRepo)
+	supplierService := supplier.NewService(supplierRepo, supplier.NewMySQLMemberRepository(db), authRepo)
+	supplierService.WithInvitations(supplier.NewMySQLInvitationRepository(db))
+	supplierService.WithMailer(mailer, cfg.AppBaseURL)
+	supplierService.WithCache(cache)
+
+	apiKeyRepo := apikey.NewMySQLAPIKeyRepository(db)
+	apiKeyService := apikey.NewService(apiKeyRepo, authRepo, supplierService)
 
 	orderRepo := order.NewMySQLOrderRepository(db)
 	orderService := order.NewService(orderRepo)
+	orderService.WithMetrics(m)
 
 	rfqRepo := rfq.NewMySQLRFQRepository(db)
 	rfqService := rfq.NewService(rfqRepo)
+	rfqService.WithMetrics(m)
 
 	notificationRepo := notification.NewMySQLNotificationRepository(db)
 	notificationService := notification.NewService(notificationRepo)
@@ -90,16 +221,32 @@ func main() {
 	favoriteRepo := favorite.NewMySQLFavoriteRepository(db)
 	favoriteService := favorite.NewService(favoriteRepo)
 
-	adminService := admin.NewService(db)
+	adminService := admin.NewService(db, authService)
+	adminService.WithCache(cache)
 
 	cmsRepo := cms.NewMySQLCMSRepository(db)
 	cmsService := cms.NewService(cmsRepo)
 
+	// Accounts whose deletion grace period has passed are anonymized hourly.
+	accountService := account.NewService(db, authService, cfg.AccountDeletionGracePeriod)
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	purger := health.NewHeartbeat(2 * time.Hour)
+	probes.Register("account_purger", purger)
+	go accountService.RunPurger(health.WithHeartbeat(purgeCtx, purger), time.Hour)
+
 	// Build HTTP server (Gin, routes, middlewares)
 	router := httpi.NewRouter(
 		cfg,
 		logger,
+		m,
+		probes,
+		rateLimitStore,
+		idempotencyStore,
+		keys,
+		cache,
 		authService,
+		apiKeyService,
 		productService,
 		supplierService,
 		orderService,
@@ -114,6 +261,7 @@ func main() {
 		favoriteService,
 		adminService,
 		cmsService,
+		accountService,
 	)
 
 	srv := &http.Server{
@@ -126,24 +274,62 @@ func main() {
 
 	// Start server in background
 	go func() {
-		logger.Printf("starting HTTP server on %s", cfg.HTTPAddress())
+		logger.Info("starting HTTP server", "addr", cfg.HTTPAddress())
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("server error: %v", err)
+			fatal(logger, "server error", err)
 		}
 	}()
 
+	// Metrics listener, meant to be reachable only from the monitoring network.
+	var metricsSrv *http.Server
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m.Handler(cfg.MetricsToken))
+		metricsSrv = &http.Server{
+			Addr:              cfg.MetricsAddr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Info("starting metrics server", "addr", cfg.MetricsAddr)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal(logger, "metrics server error", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	<-stop
 
-	logger.Println("shutting down server...")
+	// Fail readiness first and give load balancers time to stop routing
+	// requests here; in-flight and newly routed ones are still served.
+	probes.Drain()
+	logger.Info("draining before shutdown", "delay", cfg.ShutdownDrainDelay)
+	time.Sleep(cfg.ShutdownDrainDelay)
+
+	logger.Info("shutting down server")
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Printf("graceful shutdown failed: %v", err)
+		logger.Error("graceful shutdown failed", "error", err)
 	} else {
-		logger.Println("server stopped gracefully")
+		logger.Info("server stopped gracefully")
 	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			logger.Error("metrics server shutdown failed", "error", err)
+		}
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("failed to flush traces", "error", err)
+	}
+}
+
+// fatal logs msg with err and exits.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
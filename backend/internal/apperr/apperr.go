@@ -0,0 +1,259 @@
+// Package apperr defines the errors the API reports to clients. An *Error
+// carries the HTTP status, a stable machine-readable code the frontend
+// translates, and a message that is safe to show. Any other error is an
+// internal one: it is logged, but the client only sees a generic 500.
+//
+// Handlers report errors with c.Error(err) and return; the ErrorHandler
+// middleware renders them as application/problem+json (RFC 9457).
+package apperr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Generic codes, used when no more specific code applies.
+const (
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeDuplicate    = "duplicate"
+	CodeRateLimited  = "rate_limited"
+	CodeTimeout      = "timeout"
+	CodeInternal     = "internal_error"
+)
+
+// Error is an error meant for the API client.
+type Error struct {
+	// Status is the HTTP status code.
+	Status int
+	// Code identifies the error, e.g. "email_already_used". Codes are part of
+	// the API: never change or reuse one.
+	Code string
+	// Message is a human-readable description in English.
+	Message string
+	// Fields lists the offending fields of a validation error.
+	Fields []FieldError
+	// RetryAfter is sent as the Retry-After header when set.
+	RetryAfter time.Duration
+	// Err is the underlying error, logged but not sent.
+	Err error
+
+	// base is the error this one was derived from by WithStatus or Detailf.
+	base *Error
+}
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	// Field is the JSON path of the field, e.g. "items[0].quantity".
+	Field string `json:"field"`
+	// Code is the failed rule, e.g. "required", "min", "email" or "type".
+	Code string `json:"code"`
+	// Param is the rule's parameter, e.g. "8" for min=8.
+	Param string `json:"param,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether e was derived from target, so that errors.Is matches
+// the sentinel behind a WithStatus or Detailf copy.
+func (e *Error) Is(target error) bool {
+	return e.base != nil && target == error(e.base)
+}
+
+// derive returns a copy of e that errors.Is still matches against e.
+func (e *Error) derive() *Error {
+	c := *e
+	c.base = e
+	if e.base != nil {
+		c.base = e.base
+	}
+	return &c
+}
+
+// Detailf returns a copy of e with details appended to its message, e.g.
+// ErrInvalidScope.Detailf("%q", scope).
+func (e *Error) Detailf(format string, args ...any) *Error {
+	c := e.derive()
+	c.Message = e.Message + ": " + fmt.Sprintf(format, args...)
+	return c
+}
+
+// New returns an error with the given status, code and message.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest returns a 400 error.
+func BadRequest(code, message string) *Error { return New(http.StatusBadRequest, code, message) }
+
+// Unauthorized returns a 401 error.
+func Unauthorized(code, message string) *Error { return New(http.StatusUnauthorized, code, message) }
+
+// Forbidden returns a 403 error.
+func Forbidden(code, message string) *Error { return New(http.StatusForbidden, code, message) }
+
+// NotFound returns a 404 error.
+func NotFound(code, message string) *Error { return New(http.StatusNotFound, code, message) }
+
+// Conflict returns a 409 error.
+func Conflict(code, message string) *Error { return New(http.StatusConflict, code, message) }
+
+// Validation returns a 400 error listing the invalid fields.
+func Validation(message string, fields ...FieldError) *Error {
+	e := New(http.StatusBadRequest, CodeValidation, message)
+	e.Fields = fields
+	return e
+}
+
+// RateLimited returns a 429 error asking the client to retry after d.
+func RateLimited(d time.Duration) *Error {
+	e := New(http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded")
+	e.RetryAfter = d
+	return e
+}
+
+// ErrMissingClaims is reported by handlers of protected routes when the
+// request carries no claims, i.e. JWTAuth did not run.
+var ErrMissingClaims = Unauthorized(CodeUnauthorized, "missing claims")
+
+// WithStatus returns a copy of the client error in err's chain with another
+// status, for handlers where the usual status does not fit (e.g. an invalid
+// two-factor code is 400 when enrolling but 401 at login). Other errors are
+// returned unchanged.
+func WithStatus(err error, status int) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	c := e.derive()
+	c.Status = status
+	return c
+}
+
+// Invalid turns an error from c.ShouldBind* into a validation error naming
+// the offending fields.
+func Invalid(err error) *Error {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		fields := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			fields = append(fields, FieldError{Field: fieldPath(fe), Code: fe.Tag(), Param: fe.Param()})
+		}
+		e := Validation("request validation failed", fields...)
+		e.Err = err
+		return e
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		e := Validation("request validation failed", FieldError{Field: typeErr.Field, Code: "type", Param: typeErr.Type.String()})
+		e.Err = err
+		return e
+	}
+
+	var numErr *strconv.NumError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return &Error{Status: http.StatusBadRequest, Code: "empty_body", Message: "request body is empty", Err: err}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{Status: http.StatusBadRequest, Code: "malformed_json", Message: "request body is not valid JSON", Err: err}
+	case errors.As(err, &numErr):
+		return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Message: "invalid number " + strconv.Quote(numErr.Num), Err: err}
+	}
+	return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Message: "malformed request", Err: err}
+}
+
+// fieldPath returns the field's path without the top-level struct name. Field
+// names are JSON names when the validator was set up with
+// RegisterJSONFieldNames.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	for i := 0; i < len(ns); i++ {
+		if ns[i] == '.' {
+			return ns[i+1:]
+		}
+	}
+	return fe.Field()
+}
+
+// RegisterJSONFieldNames makes v report fields by their JSON names, so that
+// FieldError.Field matches what the client sent.
+func RegisterJSONFieldNames(v *validator.Validate) {
+	v.RegisterTagNameFunc(jsonFieldName)
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		if form, _, _ := strings.Cut(f.Tag.Get("form"), ","); form != "" && form != "-" {
+			return form
+		}
+		return f.Name
+	}
+	return name
+}
+
+// IsDuplicateKey reports whether err is a MySQL unique key violation.
+func IsDuplicateKey(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == mysqlDuplicateEntry
+}
+
+// MySQL error numbers mapped by From.
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+)
+
+// From returns the client error for err: the *Error in its chain if any, a
+// 409 for unique key violations, a 504 for timeouts, and otherwise a generic
+// 500 that hides err (which it wraps, for logging).
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var me *mysql.MySQLError
+	if errors.As(err, &me) {
+		switch me.Number {
+		case mysqlDuplicateEntry:
+			return &Error{Status: http.StatusConflict, Code: CodeDuplicate, Message: "a record with the same unique value already exists", Err: err}
+		case mysqlRowIsReferenced:
+			return &Error{Status: http.StatusConflict, Code: "still_referenced", Message: "the record is still referenced by other records", Err: err}
+		case mysqlNoReferencedRow:
+			return &Error{Status: http.StatusBadRequest, Code: "invalid_reference", Message: "a referenced record does not exist", Err: err}
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Status: http.StatusGatewayTimeout, Code: CodeTimeout, Message: "the request timed out", Err: err}
+	}
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+}
@@ -0,0 +1,313 @@
+// Package authz is the permission model of the API: roles are granted
+// permissions, routes require permissions (see Policy and Enforce), and
+// handlers combine permissions with resource ownership (see Allowed).
+package authz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+// Permission names an action as "<resource>.<action>".
+type Permission string
+
+// Route-level markers. Public routes need no token; Authenticated routes
+// need a valid token but no particular permission (handlers scope them to
+// the caller's own data).
+const (
+	Public        Permission = "public"
+	Authenticated Permission = "authenticated"
+)
+
+const (
+	DashboardView    Permission = "dashboard.view"
+	UsersManage      Permission = "users.manage"
+	UsersImpersonate Permission = "users.impersonate"
+	StaffManage      Permission = "staff.manage"
+
+	ProductsWrite  Permission = "products.write"
+	ProductsManage Permission = "products.manage"
+
+	SuppliersWrite        Permission = "suppliers.write"
+	SuppliersManage       Permission = "suppliers.manage"
+	SupplierMembersManage Permission = "suppliers.members"
+	SuppliersDelete       Permission = "suppliers.delete"
+
+	OrdersCreate       Permission = "orders.create"
+	OrdersView         Permission = "orders.view"
+	OrdersUpdateStatus Permission = "orders.update_status"
+	OrdersManage       Permission = "orders.manage"
+
+	RFQsCreate  Permission = "rfqs.create"
+	RFQsRespond Permission = "rfqs.respond"
+	RFQsManage  Permission = "rfqs.manage"
+
+	ReviewsCreate Permission = "reviews.create"
+
+	VerificationsSubmit Permission = "verifications.submit"
+	VerificationsReview Permission = "verifications.review"
+
+	SubscriptionsPurchase Permission = "subscriptions.purchase"
+	SubscriptionsManage   Permission = "subscriptions.manage"
+
+	MessagesSend   Permission = "messages.send"
+	MessagesManage Permission = "messages.manage"
+)
+
+// All lists every permission; admins are granted all of them.
+var All = []Permission{
+	DashboardView, UsersManage, UsersImpersonate, StaffManage,
+	ProductsWrite, ProductsManage,
+	SuppliersWrite, SuppliersManage, SupplierMembersManage, SuppliersDelete,
+	OrdersCreate, OrdersView, OrdersUpdateStatus, OrdersManage,
+	RFQsCreate, RFQsRespond, RFQsManage,
+	ReviewsCreate,
+	VerificationsSubmit, VerificationsReview,
+	SubscriptionsPurchase, SubscriptionsManage,
+	MessagesSend, MessagesManage,
+}
+
+var buyerPermissions = []Permission{OrdersCreate, RFQsCreate, ReviewsCreate}
+
+// rolePermissions grants permissions to the roles in auth.UserRole. Roles not
+// listed (e.g. visitor) only reach Public and Authenticated routes.
+var rolePermissions = map[string][]Permission{
+	"admin": All,
+	"supplier": {
+		ProductsWrite,
+		SuppliersWrite,
+		OrdersUpdateStatus,
+		RFQsRespond,
+		VerificationsSubmit,
+		SubscriptionsPurchase,
+	},
+	"buyer":  buyerPermissions,
+	"market": buyerPermissions,
+
+	// Staff sub-roles (invited by an admin) each cover one area of the
+	// admin panel.
+	"moderator": {
+		DashboardView,
+		ProductsManage,
+		SuppliersManage,
+		RFQsManage,
+		MessagesManage,
+	},
+	"kyc_reviewer": {
+		DashboardView,
+		SuppliersManage,
+		VerificationsReview,
+	},
+	"finance": {
+		DashboardView,
+		OrdersManage,
+		SubscriptionsManage,
+	},
+}
+
+var grants = func() map[string]map[Permission]bool {
+	out := make(map[string]map[Permission]bool, len(rolePermissions))
+	for role, perms := range rolePermissions {
+		set := make(map[Permission]bool, len(perms))
+		for _, p := range perms {
+			set[p] = true
+		}
+		out[role] = set
+	}
+	return out
+}()
+
+// Can reports whether role has permission p.
+func Can(role string, p Permission) bool {
+	switch p {
+	case Public:
+		return true
+	case Authenticated:
+		return role != ""
+	}
+	return grants[role][p]
+}
+
+// Permissions returns the permissions granted to role, sorted.
+func Permissions(role string) []Permission {
+	out := make([]Permission, 0, len(rolePermissions[role]))
+	out = append(out, rolePermissions[role]...)
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Allowed is the ownership check for a single resource: the caller may act
+// if they own it (their user ID is one of ownerIDs) or hold p, which grants
+// access to everyone's resources of that kind.
+func Allowed(claims *middleware.Claims, p Permission, ownerIDs ...string) bool {
+	for _, id := range ownerIDs {
+		if id != "" && id == claims.UserID {
+			return true
+		}
+	}
+	return Can(claims.Role, p)
+}
+
+// SupplierResolver maps a user to the supplier organization they belong to
+// and their team role in it. It returns an empty ID for users who are not a
+// member of any organization.
+type SupplierResolver interface {
+	SupplierMembership(ctx context.Context, userID string) (supplierID, role string, err error)
+}
+
+// memberPermissions grants permissions to the team roles of a supplier
+// organization (see supplier.MemberRole). They apply only to that
+// organization's resources, on top of the user's own role.
+var memberPermissions = map[string][]Permission{
+	"owner": {
+		SuppliersWrite, SuppliersDelete, SupplierMembersManage, ProductsWrite, OrdersView,
+		OrdersUpdateStatus, RFQsRespond, MessagesSend,
+	},
+	"manager": {
+		SuppliersWrite, SupplierMembersManage, ProductsWrite, OrdersView,
+		OrdersUpdateStatus, RFQsRespond, MessagesSend,
+	},
+	"sales":  {OrdersView, OrdersUpdateStatus, RFQsRespond, MessagesSend},
+	"viewer": {OrdersView},
+}
+
+// MemberCan reports whether team role grants p within its organization.
+func MemberCan(role string, p Permission) bool {
+	for _, q := range memberPermissions[role] {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ActsForSupplier reports whether the caller may use p on behalf of
+// supplierID, i.e. is a member of it whose team role grants p. Older rows
+// store the owning user's ID in supplier_id columns; that user is treated as
+// the owner.
+func ActsForSupplier(ctx context.Context, suppliers SupplierResolver, claims *middleware.Claims, supplierID string, p Permission) (bool, error) {
+	if supplierID == "" {
+		return false, nil
+	}
+	if supplierID == claims.UserID {
+		return MemberCan("owner", p), nil
+	}
+	own, role, err := suppliers.SupplierMembership(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	return own != "" && own == supplierID && MemberCan(role, p), nil
+}
+
+// Errors reported by handlers whose own ownership or role checks fail.
+var (
+	// ErrAccessDenied is reported when the caller may not act on a resource.
+	ErrAccessDenied = apperr.Forbidden("access_denied", "access denied")
+	// ErrAdminRequired is reported for staff-only operations.
+	ErrAdminRequired = apperr.Forbidden("admin_required", "admin access required")
+	// ErrTeamRoleForbidden is reported when the caller's team role in their
+	// supplier organization does not grant the permission.
+	ErrTeamRoleForbidden = apperr.Forbidden("team_role_forbidden", "your team role does not allow this")
+	// ErrSupplierProfileRequired is reported when a supplier operation is
+	// attempted before the caller has a supplier organization.
+	ErrSupplierProfileRequired = apperr.Forbidden("supplier_profile_required", "create a supplier profile first")
+)
+
+// Policy maps "METHOD /full/route/path" (gin's FullPath) to the permission
+// the route requires. Every route under the API prefix must be listed.
+type Policy map[string]Permission
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// Require returns the permission for a route and whether it is listed.
+func (p Policy) Require(method, path string) (Permission, bool) {
+	perm, ok := p[routeKey(method, path)]
+	return perm, ok
+}
+
+// Verify checks the policy against the routes actually registered under
+// prefix: every route must have an entry and every entry must match a route.
+// It is the route/permission matrix check run when the router is built.
+func (p Policy) Verify(routes gin.RoutesInfo, prefix string) error {
+	registered := make(map[string]bool, len(routes))
+	var problems []string
+	for _, r := range routes {
+		if !strings.HasPrefix(r.Path, prefix) {
+			continue
+		}
+		key := routeKey(r.Method, r.Path)
+		registered[key] = true
+		perm, ok := p[key]
+		if !ok {
+			problems = append(problems, "no policy for "+key)
+			continue
+		}
+		if !known(perm) {
+			problems = append(problems, fmt.Sprintf("unknown permission %q for %s", perm, key))
+		}
+	}
+	for key := range p {
+		if !registered[key] {
+			problems = append(problems, "policy for unregistered route "+key)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("authz: route policy mismatch:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func known(p Permission) bool {
+	if p == Public || p == Authenticated {
+		return true
+	}
+	for _, q := range All {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Enforce checks the matched route's permission against the caller's role.
+// It runs after JWTAuth; routes missing from the policy are denied.
+func Enforce(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perm, ok := policy.Require(c.Request.Method, c.FullPath())
+		if !ok {
+			middleware.Abort(c, middleware.ErrInsufficientPermissions)
+			return
+		}
+		if perm == Public {
+			c.Next()
+			return
+		}
+
+		raw, exists := c.Get("claims")
+		if !exists {
+			middleware.Abort(c, apperr.ErrMissingClaims)
+			return
+		}
+		claims, ok := raw.(*middleware.Claims)
+		if !ok {
+			middleware.Abort(c, apperr.Forbidden(apperr.CodeForbidden, "invalid auth claims"))
+			return
+		}
+		if !Can(claims.Role, perm) {
+			middleware.Abort(c, middleware.ErrInsufficientPermissions)
+			return
+		}
+
+		c.Next()
+	}
+}
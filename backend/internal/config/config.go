@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -9,69 +11,247 @@ import (
 )
 
 // Config holds all runtime configuration for the API.
-// It is intentionally flat to keep Viper bindings simple.
+// It is intentionally flat to keep Viper bindings simple. The env tag names
+// the environment variable of each field; secret fields can also be read
+// from the file named by <VAR>_FILE and are masked by WriteEnv.
 type Config struct {
-	AppEnv string
-
-	HTTPHost string
-	HTTPPort int
-
-	MySQLHost     string
-	MySQLPort     int
-	MySQLUser     string
-	MySQLPassword string
-	MySQLDB       string
-	MySQLParams   string // optional, e.g. "parseTime=true&loc=Local"
-
-	JWTSecret            string
-	JWTIssuer            string
-	JWTAccessTokenTTL    time.Duration
-	JWTRefreshTokenTTL   time.Duration
-	CORSAllowedOrigins   []string
-	CORSAllowedMethods   []string
-	CORSAllowedHeaders   []string
-	CORSAllowCredentials bool
+	// AppEnv is development, test, staging or production; production
+	// refuses insecure settings (see Validate).
+	AppEnv string `env:"APP_ENV"`
+	// ConfigFile is the config file that was read, if any.
+	ConfigFile string `env:"-"`
+
+	// LogLevel is debug, info, warn or error; LogFormat is json or text.
+	LogLevel  string `env:"LOG_LEVEL"`
+	LogFormat string `env:"LOG_FORMAT"`
+
+	HTTPHost string `env:"HTTP_HOST"`
+	HTTPPort int    `env:"HTTP_PORT"`
+
+	// MetricsAddr, when set, serves /metrics on its own listener (e.g.
+	// "127.0.0.1:9090"). MetricsToken, when set, also serves it on the main
+	// port to requests bearing the token. With neither, metrics are not exposed.
+	MetricsAddr  string `env:"METRICS_ADDR"`
+	MetricsToken string `env:"METRICS_TOKEN" secret:"true"`
+
+	// HealthToken, when set, lets requests bearing it get the result of every
+	// readiness check from /readyz. HealthCheckTimeout bounds each check.
+	// ShutdownDrainDelay is how long /readyz fails before the server stops
+	// accepting connections, for load balancers to notice.
+	HealthToken        string        `env:"HEALTH_TOKEN" secret:"true"`
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT"`
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY"`
+
+	// TracingExporter is otlp, stdout or none. Spans are sent over OTLP/HTTP
+	// to TracingOTLPEndpoint (e.g. "http://otel-collector:4318"); a fraction
+	// TracingSampleRatio of new traces is kept, while incoming sampled
+	// traceparent headers are always honored.
+	TracingExporter     string  `env:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO"`
+	TracingServiceName  string  `env:"TRACING_SERVICE_NAME"`
+
+	MySQLHost     string `env:"MYSQL_HOST"`
+	MySQLPort     int    `env:"MYSQL_PORT"`
+	MySQLUser     string `env:"MYSQL_USER"`
+	MySQLPassword string `env:"MYSQL_PASSWORD" secret:"true"`
+	MySQLDB       string `env:"MYSQL_DB"`
+	MySQLParams   string `env:"MYSQL_PARAMS"` // optional, e.g. "parseTime=true&loc=Local"
+
+	JWTSecret   string `env:"JWT_SECRET" secret:"true"`
+	JWTIssuer   string `env:"JWT_ISSUER"`
+	JWTAudience string `env:"JWT_AUDIENCE"`
+	// JWTKeysDir holds the PEM signing keys (RS256/EdDSA). When empty, tokens
+	// are signed with JWTSecret (HS256), which is for development only.
+	JWTKeysDir            string        `env:"JWT_KEYS_DIR"`
+	JWTKeyActivationDelay time.Duration `env:"JWT_KEY_ACTIVATION_DELAY"`
+	JWTKeysReloadInterval time.Duration `env:"JWT_KEYS_RELOAD_INTERVAL"`
+	JWTAccessTokenTTL     time.Duration `env:"JWT_ACCESS_TOKEN_TTL"`
+	JWTRefreshTokenTTL    time.Duration `env:"JWT_REFRESH_TOKEN_TTL"`
+	CORSAllowedOrigins    []string      `env:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods    []string      `env:"CORS_ALLOWED_METHODS"`
+	CORSAllowedHeaders    []string      `env:"CORS_ALLOWED_HEADERS"`
+	CORSAllowCredentials  bool          `env:"CORS_ALLOW_CREDENTIALS"`
+
+	// AppBaseURL is the public frontend URL used to build links in emails.
+	AppBaseURL string `env:"APP_BASE_URL"`
+	// APIBaseURL is the public URL of this API, used for OIDC callback URLs.
+	APIBaseURL string `env:"API_BASE_URL"`
+
+	MailDriver    string `env:"MAIL_DRIVER"` // "smtp" or "outbox"
+	MailFrom      string `env:"MAIL_FROM"`
+	MailOutboxDir string `env:"MAIL_OUTBOX_DIR"`
+	SMTPHost      string `env:"SMTP_HOST"`
+	SMTPPort      int    `env:"SMTP_PORT"`
+	SMTPUsername  string `env:"SMTP_USERNAME"`
+	SMTPPassword  string `env:"SMTP_PASSWORD" secret:"true"`
+
+	// MFAIssuer is the account issuer shown in authenticator apps.
+	MFAIssuer string `env:"MFA_ISSUER"`
+	// MFARequiredRoles lists roles that must enroll in two-factor auth.
+	MFARequiredRoles []string `env:"MFA_REQUIRED_ROLES"`
+
+	// LoginThrottleStore selects where failed logins are counted: "memory"
+	// (per process) or "mysql" (shared across instances).
+	LoginThrottleStore    string        `env:"LOGIN_THROTTLE_STORE"`
+	LoginAttemptWindow    time.Duration `env:"LOGIN_ATTEMPT_WINDOW"`
+	LoginMaxAttempts      int           `env:"LOGIN_MAX_ATTEMPTS"` // per account within the window
+	LoginMaxAttemptsPerIP int           `env:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginLockoutDuration  time.Duration `env:"LOGIN_LOCKOUT_DURATION"`
+
+	// RateLimitStore selects where API rate limit buckets are kept: "memory"
+	// (per process), "mysql" (shared across instances) or "none" (disabled).
+	RateLimitStore string `env:"RATE_LIMIT_STORE"`
+	// TrustedProxies are the addresses or CIDRs of reverse proxies whose
+	// X-Forwarded-For header is believed when determining the client IP.
+	// Empty trusts no proxy: the client IP is the peer address.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+
+	// OpenAPIValidate checks requests against the OpenAPI document before
+	// they reach the handlers; in development responses are checked too and
+	// mismatches logged.
+	OpenAPIValidate bool `env:"OPENAPI_VALIDATE"`
+
+	// HTTPCacheEntries is the capacity of the in-process cache of public
+	// catalog responses; 0 disables it (validators are still sent).
+	HTTPCacheEntries int `env:"HTTP_CACHE_ENTRIES"`
+
+	// IdempotencyStore selects where Idempotency-Key records are kept:
+	// "memory" (per process), "mysql" (shared across instances) or "none"
+	// (keys are ignored). Stored responses expire after IdempotencyTTL.
+	IdempotencyStore string        `env:"IDEMPOTENCY_STORE"`
+	IdempotencyTTL   time.Duration `env:"IDEMPOTENCY_TTL"`
+
+	// OIDCProviders are the OpenID Connect identity providers users can sign
+	// in with. OIDCDefaultRole is the role of accounts created on first login.
+	OIDCProviders   []OIDCProviderConfig `env:"OIDC_PROVIDERS"`
+	OIDCDefaultRole string               `env:"OIDC_DEFAULT_ROLE"`
+
+	// AccountDeletionGracePeriod is how long a self-service account deletion
+	// can be cancelled (by signing in) before the account is anonymized.
+	AccountDeletionGracePeriod time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD"`
+
+	// ImpersonationTokenTTL is the lifetime of tokens admins obtain to act
+	// as another user.
+	ImpersonationTokenTTL time.Duration `env:"IMPERSONATION_TOKEN_TTL"`
+}
+
+// OIDCProviderConfig describes one OpenID Connect identity provider.
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"`
+	DisplayName  string   `mapstructure:"display_name"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	Scopes       []string `mapstructure:"scopes"`
+	// DefaultRole overrides OIDCDefaultRole for this provider.
+	DefaultRole string `mapstructure:"default_role"`
 }
 
-// Load reads configuration from environment variables and optional config file.
-// Priority: ENV > config.[yaml|json|...] > defaults.
+// Development defaults for the database password and token secret, refused
+// in production.
+const (
+	devMySQLPassword = "password"
+	devJWTSecret     = "change-me-in-production"
+)
+
+// Load reads configuration from environment variables and optional config file
+// and validates it.
+// Priority: ENV (or <VAR>_FILE for secrets) > config.[yaml|json|...] > defaults.
 func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read reads configuration like Load without validating it. It only fails
+// when the config file or a value cannot be parsed; errors on values are
+// *ValidationError.
+func Read() (*Config, error) {
 	v := viper.New()
 
 	// Defaults
 	v.SetDefault("APP_ENV", "development")
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("LOG_FORMAT", "json")
 	v.SetDefault("HTTP_HOST", "0.0.0.0")
 	v.SetDefault("HTTP_PORT", 8081)
 
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_OTLP_ENDPOINT", "http://localhost:4318")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("TRACING_SERVICE_NAME", "global-trade-hub-api")
+
 	v.SetDefault("MYSQL_HOST", "127.0.0.1")
 	v.SetDefault("MYSQL_PORT", 3306)
 	v.SetDefault("MYSQL_USER", "root")
-	v.SetDefault("MYSQL_PASSWORD", "password")
+	v.SetDefault("MYSQL_PASSWORD", devMySQLPassword)
 	v.SetDefault("MYSQL_DB", "global_trade_hub")
 	v.SetDefault("MYSQL_PARAMS", "parseTime=true&loc=Local&charset=utf8mb4,utf8")
 
-	v.SetDefault("JWT_SECRET", "change-me-in-production")
+	v.SetDefault("JWT_SECRET", devJWTSecret)
 	v.SetDefault("JWT_ISSUER", "global-trade-hub")
+	v.SetDefault("JWT_AUDIENCE", "global-trade-hub")
+	v.SetDefault("JWT_KEYS_DIR", "")
+	v.SetDefault("JWT_KEY_ACTIVATION_DELAY", "1h")
+	v.SetDefault("JWT_KEYS_RELOAD_INTERVAL", "1m")
 	v.SetDefault("JWT_ACCESS_TOKEN_TTL", "15m")
 	v.SetDefault("JWT_REFRESH_TOKEN_TTL", "720h") // 30 days
 
 	v.SetDefault("CORS_ALLOWED_ORIGINS", []string{"http://localhost:8080", "http://localhost:5173"})
 	v.SetDefault("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
-	v.SetDefault("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "X-Requested-With"})
+	v.SetDefault("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "X-Requested-With", "X-API-Key", "Idempotency-Key", "traceparent", "tracestate"})
 	v.SetDefault("CORS_ALLOW_CREDENTIALS", true)
 
+	v.SetDefault("APP_BASE_URL", "http://localhost:5173")
+	v.SetDefault("API_BASE_URL", "http://localhost:8081")
+
+	v.SetDefault("MAIL_DRIVER", "outbox")
+	v.SetDefault("MAIL_FROM", "Global Trade Hub <no-reply@localhost>")
+	v.SetDefault("MAIL_OUTBOX_DIR", "tmp/outbox")
+	v.SetDefault("SMTP_HOST", "127.0.0.1")
+	v.SetDefault("SMTP_PORT", 587)
+
+	v.SetDefault("MFA_ISSUER", "Global Trade Hub")
+	v.SetDefault("MFA_REQUIRED_ROLES", []string{"admin", "moderator", "kyc_reviewer", "finance"})
+
+	v.SetDefault("LOGIN_THROTTLE_STORE", "memory")
+	v.SetDefault("LOGIN_ATTEMPT_WINDOW", "15m")
+	v.SetDefault("LOGIN_MAX_ATTEMPTS", 10)
+	v.SetDefault("LOGIN_MAX_ATTEMPTS_PER_IP", 100)
+	v.SetDefault("LOGIN_LOCKOUT_DURATION", "15m")
+
+	v.SetDefault("RATE_LIMIT_STORE", "memory")
+	v.SetDefault("OPENAPI_VALIDATE", false)
+	v.SetDefault("HTTP_CACHE_ENTRIES", 1000)
+	v.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	v.SetDefault("SHUTDOWN_DRAIN_DELAY", "5s")
+	v.SetDefault("IDEMPOTENCY_STORE", "memory")
+	v.SetDefault("IDEMPOTENCY_TTL", "24h")
+
+	v.SetDefault("OIDC_DEFAULT_ROLE", "buyer")
+
+	v.SetDefault("ACCOUNT_DELETION_GRACE_PERIOD", "720h")
+	v.SetDefault("IMPERSONATION_TOKEN_TTL", "15m")
+
 	// Set config file (backend/config.{yaml,json,toml,...})
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
 	v.AddConfigPath(".")
 	v.AddConfigPath("./backend")
 
-	// Read config file first (before environment variables)
+	// Read config file first (before environment variables); it is optional,
+	// but one that exists has to parse.
 	if err := v.ReadInConfig(); err != nil {
-		// Log warning but continue with defaults/env
-		fmt.Printf("Warning: Could not read config file: %v\n", err)
-	} else {
-		fmt.Printf("Using config file: %s\n", v.ConfigFileUsed())
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
 	}
 
 	// Set up environment variable bindings with nested key support
@@ -90,42 +270,112 @@ func Load() (*Config, error) {
 	// Environment variables (will override config file)
 	v.AutomaticEnv()
 
-	accessTTL, err := time.ParseDuration(v.GetString("JWT_ACCESS_TOKEN_TTL"))
+	var errs ValidationError
+	accessTTL := getDuration(v, "JWT_ACCESS_TOKEN_TTL", &errs)
+	refreshTTL := getDuration(v, "JWT_REFRESH_TOKEN_TTL", &errs)
+	keyActivationDelay := getDuration(v, "JWT_KEY_ACTIVATION_DELAY", &errs)
+	keysReloadInterval := getDuration(v, "JWT_KEYS_RELOAD_INTERVAL", &errs)
+	attemptWindow := getDuration(v, "LOGIN_ATTEMPT_WINDOW", &errs)
+	lockoutDuration := getDuration(v, "LOGIN_LOCKOUT_DURATION", &errs)
+	deletionGrace := getDuration(v, "ACCOUNT_DELETION_GRACE_PERIOD", &errs)
+	impersonationTTL := getDuration(v, "IMPERSONATION_TOKEN_TTL", &errs)
+	idempotencyTTL := getDuration(v, "IDEMPOTENCY_TTL", &errs)
+	healthCheckTimeout := getDuration(v, "HEALTH_CHECK_TIMEOUT", &errs)
+	drainDelay := getDuration(v, "SHUTDOWN_DRAIN_DELAY", &errs)
+
+	mysqlPassword := getSecret(v, "db.pass", "MYSQL_PASSWORD", &errs)
+	jwtSecret := getSecret(v, "jwt.secret", "JWT_SECRET", &errs)
+	smtpPassword := getSecret(v, "mail.smtp.password", "SMTP_PASSWORD", &errs)
+	metricsToken := getSecret(v, "metrics.token", "METRICS_TOKEN", &errs)
+	healthToken := getSecret(v, "health.token", "HEALTH_TOKEN", &errs)
+
+	oidcProviders, err := loadOIDCProviders(v, &errs)
 	if err != nil {
-		accessTTL = 15 * time.Minute
+		return nil, err
 	}
-	refreshTTL, err := time.ParseDuration(v.GetString("JWT_REFRESH_TOKEN_TTL"))
-	if err != nil {
-		refreshTTL = 30 * 24 * time.Hour
+	if len(errs.Errors) > 0 {
+		return nil, &errs
 	}
 
 	cfg := &Config{
 		// Support both nested YAML (app.env) and flat env vars (APP_ENV)
-		AppEnv: getString(v, "app.env", "APP_ENV"),
+		AppEnv:     getString(v, "app.env", "APP_ENV"),
+		ConfigFile: v.ConfigFileUsed(),
+
+		LogLevel:  getString(v, "log.level", "LOG_LEVEL"),
+		LogFormat: getString(v, "log.format", "LOG_FORMAT"),
 
 		HTTPHost: getString(v, "http.host", "HTTP_HOST"),
 		HTTPPort: getInt(v, "http.port", "HTTP_PORT"),
 
+		MetricsAddr:  getString(v, "metrics.addr", "METRICS_ADDR"),
+		MetricsToken: metricsToken,
+
+		HealthToken:        healthToken,
+		HealthCheckTimeout: healthCheckTimeout,
+		ShutdownDrainDelay: drainDelay,
+
+		TracingExporter:     getString(v, "tracing.exporter", "TRACING_EXPORTER"),
+		TracingOTLPEndpoint: getString(v, "tracing.otlp_endpoint", "TRACING_OTLP_ENDPOINT"),
+		TracingSampleRatio:  v.GetFloat64("TRACING_SAMPLE_RATIO"),
+		TracingServiceName:  getString(v, "tracing.service_name", "TRACING_SERVICE_NAME"),
+
 		MySQLHost:     getString(v, "db.host", "MYSQL_HOST"),
 		MySQLPort:     getInt(v, "db.port", "MYSQL_PORT"),
 		MySQLUser:     getString(v, "db.user", "MYSQL_USER"),
-		MySQLPassword: getString(v, "db.pass", "MYSQL_PASSWORD"),
+		MySQLPassword: mysqlPassword,
 		MySQLDB:       getString(v, "db.name", "MYSQL_DB"),
 		MySQLParams:   getString(v, "db.params", "MYSQL_PARAMS"),
 
-		JWTSecret:          getString(v, "jwt.secret", "JWT_SECRET"),
-		JWTIssuer:          getString(v, "jwt.issuer", "JWT_ISSUER"),
-		JWTAccessTokenTTL:  accessTTL,
-		JWTRefreshTokenTTL: refreshTTL,
+		JWTSecret:             jwtSecret,
+		JWTIssuer:             getString(v, "jwt.issuer", "JWT_ISSUER"),
+		JWTAudience:           getString(v, "jwt.audience", "JWT_AUDIENCE"),
+		JWTKeysDir:            getString(v, "jwt.keys_dir", "JWT_KEYS_DIR"),
+		JWTKeyActivationDelay: keyActivationDelay,
+		JWTKeysReloadInterval: keysReloadInterval,
+		JWTAccessTokenTTL:     accessTTL,
+		JWTRefreshTokenTTL:    refreshTTL,
 
 		CORSAllowedOrigins:   getStringSlice(v, "cors.allowed_origins", "CORS_ALLOWED_ORIGINS"),
 		CORSAllowedMethods:   getStringSlice(v, "cors.allowed_methods", "CORS_ALLOWED_METHODS"),
 		CORSAllowedHeaders:   getStringSlice(v, "cors.allowed_headers", "CORS_ALLOWED_HEADERS"),
 		CORSAllowCredentials: getBool(v, "cors.allow_credentials", "CORS_ALLOW_CREDENTIALS"),
-	}
 
-	if cfg.JWTSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET is required")
+		AppBaseURL: getString(v, "app.base_url", "APP_BASE_URL"),
+		APIBaseURL: getString(v, "app.api_base_url", "API_BASE_URL"),
+
+		MailDriver:    getString(v, "mail.driver", "MAIL_DRIVER"),
+		MailFrom:      getString(v, "mail.from", "MAIL_FROM"),
+		MailOutboxDir: getString(v, "mail.outbox_dir", "MAIL_OUTBOX_DIR"),
+		SMTPHost:      getString(v, "mail.smtp.host", "SMTP_HOST"),
+		SMTPPort:      getInt(v, "mail.smtp.port", "SMTP_PORT"),
+		SMTPUsername:  getString(v, "mail.smtp.username", "SMTP_USERNAME"),
+		SMTPPassword:  smtpPassword,
+
+		MFAIssuer:        getString(v, "mfa.issuer", "MFA_ISSUER"),
+		MFARequiredRoles: getStringSlice(v, "mfa.required_roles", "MFA_REQUIRED_ROLES"),
+
+		LoginThrottleStore:    getString(v, "login.throttle_store", "LOGIN_THROTTLE_STORE"),
+		LoginAttemptWindow:    attemptWindow,
+		LoginMaxAttempts:      getInt(v, "login.max_attempts", "LOGIN_MAX_ATTEMPTS"),
+		LoginMaxAttemptsPerIP: getInt(v, "login.max_attempts_per_ip", "LOGIN_MAX_ATTEMPTS_PER_IP"),
+		LoginLockoutDuration:  lockoutDuration,
+
+		RateLimitStore: getString(v, "rate_limit.store", "RATE_LIMIT_STORE"),
+		TrustedProxies: getStringSlice(v, "http.trusted_proxies", "TRUSTED_PROXIES"),
+
+		OpenAPIValidate: getBool(v, "openapi.validate", "OPENAPI_VALIDATE"),
+
+		HTTPCacheEntries: getInt(v, "http.cache_entries", "HTTP_CACHE_ENTRIES"),
+
+		IdempotencyStore: getString(v, "idempotency.store", "IDEMPOTENCY_STORE"),
+		IdempotencyTTL:   idempotencyTTL,
+
+		OIDCProviders:   oidcProviders,
+		OIDCDefaultRole: getString(v, "oidc.default_role", "OIDC_DEFAULT_ROLE"),
+
+		AccountDeletionGracePeriod: deletionGrace,
+		ImpersonationTokenTTL:      impersonationTTL,
 	}
 
 	return cfg, nil
@@ -136,7 +386,53 @@ func (c *Config) HTTPAddress() string {
 	return fmt.Sprintf("%s:%d", c.HTTPHost, c.HTTPPort)
 }
 
+// loadOIDCProviders reads identity providers from the oidc.providers list in
+// the config file or, when that is absent, from OIDC_PROVIDERS=name1,name2
+// plus OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET (or _CLIENT_SECRET_FILE),
+// _SCOPES, _DISPLAY_NAME and _DEFAULT_ROLE environment variables.
+func loadOIDCProviders(v *viper.Viper, errs *ValidationError) ([]OIDCProviderConfig, error) {
+	if v.IsSet("oidc.providers") {
+		var providers []OIDCProviderConfig
+		if err := v.UnmarshalKey("oidc.providers", &providers); err != nil {
+			return nil, fmt.Errorf("invalid oidc.providers: %w", err)
+		}
+		for i, p := range providers {
+			if secret, ok := secretFile(oidcEnvPrefix(p.Name)+"CLIENT_SECRET", errs); ok {
+				providers[i].ClientSecret = secret
+			}
+		}
+		return providers, nil
+	}
+
+	var providers []OIDCProviderConfig
+	for _, name := range splitList(v.GetString("OIDC_PROVIDERS")) {
+		prefix := oidcEnvPrefix(name)
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  v.GetString(prefix + "DISPLAY_NAME"),
+			Issuer:       v.GetString(prefix + "ISSUER"),
+			ClientID:     v.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: getSecret(v, prefix+"CLIENT_SECRET", prefix+"CLIENT_SECRET", errs),
+			Scopes:       splitList(v.GetString(prefix + "SCOPES")),
+			DefaultRole:  v.GetString(prefix + "DEFAULT_ROLE"),
+		})
+	}
+	return providers, nil
+}
+
+// oidcEnvPrefix returns the prefix of the environment variables of the
+// provider called name, e.g. OIDC_MY_IDP_ for my-idp.
+func oidcEnvPrefix(name string) string {
+	return "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+// splitList splits a comma or space separated env value.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
 // Helper functions to support both nested YAML keys and flat env vars
+
 func getString(v *viper.Viper, nestedKey, envKey string) string {
 	// Try nested key first (from YAML)
 	if v.IsSet(nestedKey) {
@@ -165,10 +461,54 @@ func getBool(v *viper.Viper, nestedKey, envKey string) bool {
 }
 
 func getStringSlice(v *viper.Viper, nestedKey, envKey string) []string {
+	key := envKey
 	// Try nested key first (from YAML)
 	if v.IsSet(nestedKey) {
-		return v.GetStringSlice(nestedKey)
+		key = nestedKey
 	}
-	// Fall back to flat env var
-	return v.GetStringSlice(envKey)
+	// Env vars (and scalar YAML values) are comma or space separated lists
+	if s, ok := v.Get(key).(string); ok {
+		return splitList(s)
+	}
+	return v.GetStringSlice(key)
+}
+
+// getDuration parses the duration in envKey, recording a malformed one in
+// errs.
+func getDuration(v *viper.Viper, envKey string, errs *ValidationError) time.Duration {
+	d, err := time.ParseDuration(v.GetString(envKey))
+	if err != nil {
+		errs.add(envKey, ErrInvalid, "%q is not a duration such as 15m or 24h", v.GetString(envKey))
+	}
+	return d
+}
+
+// getSecret is getString for secrets, which can also be read from the file
+// named by <envKey>_FILE.
+func getSecret(v *viper.Viper, nestedKey, envKey string, errs *ValidationError) string {
+	if secret, ok := secretFile(envKey, errs); ok {
+		return secret
+	}
+	return getString(v, nestedKey, envKey)
+}
+
+// secretFile reads a secret from the file named by <envKey>_FILE (as mounted
+// by Docker and Kubernetes secrets), without its trailing newline. ok is
+// false when <envKey>_FILE is not set. Setting both envKey and <envKey>_FILE
+// is an error.
+func secretFile(envKey string, errs *ValidationError) (secret string, ok bool) {
+	path := os.Getenv(envKey + "_FILE")
+	if path == "" {
+		return "", false
+	}
+	if _, set := os.LookupEnv(envKey); set {
+		errs.add(envKey+"_FILE", ErrInvalid, "%s is set as well; set only one of them", envKey)
+		return "", true
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		errs.add(envKey+"_FILE", ErrInvalid, "%v", err)
+		return "", true
+	}
+	return strings.TrimRight(string(b), "\r\n"), true
 }
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redacted replaces set secrets in the output of WriteEnv.
+const redacted = "[REDACTED]"
+
+// WriteEnv writes the configuration to w as KEY=value lines in the format of
+// .env files, in field order. With redact, secrets that are set are replaced
+// by [REDACTED], so the output can be shared.
+func (c *Config) WriteEnv(w io.Writer, redact bool) error {
+	var b strings.Builder
+	if c.ConfigFile != "" {
+		fmt.Fprintf(&b, "# config file: %s\n", c.ConfigFile)
+	}
+
+	rv := reflect.ValueOf(c).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" || key == "-" {
+			continue
+		}
+		if providers, ok := rv.Field(i).Interface().([]OIDCProviderConfig); ok {
+			writeOIDCProviders(&b, providers, redact)
+			continue
+		}
+		value := formatValue(rv.Field(i))
+		if redact && field.Tag.Get("secret") == "true" && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeOIDCProviders writes providers in the OIDC_PROVIDERS form read by
+// loadOIDCProviders.
+func writeOIDCProviders(b *strings.Builder, providers []OIDCProviderConfig, redact bool) {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name
+	}
+	fmt.Fprintf(b, "OIDC_PROVIDERS=%s\n", strings.Join(names, ","))
+	for _, p := range providers {
+		prefix := oidcEnvPrefix(p.Name)
+		secret := p.ClientSecret
+		if redact && secret != "" {
+			secret = redacted
+		}
+		fmt.Fprintf(b, "%sDISPLAY_NAME=%s\n", prefix, p.DisplayName)
+		fmt.Fprintf(b, "%sISSUER=%s\n", prefix, p.Issuer)
+		fmt.Fprintf(b, "%sCLIENT_ID=%s\n", prefix, p.ClientID)
+		fmt.Fprintf(b, "%sCLIENT_SECRET=%s\n", prefix, secret)
+		fmt.Fprintf(b, "%sSCOPES=%s\n", prefix, strings.Join(p.Scopes, ","))
+		fmt.Fprintf(b, "%sDEFAULT_ROLE=%s\n", prefix, p.DefaultRole)
+	}
+}
+
+func formatValue(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case []string:
+		return strings.Join(x, ",")
+	default:
+		return fmt.Sprint(x)
+	}
+}
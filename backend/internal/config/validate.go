@@ -0,0 +1,277 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kinds of configuration errors, matched with errors.Is.
+var (
+	ErrRequired = errors.New("is required")
+	ErrInvalid  = errors.New("is invalid")
+	// ErrInsecure is a setting refused with APP_ENV=production, such as a
+	// development default.
+	ErrInsecure = errors.New("is insecure in production")
+)
+
+// FieldError is a problem with one setting.
+type FieldError struct {
+	// Key is the environment variable of the setting, e.g. "JWT_SECRET".
+	Key    string
+	Err    error // ErrRequired, ErrInvalid or ErrInsecure
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	msg := e.Key + " " + e.Err.Error()
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ValidationError lists every problem found in the configuration.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap lets errors.Is and errors.As look at each FieldError.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+func (e *ValidationError) add(key string, err error, format string, args ...any) {
+	e.Errors = append(e.Errors, &FieldError{Key: key, Err: err, Reason: fmt.Sprintf(format, args...)})
+}
+
+// minSecretLength is the shortest JWT_SECRET accepted in production.
+const minSecretLength = 32
+
+// Validate checks every setting and, with AppEnv production, refuses
+// development defaults, placeholder secrets, localhost URLs and the outbox
+// mailer. It returns a *ValidationError listing all problems.
+func (c *Config) Validate() error {
+	var errs ValidationError
+
+	oneOf(&errs, "APP_ENV", c.AppEnv, "development", "test", "staging", "production")
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs.add("LOG_LEVEL", ErrInvalid, "%q: use debug, info, warn or error", c.LogLevel)
+	}
+	oneOf(&errs, "LOG_FORMAT", strings.ToLower(c.LogFormat), "json", "text")
+
+	port(&errs, "HTTP_PORT", c.HTTPPort)
+	if c.HealthCheckTimeout <= 0 {
+		errs.add("HEALTH_CHECK_TIMEOUT", ErrInvalid, "must be positive")
+	}
+	if c.ShutdownDrainDelay < 0 {
+		errs.add("SHUTDOWN_DRAIN_DELAY", ErrInvalid, "must not be negative")
+	}
+
+	oneOf(&errs, "TRACING_EXPORTER", strings.ToLower(c.TracingExporter), "otlp", "stdout", "none")
+	if strings.EqualFold(c.TracingExporter, "otlp") {
+		absoluteURL(&errs, "TRACING_OTLP_ENDPOINT", c.TracingOTLPEndpoint)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs.add("TRACING_SAMPLE_RATIO", ErrInvalid, "%v is not between 0 and 1", c.TracingSampleRatio)
+	}
+
+	required(&errs, "MYSQL_HOST", c.MySQLHost)
+	port(&errs, "MYSQL_PORT", c.MySQLPort)
+	required(&errs, "MYSQL_USER", c.MySQLUser)
+	required(&errs, "MYSQL_DB", c.MySQLDB)
+
+	if c.JWTKeysDir == "" {
+		required(&errs, "JWT_SECRET", c.JWTSecret)
+	}
+	required(&errs, "JWT_ISSUER", c.JWTIssuer)
+	required(&errs, "JWT_AUDIENCE", c.JWTAudience)
+	if c.JWTKeyActivationDelay < 0 {
+		errs.add("JWT_KEY_ACTIVATION_DELAY", ErrInvalid, "must not be negative")
+	}
+	if c.JWTKeysReloadInterval < 0 {
+		errs.add("JWT_KEYS_RELOAD_INTERVAL", ErrInvalid, "must not be negative (0 disables reloading)")
+	}
+	positive(&errs, "JWT_ACCESS_TOKEN_TTL", c.JWTAccessTokenTTL)
+	if c.JWTRefreshTokenTTL <= c.JWTAccessTokenTTL {
+		errs.add("JWT_REFRESH_TOKEN_TTL", ErrInvalid, "must be longer than JWT_ACCESS_TOKEN_TTL")
+	}
+
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		errs.add("CORS_ALLOWED_ORIGINS", ErrInvalid, `"*" cannot be combined with CORS_ALLOW_CREDENTIALS`)
+	}
+
+	absoluteURL(&errs, "APP_BASE_URL", c.AppBaseURL)
+	absoluteURL(&errs, "API_BASE_URL", c.APIBaseURL)
+
+	oneOf(&errs, "MAIL_DRIVER", c.MailDriver, "smtp", "outbox")
+	if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+		errs.add("MAIL_FROM", ErrInvalid, "%v", err)
+	}
+	switch c.MailDriver {
+	case "smtp":
+		required(&errs, "SMTP_HOST", c.SMTPHost)
+		port(&errs, "SMTP_PORT", c.SMTPPort)
+	case "outbox":
+		required(&errs, "MAIL_OUTBOX_DIR", c.MailOutboxDir)
+	}
+
+	oneOf(&errs, "LOGIN_THROTTLE_STORE", c.LoginThrottleStore, "memory", "mysql")
+	positive(&errs, "LOGIN_ATTEMPT_WINDOW", c.LoginAttemptWindow)
+	if c.LoginMaxAttempts <= 0 {
+		errs.add("LOGIN_MAX_ATTEMPTS", ErrInvalid, "must be positive")
+	}
+	if c.LoginMaxAttemptsPerIP <= 0 {
+		errs.add("LOGIN_MAX_ATTEMPTS_PER_IP", ErrInvalid, "must be positive")
+	}
+	positive(&errs, "LOGIN_LOCKOUT_DURATION", c.LoginLockoutDuration)
+
+	oneOf(&errs, "RATE_LIMIT_STORE", c.RateLimitStore, "memory", "mysql", "none")
+	for _, p := range c.TrustedProxies {
+		if net.ParseIP(p) == nil {
+			if _, _, err := net.ParseCIDR(p); err != nil {
+				errs.add("TRUSTED_PROXIES", ErrInvalid, "%q is not an IP address or CIDR", p)
+			}
+		}
+	}
+
+	if c.HTTPCacheEntries < 0 {
+		errs.add("HTTP_CACHE_ENTRIES", ErrInvalid, "must not be negative (0 disables the cache)")
+	}
+	oneOf(&errs, "IDEMPOTENCY_STORE", c.IdempotencyStore, "memory", "mysql", "none")
+	positive(&errs, "IDEMPOTENCY_TTL", c.IdempotencyTTL)
+
+	positive(&errs, "ACCOUNT_DELETION_GRACE_PERIOD", c.AccountDeletionGracePeriod)
+	positive(&errs, "IMPERSONATION_TOKEN_TTL", c.ImpersonationTokenTTL)
+
+	if c.AppEnv == "production" {
+		c.validateProduction(&errs)
+	}
+
+	if len(errs.Errors) > 0 {
+		return &errs
+	}
+	return nil
+}
+
+// validateProduction adds the guardrails of AppEnv production.
+func (c *Config) validateProduction(errs *ValidationError) {
+	if c.JWTKeysDir == "" {
+		switch {
+		case c.JWTSecret == devJWTSecret:
+			errs.add("JWT_SECRET", ErrInsecure, "the development default is used; set JWT_KEYS_DIR or a random secret")
+		case len(c.JWTSecret) < minSecretLength:
+			errs.add("JWT_SECRET", ErrInsecure, "shorter than %d characters", minSecretLength)
+		}
+		placeholder(errs, "JWT_SECRET", c.JWTSecret)
+	}
+
+	switch c.MySQLPassword {
+	case "":
+		errs.add("MYSQL_PASSWORD", ErrInsecure, "empty")
+	case devMySQLPassword:
+		errs.add("MYSQL_PASSWORD", ErrInsecure, "the development default is used")
+	}
+	placeholder(errs, "MYSQL_PASSWORD", c.MySQLPassword)
+	placeholder(errs, "SMTP_PASSWORD", c.SMTPPassword)
+	for _, p := range c.OIDCProviders {
+		placeholder(errs, oidcEnvPrefix(p.Name)+"CLIENT_SECRET", p.ClientSecret)
+	}
+
+	if c.MailDriver == "outbox" {
+		errs.add("MAIL_DRIVER", ErrInsecure, "the outbox driver only writes emails to disk; use smtp")
+	}
+
+	if len(c.TrustedProxies) == 0 {
+		errs.add("TRUSTED_PROXIES", ErrInsecure, "empty; list the reverse proxy addresses, or 127.0.0.1 when clients connect directly")
+	}
+
+	https(errs, "APP_BASE_URL", c.AppBaseURL)
+	https(errs, "API_BASE_URL", c.APIBaseURL)
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" || isLocalhost(origin) {
+			errs.add("CORS_ALLOWED_ORIGINS", ErrInsecure, "%q is allowed", origin)
+		}
+	}
+}
+
+func required(errs *ValidationError, key, value string) {
+	if value == "" {
+		errs.add(key, ErrRequired, "")
+	}
+}
+
+func oneOf(errs *ValidationError, key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		errs.add(key, ErrInvalid, "%q: use %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func port(errs *ValidationError, key string, p int) {
+	if p < 1 || p > 65535 {
+		errs.add(key, ErrInvalid, "%d is not a port number", p)
+	}
+}
+
+func positive(errs *ValidationError, key string, d time.Duration) {
+	if d <= 0 {
+		errs.add(key, ErrInvalid, "must be positive")
+	}
+}
+
+func absoluteURL(errs *ValidationError, key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add(key, ErrInvalid, "%q is not an http(s) URL", value)
+	}
+}
+
+// https refuses URLs that are not https or point at localhost.
+func https(errs *ValidationError, key, value string) {
+	if isLocalhost(value) {
+		errs.add(key, ErrInsecure, "%q points at localhost", value)
+	} else if !strings.HasPrefix(value, "https://") {
+		errs.add(key, ErrInsecure, "%q is not https", value)
+	}
+}
+
+func isLocalhost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// placeholder refuses the YOUR_... values of config.yaml.example.
+func placeholder(errs *ValidationError, key, value string) {
+	if strings.HasPrefix(value, "YOUR_") {
+		errs.add(key, ErrInsecure, "the example placeholder is used")
+	}
+}
@@ -1,6 +1,9 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"gorm.io/driver/mysql"
@@ -10,9 +13,16 @@ import (
 	"github.com/example/global-trade-hub/backend/internal/domain/cms"
 )
 
+// SchemaVersion is the number of the latest migration in backend/migrations,
+// the schema this binary expects. The SQL migrations record their own number
+// in schema_version (see 025_schema_version); bump it with every migration.
+const SchemaVersion = 26
+
 // AutoMigrate opens a temporary GORM connection and runs automatic migrations
 // using migration models that match backend/migrations/001_init_schema.up.sql
-// (and CMS migrations 004–008). Tables are created/updated on startup.
+// (plus CMS migrations 004–008 and auth migrations 010+). Tables are
+// created/updated on startup, after which the schema is recorded as being at
+// SchemaVersion.
 func AutoMigrate(cfg *config.Config) error {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%d)/%s?%s",
@@ -46,6 +56,26 @@ func AutoMigrate(cfg *config.Config) error {
 		&MigrationReview{},
 		&MigrationFavorite{},
 		&MigrationSearchHistory{},
+		// Auth (010+)
+		&MigrationRefreshToken{},
+		&MigrationUserActionToken{},
+		&MigrationUserMFA{},
+		&MigrationUserRecoveryCode{},
+		&MigrationLoginAttempt{},
+		&MigrationLoginLockout{},
+		&MigrationAPIKey{},
+		&MigrationUserIdentity{},
+		&MigrationOIDCUsedState{},
+		&MigrationAuthSession{},
+		&MigrationStaffInvitation{},
+		&MigrationImpersonationSession{},
+		&MigrationImpersonationRequest{},
+		&MigrationRateLimitBucket{},
+		&MigrationIdempotencyKey{},
+		&MigrationSchemaVersion{},
+		// Supplier organizations (019)
+		&MigrationSupplierMember{},
+		&MigrationSupplierInvitation{},
 		// CMS (004–008)
 		&cms.ContactMessage{},
 		&cms.BlogPost{},
@@ -56,5 +86,39 @@ func AutoMigrate(cfg *config.Config) error {
 		return err
 	}
 
+	db, err := gdb.DB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return recordSchemaVersion(context.Background(), db)
+}
+
+// recordSchemaVersion sets the version in schema_version to SchemaVersion,
+// as the SQL migrations do, keeping a newer one written by a later binary.
+func recordSchemaVersion(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO schema_version (id, version) VALUES (1, ?)
+ON DUPLICATE KEY UPDATE version = GREATEST(version, VALUES(version))`, SchemaVersion)
+	if err != nil {
+		return fmt.Errorf("record schema version: %w", err)
+	}
+	return nil
+}
+
+// CheckSchema reports whether the SQL migrations applied to the database
+// reach SchemaVersion, for the readiness probe. The version is written by
+// the migration files and by AutoMigrate, so it lags while neither has run.
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT version FROM schema_version WHERE id = 1`).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("schema version not recorded: apply migrations up to %03d", SchemaVersion)
+	}
+	if err != nil {
+		return err
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("schema version %d, want %d", version, SchemaVersion)
+	}
 	return nil
 }
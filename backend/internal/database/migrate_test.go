@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// versionTable is an in-memory schema_version table, served through a
+// database/sql driver that understands the two statements used on it.
+type versionTable struct {
+	mu      sync.Mutex
+	version int64
+	ok      bool
+}
+
+func (t *versionTable) Connect(context.Context) (driver.Conn, error) { return versionConn{t}, nil }
+func (t *versionTable) Driver() driver.Driver                        { return nil }
+
+type versionConn struct{ t *versionTable }
+
+func (versionConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (versionConn) Close() error                        { return nil }
+func (versionConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c versionConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.HasPrefix(query, "INSERT INTO schema_version") || len(args) != 1 {
+		return nil, errors.New("unexpected statement: " + query)
+	}
+	v := args[0].Value.(int64)
+	c.t.mu.Lock()
+	defer c.t.mu.Unlock()
+	if !c.t.ok || v > c.t.version {
+		c.t.version = v
+	}
+	c.t.ok = true
+	return driver.RowsAffected(1), nil
+}
+
+func (c versionConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(query, "SELECT version FROM schema_version") {
+		return nil, errors.New("unexpected query: " + query)
+	}
+	c.t.mu.Lock()
+	defer c.t.mu.Unlock()
+	return &versionRows{version: c.t.version, done: !c.t.ok}, nil
+}
+
+type versionRows struct {
+	version int64
+	done    bool
+}
+
+func (*versionRows) Columns() []string { return []string{"version"} }
+func (*versionRows) Close() error      { return nil }
+
+func (r *versionRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.version
+	r.done = true
+	return nil
+}
+
+func TestRecordSchemaVersionMakesSchemaReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  *int64
+		want    int64
+		readyOK bool // CheckSchema before recording
+	}{
+		{name: "no row", want: SchemaVersion},
+		{name: "older version", before: ptr(int64(SchemaVersion - 1)), want: SchemaVersion},
+		{name: "same version", before: ptr(int64(SchemaVersion)), want: SchemaVersion, readyOK: true},
+		{name: "newer version is kept", before: ptr(int64(SchemaVersion + 1)), want: SchemaVersion + 1, readyOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &versionTable{}
+			if tt.before != nil {
+				table.version, table.ok = *tt.before, true
+			}
+			db := sql.OpenDB(table)
+			defer db.Close()
+			ctx := context.Background()
+
+			if err := CheckSchema(ctx, db); (err == nil) != tt.readyOK {
+				t.Fatalf("CheckSchema before recording: %v, want ready %v", err, tt.readyOK)
+			}
+			if err := recordSchemaVersion(ctx, db); err != nil {
+				t.Fatal(err)
+			}
+			if table.version != tt.want {
+				t.Errorf("version %d, want %d", table.version, tt.want)
+			}
+			if err := CheckSchema(ctx, db); err != nil {
+				t.Errorf("CheckSchema after recording: %v", err)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -6,21 +6,28 @@ package database
 
 import "time"
 
-// MigrationUser matches users table (001_init_schema).
+// MigrationUser matches users table (001_init_schema, 003, 011, 012, 018, 020).
 type MigrationUser struct {
-	ID           string    `gorm:"column:id;type:varchar(36);primaryKey"`
-	Email        string    `gorm:"column:email;type:varchar(255);not null;uniqueIndex"`
-	PasswordHash string    `gorm:"column:password_hash;type:varchar(255);not null"`
-	FullName     string    `gorm:"column:full_name;type:varchar(255);not null"`
-	Phone        string    `gorm:"column:phone;type:varchar(50)"`
-	Role         string    `gorm:"column:role;type:enum('buyer','supplier','market_visitor','admin');default:buyer"`
-	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
+	ID                  string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	Email               string     `gorm:"column:email;type:varchar(255);not null;uniqueIndex"`
+	PasswordHash        string     `gorm:"column:password_hash;type:varchar(255);not null"`
+	FullName            string     `gorm:"column:full_name;type:varchar(255);not null"`
+	Phone               string     `gorm:"column:phone;type:varchar(50)"`
+	Role                string     `gorm:"column:role;type:varchar(30);not null;default:buyer"`
+	Status              string     `gorm:"column:status;type:varchar(20);default:active;index:idx_users_status"`
+	MustChangePassword  bool       `gorm:"column:must_change_password;not null;default:false"`
+	EmailVerifiedAt     *time.Time `gorm:"column:email_verified_at;type:timestamp"`
+	Locale              string     `gorm:"column:locale;type:varchar(20)"`
+	AvatarURL           string     `gorm:"column:avatar_url;type:text"`
+	DeletionScheduledAt *time.Time `gorm:"column:deletion_scheduled_at;type:timestamp;index"`
+	DeletedAt           *time.Time `gorm:"column:deleted_at;type:timestamp"`
+	CreatedAt           time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
 }
 
 func (MigrationUser) TableName() string { return "users" }
 
-// MigrationSupplier matches suppliers table (001_init_schema).
+// MigrationSupplier matches suppliers table (001_init_schema, 023).
 type MigrationSupplier struct {
 	ID            string    `gorm:"column:id;type:varchar(36);primaryKey"`
 	UserID        string    `gorm:"column:user_id;type:varchar(36);not null;index"`
@@ -44,7 +51,7 @@ type MigrationSupplier struct {
 	ResponseTime  int       `gorm:"column:response_time;default:0"`
 	Established   *int      `gorm:"column:established;type:int"`
 	Employees     string    `gorm:"column:employees;type:varchar(50)"`
-	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_suppliers_created_at"`
 	UpdatedAt     time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
 }
 
@@ -89,7 +96,7 @@ type MigrationSubcategory struct {
 
 func (MigrationSubcategory) TableName() string { return "subcategories" }
 
-// MigrationProduct matches products table (001_init_schema).
+// MigrationProduct matches products table (001_init_schema, 023).
 type MigrationProduct struct {
 	ID             string    `gorm:"column:id;type:varchar(36);primaryKey"`
 	SupplierID     string    `gorm:"column:supplier_id;type:varchar(36);not null;index"`
@@ -109,18 +116,20 @@ type MigrationProduct struct {
 	ReviewCount    int       `gorm:"column:review_count;default:0"`
 	Featured       bool      `gorm:"column:featured;default:false"`
 	Status         string    `gorm:"column:status;type:varchar(20);default:draft"`
-	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	CreatedBy      string    `gorm:"column:created_by;type:varchar(36)"`
+	UpdatedBy      string    `gorm:"column:updated_by;type:varchar(36)"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_products_created_at"`
 	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
 }
 
 func (MigrationProduct) TableName() string { return "products" }
 
-// MigrationOrder matches orders table (001_init_schema).
+// MigrationOrder matches orders table (001_init_schema, 023).
 type MigrationOrder struct {
 	ID                string     `gorm:"column:id;type:varchar(36);primaryKey"`
 	OrderNumber       string     `gorm:"column:order_number;type:varchar(50);not null;uniqueIndex"`
-	BuyerID           string     `gorm:"column:buyer_id;type:varchar(36);not null;index"`
-	SupplierID        string     `gorm:"column:supplier_id;type:varchar(36);not null;index"`
+	BuyerID           string     `gorm:"column:buyer_id;type:varchar(36);not null;index;index:idx_orders_buyer_created,priority:1"`
+	SupplierID        string     `gorm:"column:supplier_id;type:varchar(36);not null;index;index:idx_orders_supplier_created,priority:1"`
 	ProductID         string     `gorm:"column:product_id;type:varchar(36);not null"`
 	Quantity          int        `gorm:"column:quantity;not null"`
 	UnitPrice         float64    `gorm:"column:unit_price;type:decimal(15,2);not null"`
@@ -134,20 +143,21 @@ type MigrationOrder struct {
 	TrackingNumber    string     `gorm:"column:tracking_number;type:varchar(100)"`
 	EstimatedDelivery *time.Time `gorm:"column:estimated_delivery;type:timestamp"`
 	DeliveredAt       *time.Time `gorm:"column:delivered_at;type:timestamp"`
-	CreatedAt         time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	StatusUpdatedBy   string     `gorm:"column:status_updated_by;type:varchar(36)"`
+	CreatedAt         time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_orders_created_at;index:idx_orders_buyer_created,priority:2;index:idx_orders_supplier_created,priority:2"`
 	UpdatedAt         time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
 }
 
 func (MigrationOrder) TableName() string { return "orders" }
 
-// MigrationRFQ matches rfqs table (001_init_schema).
+// MigrationRFQ matches rfqs table (001_init_schema, 023).
 type MigrationRFQ struct {
 	ID                    string     `gorm:"column:id;type:varchar(36);primaryKey"`
-	BuyerID               string     `gorm:"column:buyer_id;type:varchar(36);not null;index"`
+	BuyerID               string     `gorm:"column:buyer_id;type:varchar(36);not null;index;index:idx_rfqs_buyer_created,priority:1"`
 	ProductID             string     `gorm:"column:product_id;type:varchar(36)"`
 	ProductName           string     `gorm:"column:product_name;type:varchar(255);not null"`
 	ProductImage          string     `gorm:"column:product_image;type:text"`
-	SupplierID            string     `gorm:"column:supplier_id;type:varchar(36);index"`
+	SupplierID            string     `gorm:"column:supplier_id;type:varchar(36);index;index:idx_rfqs_supplier_created,priority:1"`
 	Quantity              int        `gorm:"column:quantity;not null"`
 	Unit                  string     `gorm:"column:unit;type:varchar(50);not null"`
 	Specifications        string     `gorm:"column:specifications;type:text"`
@@ -159,7 +169,7 @@ type MigrationRFQ struct {
 	Status                string     `gorm:"column:status;type:varchar(20);default:draft"`
 	SubmittedAt           *time.Time `gorm:"column:submitted_at;type:timestamp"`
 	ExpiresAt             *time.Time `gorm:"column:expires_at;type:timestamp"`
-	CreatedAt             time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	CreatedAt             time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_rfqs_created_at;index:idx_rfqs_buyer_created,priority:2;index:idx_rfqs_supplier_created,priority:2"`
 	UpdatedAt             time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
 }
 
@@ -180,6 +190,7 @@ type MigrationRFQResponse struct {
 	Message           string     `gorm:"column:message;type:text"`
 	Status            string     `gorm:"column:status;type:varchar(20);default:pending"`
 	SubmittedAt       time.Time  `gorm:"column:submitted_at;type:timestamp"`
+	RespondedBy       string     `gorm:"column:responded_by;type:varchar(36)"`
 	ExpiresAt         *time.Time `gorm:"column:expires_at;type:timestamp"`
 	CreatedAt         time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
 	UpdatedAt         time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
@@ -187,10 +198,10 @@ type MigrationRFQResponse struct {
 
 func (MigrationRFQResponse) TableName() string { return "rfq_responses" }
 
-// MigrationNotification matches notifications table (001_init_schema).
+// MigrationNotification matches notifications table (001_init_schema, 023).
 type MigrationNotification struct {
 	ID          string     `gorm:"column:id;type:varchar(36);primaryKey"`
-	UserID      string     `gorm:"column:user_id;type:varchar(36);not null;index"`
+	UserID      string     `gorm:"column:user_id;type:varchar(36);not null;index;index:idx_notifications_user_created,priority:1"`
 	Type        string     `gorm:"column:type;type:varchar(20);not null"`
 	Priority    string     `gorm:"column:priority;type:varchar(20);default:medium"`
 	Title       string     `gorm:"column:title;type:varchar(255);not null"`
@@ -200,7 +211,7 @@ type MigrationNotification struct {
 	ActionLabel string     `gorm:"column:action_label;type:varchar(100)"`
 	Read        bool       `gorm:"column:read;default:false"`
 	Metadata    string     `gorm:"column:metadata;type:text"`
-	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_notifications_user_created,priority:2"`
 	ReadAt      *time.Time `gorm:"column:read_at;type:timestamp"`
 }
 
@@ -257,45 +268,46 @@ type MigrationSubscription struct {
 
 func (MigrationSubscription) TableName() string { return "subscriptions" }
 
-// MigrationMessage matches messages table (001_init_schema).
+// MigrationMessage matches messages table (001_init_schema, 023).
 type MigrationMessage struct {
-	ID             string     `gorm:"column:id;type:varchar(36);primaryKey"`
-	ConversationID string     `gorm:"column:conversation_id;type:varchar(36);not null;index"`
-	SenderID       string     `gorm:"column:sender_id;type:varchar(36);not null;index"`
-	ReceiverID     string     `gorm:"column:receiver_id;type:varchar(36);not null;index"`
-	Subject        string     `gorm:"column:subject;type:varchar(255)"`
-	Body           string     `gorm:"column:body;type:text;not null"`
-	Attachments    string     `gorm:"column:attachments;type:text"`
-	Read           bool       `gorm:"column:read;default:false"`
-	ReadAt         *time.Time `gorm:"column:read_at;type:timestamp"`
-	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	ID               string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	ConversationID   string     `gorm:"column:conversation_id;type:varchar(36);not null;index;index:idx_messages_conversation_created,priority:1"`
+	SenderID         string     `gorm:"column:sender_id;type:varchar(36);not null;index"`
+	SenderSupplierID string     `gorm:"column:sender_supplier_id;type:varchar(36);index"`
+	ReceiverID       string     `gorm:"column:receiver_id;type:varchar(36);not null;index"`
+	Subject          string     `gorm:"column:subject;type:varchar(255)"`
+	Body             string     `gorm:"column:body;type:text;not null"`
+	Attachments      string     `gorm:"column:attachments;type:text"`
+	Read             bool       `gorm:"column:read;default:false"`
+	ReadAt           *time.Time `gorm:"column:read_at;type:timestamp"`
+	CreatedAt        time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_messages_conversation_created,priority:2"`
 }
 
 func (MigrationMessage) TableName() string { return "messages" }
 
-// MigrationReview matches reviews table (001_init_schema).
+// MigrationReview matches reviews table (001_init_schema, 023).
 type MigrationReview struct {
 	ID               string    `gorm:"column:id;type:varchar(36);primaryKey"`
-	ProductID        string    `gorm:"column:product_id;type:varchar(36);index"`
-	SupplierID       string    `gorm:"column:supplier_id;type:varchar(36);index"`
+	ProductID        string    `gorm:"column:product_id;type:varchar(36);index;index:idx_reviews_product_created,priority:1"`
+	SupplierID       string    `gorm:"column:supplier_id;type:varchar(36);index;index:idx_reviews_supplier_created,priority:1"`
 	ReviewerID       string    `gorm:"column:reviewer_id;type:varchar(36);not null;index"`
 	Rating           int       `gorm:"column:rating;not null"`
 	Title            string    `gorm:"column:title;type:varchar(255)"`
 	Comment          string    `gorm:"column:comment;type:text"`
 	VerifiedPurchase bool      `gorm:"column:verified_purchase;default:false"`
 	HelpfulCount     int       `gorm:"column:helpful_count;default:0"`
-	CreatedAt        time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	CreatedAt        time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_reviews_product_created,priority:2;index:idx_reviews_supplier_created,priority:2"`
 	UpdatedAt        time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
 }
 
 func (MigrationReview) TableName() string { return "reviews" }
 
-// MigrationFavorite matches favorites table (001_init_schema).
+// MigrationFavorite matches favorites table (001_init_schema, 023).
 type MigrationFavorite struct {
 	ID        string    `gorm:"column:id;type:varchar(36);primaryKey"`
-	UserID    string    `gorm:"column:user_id;type:varchar(36);not null;uniqueIndex:unique_user_product"`
+	UserID    string    `gorm:"column:user_id;type:varchar(36);not null;uniqueIndex:unique_user_product;index:idx_favorites_user_created,priority:1"`
 	ProductID string    `gorm:"column:product_id;type:varchar(36);not null;uniqueIndex:unique_user_product"`
-	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime;index:idx_favorites_user_created,priority:2"`
 }
 
 func (MigrationFavorite) TableName() string { return "favorites" }
@@ -312,3 +324,232 @@ type MigrationSearchHistory struct {
 }
 
 func (MigrationSearchHistory) TableName() string { return "search_history" }
+
+// MigrationRefreshToken matches refresh_tokens table (010_auth_refresh_tokens).
+type MigrationRefreshToken struct {
+	ID         string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	UserID     string     `gorm:"column:user_id;type:varchar(36);not null;index"`
+	FamilyID   string     `gorm:"column:family_id;type:varchar(36);not null;index"`
+	TokenHash  string     `gorm:"column:token_hash;type:char(64);not null"`
+	UserAgent  string     `gorm:"column:user_agent;type:varchar(512)"`
+	IPAddress  string     `gorm:"column:ip_address;type:varchar(64)"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;type:timestamp;not null;index"`
+	RotatedAt  *time.Time `gorm:"column:rotated_at;type:timestamp"`
+	ReplacedBy *string    `gorm:"column:replaced_by;type:varchar(36)"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+}
+
+func (MigrationRefreshToken) TableName() string { return "refresh_tokens" }
+
+// MigrationStaffInvitation matches staff_invitations table (018_staff_invitations).
+type MigrationStaffInvitation struct {
+	ID         string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	Email      string     `gorm:"column:email;type:varchar(255);not null;index"`
+	Role       string     `gorm:"column:role;type:varchar(30);not null"`
+	InvitedBy  string     `gorm:"column:invited_by;type:varchar(36);not null"`
+	TokenHash  string     `gorm:"column:token_hash;type:char(64);not null"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;type:timestamp;not null;index"`
+	AcceptedAt *time.Time `gorm:"column:accepted_at;type:timestamp"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+}
+
+func (MigrationStaffInvitation) TableName() string { return "staff_invitations" }
+
+// MigrationSupplierMember matches supplier_members table (019_supplier_members).
+type MigrationSupplierMember struct {
+	SupplierID string    `gorm:"column:supplier_id;type:varchar(36);primaryKey"`
+	UserID     string    `gorm:"column:user_id;type:varchar(36);primaryKey;uniqueIndex"`
+	Role       string    `gorm:"column:role;type:varchar(20);not null"`
+	InvitedBy  string    `gorm:"column:invited_by;type:varchar(36)"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
+}
+
+func (MigrationSupplierMember) TableName() string { return "supplier_members" }
+
+// MigrationSupplierInvitation matches supplier_invitations table (019_supplier_members).
+type MigrationSupplierInvitation struct {
+	ID         string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	SupplierID string     `gorm:"column:supplier_id;type:varchar(36);not null;index"`
+	Email      string     `gorm:"column:email;type:varchar(255);not null"`
+	Role       string     `gorm:"column:role;type:varchar(20);not null"`
+	InvitedBy  string     `gorm:"column:invited_by;type:varchar(36);not null"`
+	TokenHash  string     `gorm:"column:token_hash;type:char(64);not null;uniqueIndex"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;type:timestamp;not null"`
+	AcceptedAt *time.Time `gorm:"column:accepted_at;type:timestamp"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+}
+
+func (MigrationSupplierInvitation) TableName() string { return "supplier_invitations" }
+
+// MigrationImpersonationSession matches impersonation_sessions table (021_impersonation).
+type MigrationImpersonationSession struct {
+	ID        string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	ActorID   string     `gorm:"column:actor_id;type:varchar(36);not null;index"`
+	SubjectID string     `gorm:"column:subject_id;type:varchar(36);not null;index"`
+	Reason    string     `gorm:"column:reason;type:varchar(500);not null"`
+	IPAddress string     `gorm:"column:ip_address;type:varchar(64)"`
+	UserAgent string     `gorm:"column:user_agent;type:varchar(512)"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime;index"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp;not null"`
+	EndedAt   *time.Time `gorm:"column:ended_at;type:timestamp"`
+}
+
+func (MigrationImpersonationSession) TableName() string { return "impersonation_sessions" }
+
+// MigrationImpersonationRequest matches impersonation_requests table (021_impersonation).
+type MigrationImpersonationRequest struct {
+	ID              int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ImpersonationID string    `gorm:"column:impersonation_id;type:varchar(36);not null;index"`
+	ActorID         string    `gorm:"column:actor_id;type:varchar(36);not null;index"`
+	SubjectID       string    `gorm:"column:subject_id;type:varchar(36);not null;index"`
+	Method          string    `gorm:"column:method;type:varchar(10);not null"`
+	Route           string    `gorm:"column:route;type:varchar(255);not null"`
+	Path            string    `gorm:"column:path;type:varchar(2048);not null"`
+	Status          int       `gorm:"column:status;not null"`
+	IPAddress       string    `gorm:"column:ip_address;type:varchar(64)"`
+	UserAgent       string    `gorm:"column:user_agent;type:varchar(512)"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp(3);not null"`
+}
+
+func (MigrationImpersonationRequest) TableName() string { return "impersonation_requests" }
+
+// MigrationAuthSession matches auth_sessions table (017_auth_sessions).
+type MigrationAuthSession struct {
+	ID         string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	UserID     string     `gorm:"column:user_id;type:varchar(36);not null;index"`
+	UserAgent  string     `gorm:"column:user_agent;type:varchar(512)"`
+	IPAddress  string     `gorm:"column:ip_address;type:varchar(64)"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	LastSeenAt time.Time  `gorm:"column:last_seen_at;type:timestamp;not null"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;type:timestamp;not null;index"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp"`
+}
+
+func (MigrationAuthSession) TableName() string { return "auth_sessions" }
+
+// MigrationUserActionToken matches user_action_tokens table (012_auth_action_tokens).
+type MigrationUserActionToken struct {
+	ID        string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	UserID    string     `gorm:"column:user_id;type:varchar(36);not null;index:idx_user_purpose"`
+	Purpose   string     `gorm:"column:purpose;type:varchar(30);not null;index:idx_user_purpose"`
+	TokenHash string     `gorm:"column:token_hash;type:char(64);not null"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp;not null;index"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+}
+
+func (MigrationUserActionToken) TableName() string { return "user_action_tokens" }
+
+// MigrationUserMFA matches user_mfa table (013_auth_mfa).
+type MigrationUserMFA struct {
+	UserID       string     `gorm:"column:user_id;type:varchar(36);primaryKey"`
+	TOTPSecret   string     `gorm:"column:totp_secret;type:varchar(64);not null"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at;type:timestamp"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0"`
+	CreatedAt    time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
+}
+
+func (MigrationUserMFA) TableName() string { return "user_mfa" }
+
+// MigrationUserRecoveryCode matches user_recovery_codes table (013_auth_mfa).
+type MigrationUserRecoveryCode struct {
+	ID        string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	UserID    string     `gorm:"column:user_id;type:varchar(36);not null;index"`
+	CodeHash  string     `gorm:"column:code_hash;type:char(64);not null"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+}
+
+func (MigrationUserRecoveryCode) TableName() string { return "user_recovery_codes" }
+
+// MigrationLoginAttempt matches login_attempts table (014_auth_login_attempts).
+type MigrationLoginAttempt struct {
+	ID          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	AttemptKey  string    `gorm:"column:attempt_key;type:varchar(320);not null;index:idx_key_time"`
+	AttemptedAt time.Time `gorm:"column:attempted_at;type:timestamp(3);not null;index:idx_key_time"`
+}
+
+func (MigrationLoginAttempt) TableName() string { return "login_attempts" }
+
+// MigrationLoginLockout matches login_lockouts table (014_auth_login_attempts).
+type MigrationLoginLockout struct {
+	AttemptKey  string    `gorm:"column:attempt_key;type:varchar(320);primaryKey"`
+	LockedUntil time.Time `gorm:"column:locked_until;type:timestamp;not null"`
+}
+
+func (MigrationLoginLockout) TableName() string { return "login_lockouts" }
+
+// MigrationRateLimitBucket matches rate_limit_buckets table (022_rate_limits).
+type MigrationRateLimitBucket struct {
+	BucketKey string    `gorm:"column:bucket_key;type:varchar(320);primaryKey"`
+	Tokens    float64   `gorm:"column:tokens;type:double;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp(3);not null;index:idx_updated_at"`
+}
+
+func (MigrationRateLimitBucket) TableName() string { return "rate_limit_buckets" }
+
+// MigrationIdempotencyKey matches idempotency_keys table (024_idempotency_keys).
+type MigrationIdempotencyKey struct {
+	RecordKey   string    `gorm:"column:record_key;type:char(64);primaryKey"`
+	Fingerprint string    `gorm:"column:fingerprint;type:char(64);not null"`
+	StatusCode  int       `gorm:"column:status_code;type:smallint;not null;default:0"`
+	ContentType string    `gorm:"column:content_type;type:varchar(255);not null;default:''"`
+	Body        []byte    `gorm:"column:body;type:mediumblob"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;type:timestamp(3);not null;index:idx_expires_at"`
+}
+
+func (MigrationIdempotencyKey) TableName() string { return "idempotency_keys" }
+
+// MigrationSchemaVersion matches schema_version table (025_schema_version).
+type MigrationSchemaVersion struct {
+	ID        int8      `gorm:"column:id;type:tinyint;primaryKey;autoIncrement:false"`
+	Version   int       `gorm:"column:version;type:int;not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (MigrationSchemaVersion) TableName() string { return "schema_version" }
+
+// MigrationAPIKey matches api_keys table (015_auth_api_keys).
+type MigrationAPIKey struct {
+	ID         string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	UserID     string     `gorm:"column:user_id;type:varchar(36);not null;index"`
+	SupplierID *string    `gorm:"column:supplier_id;type:varchar(36);index"`
+	Name       string     `gorm:"column:name;type:varchar(100);not null"`
+	Prefix     string     `gorm:"column:prefix;type:varchar(16);not null;uniqueIndex"`
+	KeyHash    string     `gorm:"column:key_hash;type:char(64);not null"`
+	Scopes     string     `gorm:"column:scopes;type:text;not null"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;type:timestamp"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at;type:timestamp"`
+	LastUsedIP *string    `gorm:"column:last_used_ip;type:varchar(64)"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`
+}
+
+func (MigrationAPIKey) TableName() string { return "api_keys" }
+
+// MigrationUserIdentity matches user_identities table (016_auth_user_identities).
+type MigrationUserIdentity struct {
+	ID          string     `gorm:"column:id;type:varchar(36);primaryKey"`
+	UserID      string     `gorm:"column:user_id;type:varchar(36);not null;index"`
+	Provider    string     `gorm:"column:provider;type:varchar(50);not null;uniqueIndex:uq_provider_subject"`
+	Subject     string     `gorm:"column:subject;type:varchar(255);not null;uniqueIndex:uq_provider_subject"`
+	Email       string     `gorm:"column:email;type:varchar(255);not null"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at;type:timestamp"`
+	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`
+}
+
+func (MigrationUserIdentity) TableName() string { return "user_identities" }
+
+// MigrationOIDCUsedState matches oidc_used_states table (026_oidc_used_states).
+type MigrationOIDCUsedState struct {
+	ID        string    `gorm:"column:id;type:varchar(36);primaryKey"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:timestamp(3);not null;index:idx_expires_at"`
+}
+
+func (MigrationOIDCUsedState) TableName() string { return "oidc_used_states" }
@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 
 	"github.com/example/global-trade-hub/backend/internal/config"
 )
@@ -12,6 +14,8 @@ import (
 // OpenMySQL opens a MySQL connection using the low-level database/sql driver.
 // For a large system, you could wrap this with sqlx or GORM; here we keep it
 // thin and build repositories on top of *sql.DB for clarity and performance.
+// The driver is wrapped by otelsql, so every query is traced as a child span
+// of the request (see the tracing package).
 func OpenMySQL(cfg *config.Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%d)/%s?%s",
@@ -23,7 +27,10 @@ func OpenMySQL(cfg *config.Config) (*sql.DB, error) {
 		cfg.MySQLParams,
 	)
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := otelsql.Open("mysql", dsn,
+		otelsql.WithAttributes(semconv.DBSystemNameMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
 		return nil, err
 	}
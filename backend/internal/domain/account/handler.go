@@ -0,0 +1,145 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+// Handler exposes the self-service account endpoints under /me.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// mimeZIP is the media type of the archive returned by Export.
+const mimeZIP = "application/zip"
+
+// Export returns the caller's data as JSON, or as a ZIP archive with one JSON
+// file per section when format=zip or the Accept header prefers
+// application/zip. An explicit format wins over the Accept header.
+func (h *Handler) Export(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	format := c.Query("format")
+	if format == "" {
+		format = "json"
+		if c.NegotiateFormat(binding.MIMEJSON, mimeZIP) == mimeZIP {
+			format = "zip"
+		}
+	}
+	if format != "json" && format != "zip" {
+		_ = c.Error(apperr.Validation("format must be json or zip", apperr.FieldError{Field: "format", Code: "oneof", Param: "json zip"}))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	export, err := h.svc.Export(ctx, claims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Vary", "Accept")
+	name := fmt.Sprintf("global-trade-hub-export-%s", export.ExportedAt.Format("20060102-150405"))
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	// Build the archive first so a failure is still reported as an error
+	// response rather than a truncated download.
+	var buf bytes.Buffer
+	if err := writeZip(&buf, export); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+	c.Data(http.StatusOK, mimeZIP, buf.Bytes())
+}
+
+// writeZip writes export as a ZIP archive with one indented JSON file per
+// section.
+func writeZip(w io.Writer, export *Export) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"orders.json", export.Orders},
+		{"rfqs.json", export.RFQs},
+		{"messages.json", export.Messages},
+		{"reviews.json", export.Reviews},
+		{"favorites.json", export.Favorites},
+		{"search_history.json", export.SearchHistory},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Delete schedules deletion of the caller's account. The account and its
+// personal data are anonymized after the grace period unless the user signs
+// in again before then.
+func (h *Handler) Delete(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	var in auth.DeleteAccountInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.svc.RequestDeletion(ctx, claims.UserID, in.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			err = auth.ErrWrongPassword
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
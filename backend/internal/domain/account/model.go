@@ -0,0 +1,28 @@
+package account
+
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+)
+
+// Record is one exported row, keyed by column name.
+type Record map[string]any
+
+// Export is everything the platform stores about a user, as returned by
+// GET /me/export.
+type Export struct {
+	ExportedAt    time.Time  `json:"exportedAt"`
+	Profile       *auth.User `json:"profile"`
+	Orders        []Record   `json:"orders"`
+	RFQs          []Record   `json:"rfqs"`
+	Messages      []Record   `json:"messages"`
+	Reviews       []Record   `json:"reviews"`
+	Favorites     []Record   `json:"favorites"`
+	SearchHistory []Record   `json:"searchHistory"`
+}
+
+// DeleteResult tells the user when their account will be deleted.
+type DeleteResult struct {
+	DeletionScheduledAt time.Time `json:"deletionScheduledAt"`
+}
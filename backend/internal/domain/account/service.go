@@ -0,0 +1,278 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/health"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
+
+var (
+	// ErrStaffAccount is returned when a staff account asks to delete itself;
+	// staff accounts are removed by an admin instead.
+	ErrStaffAccount = apperr.Forbidden("staff_account", "staff accounts cannot be deleted from self-service")
+	// ErrLastOwner is returned when the user is the only owner of a supplier
+	// organization that still has other members.
+	ErrLastOwner = apperr.Conflict("transfer_ownership_first", "transfer ownership of your supplier organization before deleting your account")
+)
+
+// Service implements data export and account deletion for the signed-in
+// user. Deletion is deferred: a request schedules it, and PurgeDue anonymizes
+// accounts whose grace period has passed.
+type Service struct {
+	db    *sql.DB
+	auth  *auth.Service
+	grace time.Duration
+}
+
+func NewService(db *sql.DB, authSvc *auth.Service, grace time.Duration) *Service {
+	return &Service{db: db, auth: authSvc, grace: grace}
+}
+
+// Export collects the user's profile and the content they created.
+func (s *Service) Export(ctx context.Context, userID string) (*Export, error) {
+	ctx, span := tracing.Start(ctx, "account.Service.Export")
+	defer span.End()
+
+	u, err := s.auth.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columns are listed so that only the user's own data is exported, not
+	// whatever a later migration adds to these tables.
+	out := &Export{ExportedAt: time.Now().UTC(), Profile: u}
+	sections := []struct {
+		name  string
+		query string
+		dst   *[]Record
+	}{
+		{"orders", `
+SELECT id, order_number, supplier_id, product_id, quantity, unit_price, total_amount,
+       currency, status, payment_status, payment_method, shipping_address, shipping_method,
+       tracking_number, estimated_delivery, delivered_at, created_at, updated_at
+FROM orders WHERE buyer_id = ? ORDER BY created_at`, &out.Orders},
+		{"rfqs", `
+SELECT id, product_id, product_name, product_image, supplier_id, quantity, unit,
+       specifications, requirements, delivery_location, preferred_delivery_date, budget,
+       currency, status, submitted_at, expires_at, created_at, updated_at
+FROM rfqs WHERE buyer_id = ? ORDER BY created_at`, &out.RFQs},
+		{"messages", `
+SELECT id, conversation_id, sender_id, receiver_id, subject, body, attachments,
+       ` + "`read`" + `, read_at, created_at
+FROM messages WHERE ? IN (sender_id, receiver_id) ORDER BY created_at`, &out.Messages},
+		{"reviews", `
+SELECT id, product_id, supplier_id, rating, title, comment, verified_purchase,
+       helpful_count, created_at, updated_at
+FROM reviews WHERE reviewer_id = ? ORDER BY created_at`, &out.Reviews},
+		{"favorites", `
+SELECT id, product_id, created_at
+FROM favorites WHERE user_id = ? ORDER BY created_at`, &out.Favorites},
+		{"search history", `
+SELECT id, query, search_type, filters, result_count, created_at
+FROM search_history WHERE user_id = ? ORDER BY created_at`, &out.SearchHistory},
+	}
+	for _, sec := range sections {
+		records, err := s.dump(ctx, sec.query, userID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", sec.name, err)
+		}
+		*sec.dst = records
+	}
+	return out, nil
+}
+
+// dump returns every row of query as a Record.
+func (s *Service) dump(ctx context.Context, query string, args ...any) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	out := []Record{}
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		rec := make(Record, len(cols))
+		for i, col := range cols {
+			// The driver returns text and decimal columns as bytes.
+			if b, ok := values[i].([]byte); ok {
+				rec[col] = string(b)
+			} else {
+				rec[col] = values[i]
+			}
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// RequestDeletion schedules deletion of the user's account after the grace
+// period, once the password is confirmed.
+func (s *Service) RequestDeletion(ctx context.Context, userID, password string) (*DeleteResult, error) {
+	ctx, span := tracing.Start(ctx, "account.Service.RequestDeletion")
+	defer span.End()
+
+	u, err := s.auth.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Role.IsStaff() {
+		return nil, ErrStaffAccount
+	}
+
+	var blocking int
+	err = s.db.QueryRowContext(ctx, `
+SELECT COUNT(*)
+FROM supplier_members m
+WHERE m.user_id = ? AND m.role = 'owner'
+  AND NOT EXISTS (
+    SELECT 1 FROM supplier_members o
+    WHERE o.supplier_id = m.supplier_id AND o.role = 'owner' AND o.user_id <> m.user_id)
+  AND EXISTS (
+    SELECT 1 FROM supplier_members x
+    WHERE x.supplier_id = m.supplier_id AND x.user_id <> m.user_id)`, userID).Scan(&blocking)
+	if err != nil {
+		return nil, err
+	}
+	if blocking > 0 {
+		return nil, ErrLastOwner
+	}
+
+	u, err = s.auth.ScheduleDeletion(ctx, userID, password, s.grace)
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteResult{DeletionScheduledAt: *u.DeletionScheduledAt}, nil
+}
+
+// PurgeDue anonymizes every account whose scheduled deletion is at or before
+// now and returns how many were processed.
+func (s *Service) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "account.Service.PurgeDue")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?`, now)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if err := s.anonymize(ctx, id, now); err != nil {
+			logging.FromContext(ctx).Error("account: purge failed", "user_id", id, "error", err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// anonymize replaces the user's personal data with placeholders and removes
+// their private records. Orders, RFQs and reviews stay for the other parties
+// and now point at the anonymized user; the content of messages they sent is
+// redacted.
+func (s *Service) anonymize(ctx context.Context, userID string, now time.Time) error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE users
+SET email = ?, full_name = 'Deleted user', phone = NULL, locale = NULL, avatar_url = NULL,
+    password_hash = ?, status = ?, must_change_password = FALSE,
+    deletion_scheduled_at = NULL, deleted_at = ?, updated_at = ?
+WHERE id = ?`,
+		fmt.Sprintf("deleted-%s@deleted.invalid", userID), string(hash), auth.StatusDeleted, now, now, userID,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE messages SET subject = NULL, body = '[deleted]', attachments = NULL WHERE sender_id = ?`, userID,
+	); err != nil {
+		return err
+	}
+
+	for _, table := range []string{
+		"favorites", "search_history", "notifications", "api_keys", "user_identities",
+		"user_mfa", "user_recovery_codes", "user_action_tokens", "supplier_members",
+	} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE user_id = ?", userID); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return s.auth.LogoutAll(ctx, userID)
+}
+
+// RunPurger calls PurgeDue every interval until ctx is cancelled, beating
+// the heartbeat ctx carries (see health.WithHeartbeat) each time.
+func (s *Service) RunPurger(ctx context.Context, interval time.Duration) {
+	logger := logging.FromContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		health.Beat(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.PurgeDue(ctx, now.UTC())
+			if err != nil {
+				logger.Error("account: purge failed", "error", err)
+			} else if n > 0 {
+				logger.Info("account: anonymized deleted accounts", "count", n)
+			}
+		}
+	}
+}
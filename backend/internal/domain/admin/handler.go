@@ -8,6 +8,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/domain/auth"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
 )
@@ -20,17 +22,18 @@ func NewHandler(svc *Service) *Handler {
 	return &Handler{svc: svc}
 }
 
-// requireAdmin is a helper to check admin role
-func requireAdmin(c *gin.Context) (*middleware.Claims, bool) {
+// requireStaff is a helper to check for an admin or staff role. The route
+// policy decides which staff roles may use each endpoint.
+func requireStaff(c *gin.Context) (*middleware.Claims, bool) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return nil, false
 	}
 	claims := raw.(*middleware.Claims)
 
-	if claims.Role != string(auth.RoleAdmin) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+	if !auth.UserRole(claims.Role).IsStaff() {
+		_ = c.Error(authz.ErrAdminRequired)
 		return nil, false
 	}
 
@@ -39,7 +42,7 @@ func requireAdmin(c *gin.Context) (*middleware.Claims, bool) {
 
 // GetDashboardStats returns dashboard statistics
 func (h *Handler) GetDashboardStats(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -48,7 +51,7 @@ func (h *Handler) GetDashboardStats(c *gin.Context) {
 
 	stats, err := h.svc.GetDashboardStats(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -57,7 +60,7 @@ func (h *Handler) GetDashboardStats(c *gin.Context) {
 
 // GetSalesData returns sales metrics over time
 func (h *Handler) GetSalesData(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -71,7 +74,7 @@ func (h *Handler) GetSalesData(c *gin.Context) {
 
 	data, err := h.svc.GetSalesData(ctx, days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -80,7 +83,7 @@ func (h *Handler) GetSalesData(c *gin.Context) {
 
 // GetCategoryStats returns category distribution statistics
 func (h *Handler) GetCategoryStats(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -89,7 +92,7 @@ func (h *Handler) GetCategoryStats(c *gin.Context) {
 
 	stats, err := h.svc.GetCategoryStats(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -98,7 +101,7 @@ func (h *Handler) GetCategoryStats(c *gin.Context) {
 
 // GetTopProducts returns best-selling products
 func (h *Handler) GetTopProducts(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -112,7 +115,7 @@ func (h *Handler) GetTopProducts(c *gin.Context) {
 
 	products, err := h.svc.GetTopProducts(ctx, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -121,7 +124,7 @@ func (h *Handler) GetTopProducts(c *gin.Context) {
 
 // GetUserStats returns user growth metrics
 func (h *Handler) GetUserStats(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -135,7 +138,7 @@ func (h *Handler) GetUserStats(c *gin.Context) {
 
 	stats, err := h.svc.GetUserStats(ctx, days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -144,7 +147,7 @@ func (h *Handler) GetUserStats(c *gin.Context) {
 
 // GetRecentActivities returns recent platform activities
 func (h *Handler) GetRecentActivities(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -158,7 +161,7 @@ func (h *Handler) GetRecentActivities(c *gin.Context) {
 
 	activities, err := h.svc.GetRecentActivities(ctx, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -167,7 +170,7 @@ func (h *Handler) GetRecentActivities(c *gin.Context) {
 
 // ListBuyers returns all buyers with their statistics
 func (h *Handler) ListBuyers(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -186,7 +189,7 @@ func (h *Handler) ListBuyers(c *gin.Context) {
 
 	buyers, err := h.svc.ListBuyers(ctx, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -195,7 +198,8 @@ func (h *Handler) ListBuyers(c *gin.Context) {
 
 // UpdateUserStatus updates a user's status (active, inactive, suspended)
 func (h *Handler) UpdateUserStatus(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	claims, ok := requireStaff(c)
+	if !ok {
 		return
 	}
 
@@ -203,15 +207,15 @@ func (h *Handler) UpdateUserStatus(c *gin.Context) {
 
 	var input UpdateUserStatusInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	if err := h.svc.UpdateUserStatus(ctx, userID, input.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.svc.UpdateUserStatus(ctx, claims.UserID, userID, input.Status); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -220,7 +224,7 @@ func (h *Handler) UpdateUserStatus(c *gin.Context) {
 
 // ListProducts returns all products for admin
 func (h *Handler) ListProducts(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -241,7 +245,7 @@ func (h *Handler) ListProducts(c *gin.Context) {
 
 	products, err := h.svc.ListProducts(ctx, limit, offset, status, category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -250,7 +254,7 @@ func (h *Handler) ListProducts(c *gin.Context) {
 
 // UpdateProductStatus updates a product's status
 func (h *Handler) UpdateProductStatus(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -258,7 +262,7 @@ func (h *Handler) UpdateProductStatus(c *gin.Context) {
 
 	var input UpdateProductStatusInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
@@ -266,7 +270,7 @@ func (h *Handler) UpdateProductStatus(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.UpdateProductStatus(ctx, productID, &input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -275,7 +279,7 @@ func (h *Handler) UpdateProductStatus(c *gin.Context) {
 
 // DeleteProduct soft deletes a product
 func (h *Handler) DeleteProduct(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -285,7 +289,7 @@ func (h *Handler) DeleteProduct(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.DeleteProduct(ctx, productID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -294,7 +298,7 @@ func (h *Handler) DeleteProduct(c *gin.Context) {
 
 // ListOrders returns all orders for admin
 func (h *Handler) ListOrders(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -315,7 +319,7 @@ func (h *Handler) ListOrders(c *gin.Context) {
 
 	orders, err := h.svc.ListOrders(ctx, limit, offset, status, paymentStatus)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -324,7 +328,7 @@ func (h *Handler) ListOrders(c *gin.Context) {
 
 // UpdateOrderStatus updates an order's status
 func (h *Handler) UpdateOrderStatus(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -332,7 +336,7 @@ func (h *Handler) UpdateOrderStatus(c *gin.Context) {
 
 	var input UpdateOrderStatusInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
@@ -340,7 +344,7 @@ func (h *Handler) UpdateOrderStatus(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.UpdateOrderStatus(ctx, orderID, &input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -349,7 +353,7 @@ func (h *Handler) UpdateOrderStatus(c *gin.Context) {
 
 // ListSuppliers returns all suppliers for admin
 func (h *Handler) ListSuppliers(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -370,7 +374,7 @@ func (h *Handler) ListSuppliers(c *gin.Context) {
 
 	suppliers, err := h.svc.ListSuppliers(ctx, limit, offset, status, subscription)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -379,7 +383,8 @@ func (h *Handler) ListSuppliers(c *gin.Context) {
 
 // UpdateSupplierStatus updates a supplier's status
 func (h *Handler) UpdateSupplierStatus(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	claims, ok := requireStaff(c)
+	if !ok {
 		return
 	}
 
@@ -387,15 +392,15 @@ func (h *Handler) UpdateSupplierStatus(c *gin.Context) {
 
 	var input UpdateSupplierStatusInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	if err := h.svc.UpdateSupplierStatus(ctx, supplierID, &input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.svc.UpdateSupplierStatus(ctx, claims.UserID, supplierID, &input); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -404,7 +409,7 @@ func (h *Handler) UpdateSupplierStatus(c *gin.Context) {
 
 // ListVerifications returns all verification requests
 func (h *Handler) ListVerifications(c *gin.Context) {
-	if _, ok := requireAdmin(c); !ok {
+	if _, ok := requireStaff(c); !ok {
 		return
 	}
 
@@ -424,7 +429,7 @@ func (h *Handler) ListVerifications(c *gin.Context) {
 
 	verifications, err := h.svc.ListVerifications(ctx, limit, offset, status)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -433,7 +438,7 @@ func (h *Handler) ListVerifications(c *gin.Context) {
 
 // ReviewVerification approves or rejects a verification request
 func (h *Handler) ReviewVerification(c *gin.Context) {
-	adminUser, ok := requireAdmin(c)
+	adminUser, ok := requireStaff(c)
 	if !ok {
 		return
 	}
@@ -442,7 +447,7 @@ func (h *Handler) ReviewVerification(c *gin.Context) {
 
 	var input ReviewVerificationInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
@@ -450,7 +455,7 @@ func (h *Handler) ReviewVerification(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.ReviewVerification(ctx, verificationID, adminUser.ID, &input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
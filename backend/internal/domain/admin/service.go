@@ -3,20 +3,41 @@ package admin
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/domain/order"
+	"github.com/example/global-trade-hub/backend/internal/domain/product"
+	"github.com/example/global-trade-hub/backend/internal/domain/supplier"
+	"github.com/example/global-trade-hub/backend/internal/domain/verification"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
-	db *sql.DB
+	db    *sql.DB
+	users *auth.Service
+	cache *httpcache.Cache
+}
+
+// NewService returns the admin service; user status changes go through
+// users, which guards admins and signs out deactivated accounts.
+func NewService(db *sql.DB, users *auth.Service) *Service {
+	return &Service{db: db, users: users}
 }
 
-func NewService(db *sql.DB) *Service {
-	return &Service{db: db}
+// WithCache sets the response cache whose copies of a product or supplier
+// are dropped when moderation changes it.
+func (s *Service) WithCache(cache *httpcache.Cache) {
+	s.cache = cache
 }
 
 // GetDashboardStats returns overall platform statistics
 func (s *Service) GetDashboardStats(ctx context.Context) (*DashboardStats, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.GetDashboardStats")
+	defer span.End()
+
 	var stats DashboardStats
 
 	// Get total users
@@ -95,6 +116,9 @@ func (s *Service) GetDashboardStats(ctx context.Context) (*DashboardStats, error
 
 // GetSalesData returns sales metrics over time
 func (s *Service) GetSalesData(ctx context.Context, days int) ([]*SalesData, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.GetSalesData")
+	defer span.End()
+
 	query := `
 SELECT 
 	DATE(created_at) as date,
@@ -126,6 +150,9 @@ ORDER BY date ASC`
 
 // GetCategoryStats returns product and revenue distribution by category
 func (s *Service) GetCategoryStats(ctx context.Context) ([]*CategoryStats, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.GetCategoryStats")
+	defer span.End()
+
 	query := `
 SELECT 
 	c.id,
@@ -171,6 +198,9 @@ ORDER BY revenue DESC`
 
 // GetTopProducts returns best-selling products
 func (s *Service) GetTopProducts(ctx context.Context, limit int) ([]*TopProduct, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.GetTopProducts")
+	defer span.End()
+
 	query := `
 SELECT 
 	p.id,
@@ -206,6 +236,9 @@ LIMIT ?`
 
 // GetUserStats returns user growth metrics
 func (s *Service) GetUserStats(ctx context.Context, days int) ([]*UserStats, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.GetUserStats")
+	defer span.End()
+
 	query := `
 SELECT 
 	DATE(created_at) as date,
@@ -239,6 +272,9 @@ ORDER BY date ASC`
 
 // GetRecentActivities returns recent platform activities
 func (s *Service) GetRecentActivities(ctx context.Context, limit int) ([]*RecentActivity, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.GetRecentActivities")
+	defer span.End()
+
 	// This combines multiple tables - simplified version
 	// In production, you might want a dedicated activities/audit log table
 
@@ -285,6 +321,9 @@ LIMIT ?`
 
 // ListBuyers returns all buyers with their statistics
 func (s *Service) ListBuyers(ctx context.Context, limit, offset int) ([]*BuyerListItem, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.ListBuyers")
+	defer span.End()
+
 	query := `
 SELECT 
 	u.id,
@@ -325,27 +364,21 @@ LIMIT ? OFFSET ?`
 	return buyers, rows.Err()
 }
 
-// UpdateUserStatus updates a user's status (admin only)
-func (s *Service) UpdateUserStatus(ctx context.Context, userID string, status string) error {
-	query := `UPDATE users SET status = ?, updated_at = NOW() WHERE id = ?`
-	result, err := s.db.ExecContext(ctx, query, status, userID)
-	if err != nil {
-		return err
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return fmt.Errorf("user not found")
-	}
+// UpdateUserStatus updates a user's status (admin only) on behalf of
+// actorID; see auth.Service.UpdateUserStatus.
+func (s *Service) UpdateUserStatus(ctx context.Context, actorID, userID string, status string) error {
+	ctx, span := tracing.Start(ctx, "admin.Service.UpdateUserStatus")
+	defer span.End()
 
-	return nil
+	_, err := s.users.UpdateUserStatus(ctx, actorID, userID, auth.UserStatus(status))
+	return err
 }
 
 // ListProducts returns all products for admin with filters
 func (s *Service) ListProducts(ctx context.Context, limit, offset int, status, category string) ([]*AdminProduct, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.ListProducts")
+	defer span.End()
+
 	query := `
 SELECT 
 	p.id,
@@ -418,6 +451,9 @@ LIMIT ? OFFSET ?`
 
 // UpdateProductStatus changes a product's status
 func (s *Service) UpdateProductStatus(ctx context.Context, productID string, input *UpdateProductStatusInput) error {
+	ctx, span := tracing.Start(ctx, "admin.Service.UpdateProductStatus")
+	defer span.End()
+
 	query := `UPDATE products SET status = ?, updated_at = NOW() WHERE id = ?`
 	result, err := s.db.ExecContext(ctx, query, input.Status, productID)
 	if err != nil {
@@ -429,14 +465,18 @@ func (s *Service) UpdateProductStatus(ctx context.Context, productID string, inp
 		return err
 	}
 	if rows == 0 {
-		return fmt.Errorf("product not found")
+		return product.ErrNotFound
 	}
 
+	s.cache.Invalidate("product:" + productID)
 	return nil
 }
 
 // DeleteProduct soft deletes a product
 func (s *Service) DeleteProduct(ctx context.Context, productID string) error {
+	ctx, span := tracing.Start(ctx, "admin.Service.DeleteProduct")
+	defer span.End()
+
 	query := `UPDATE products SET status = 'inactive', updated_at = NOW() WHERE id = ?`
 	result, err := s.db.ExecContext(ctx, query, productID)
 	if err != nil {
@@ -448,14 +488,18 @@ func (s *Service) DeleteProduct(ctx context.Context, productID string) error {
 		return err
 	}
 	if rows == 0 {
-		return fmt.Errorf("product not found")
+		return product.ErrNotFound
 	}
 
+	s.cache.Invalidate("product:" + productID)
 	return nil
 }
 
 // ListOrders returns all orders for admin with filters
 func (s *Service) ListOrders(ctx context.Context, limit, offset int, status, paymentStatus string) ([]*AdminOrder, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.ListOrders")
+	defer span.End()
+
 	query := `
 SELECT 
 	o.id,
@@ -525,6 +569,9 @@ LIMIT ? OFFSET ?`
 
 // UpdateOrderStatus changes an order's status
 func (s *Service) UpdateOrderStatus(ctx context.Context, orderID string, input *UpdateOrderStatusInput) error {
+	ctx, span := tracing.Start(ctx, "admin.Service.UpdateOrderStatus")
+	defer span.End()
+
 	query := `UPDATE orders SET status = ?, updated_at = NOW() WHERE id = ?`
 	result, err := s.db.ExecContext(ctx, query, input.Status, orderID)
 	if err != nil {
@@ -536,7 +583,7 @@ func (s *Service) UpdateOrderStatus(ctx context.Context, orderID string, input *
 		return err
 	}
 	if rows == 0 {
-		return fmt.Errorf("order not found")
+		return order.ErrNotFound
 	}
 
 	return nil
@@ -544,6 +591,9 @@ func (s *Service) UpdateOrderStatus(ctx context.Context, orderID string, input *
 
 // ListSuppliers returns all suppliers for admin
 func (s *Service) ListSuppliers(ctx context.Context, limit, offset int, status, subscription string) ([]*AdminSupplier, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.ListSuppliers")
+	defer span.End()
+
 	query := `
 SELECT 
 	u.id,
@@ -610,27 +660,47 @@ LIMIT ? OFFSET ?`
 	return suppliers, rows.Err()
 }
 
-// UpdateSupplierStatus changes a supplier's status
-func (s *Service) UpdateSupplierStatus(ctx context.Context, supplierID string, input *UpdateSupplierStatusInput) error {
-	query := `UPDATE users SET status = ?, updated_at = NOW() WHERE id = ? AND role = 'supplier'`
-	result, err := s.db.ExecContext(ctx, query, input.Status, supplierID)
+// UpdateSupplierStatus changes a supplier's status on behalf of actorID. Like
+// UpdateUserStatus it goes through auth.Service, so deactivating a supplier
+// signs it out.
+func (s *Service) UpdateSupplierStatus(ctx context.Context, actorID, supplierID string, input *UpdateSupplierStatusInput) error {
+	ctx, span := tracing.Start(ctx, "admin.Service.UpdateSupplierStatus")
+	defer span.End()
+
+	u, err := s.users.GetUserByID(ctx, supplierID)
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return supplier.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
+	if u.Role != auth.RoleSupplier {
+		return supplier.ErrNotFound
+	}
+
+	if _, err := s.users.UpdateUserStatus(ctx, actorID, supplierID, auth.UserStatus(input.Status)); err != nil {
+		return err
+	}
 
-	rows, err := result.RowsAffected()
+	// supplierID is the supplier's account; its public page is cached under
+	// the ID of its organization.
+	var orgID string
+	err = s.db.QueryRowContext(ctx, `SELECT id FROM suppliers WHERE user_id = ? LIMIT 1`, supplierID).Scan(&orgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("supplier not found")
-	}
-
+	s.cache.Invalidate("supplier:" + orgID)
 	return nil
 }
 
 // ListVerifications returns all verification requests for admin
 func (s *Service) ListVerifications(ctx context.Context, limit, offset int, status string) ([]*AdminVerification, error) {
+	ctx, span := tracing.Start(ctx, "admin.Service.ListVerifications")
+	defer span.End()
+
 	query := `
 SELECT 
 	v.id,
@@ -686,6 +756,9 @@ LIMIT ? OFFSET ?`
 
 // ReviewVerification approves or rejects a verification request
 func (s *Service) ReviewVerification(ctx context.Context, verificationID, adminID string, input *ReviewVerificationInput) error {
+	ctx, span := tracing.Start(ctx, "admin.Service.ReviewVerification")
+	defer span.End()
+
 	query := `
 UPDATE verifications 
 SET status = ?, reviewed_by = ?, reviewed_at = NOW(), review_message = ?, updated_at = NOW()
@@ -701,7 +774,7 @@ WHERE id = ?`
 		return err
 	}
 	if rows == 0 {
-		return fmt.Errorf("verification not found")
+		return verification.ErrNotFound
 	}
 
 	// If approved, update user's verified status
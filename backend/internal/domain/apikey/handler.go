@@ -0,0 +1,133 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// List returns the current user's API keys (protected).
+func (h *Handler) List(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	keys, err := h.svc.List(ctx, claims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if keys == nil {
+		keys = []*APIKey{}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": keys})
+}
+
+// Create issues a new API key; the secret is only in this response (protected).
+func (h *Handler) Create(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var in CreateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	created, err := h.svc.Create(ctx, claims.UserID, in)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// Get returns a single API key (protected).
+func (h *Handler) Get(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	k, err := h.svc.Get(ctx, claims.UserID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// Update renames an API key or changes its scopes (protected).
+func (h *Handler) Update(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var in UpdateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	k, err := h.svc.Update(ctx, claims.UserID, c.Param("id"), in)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// Revoke disables an API key (protected).
+func (h *Handler) Revoke(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.Revoke(ctx, claims.UserID, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func currentClaims(c *gin.Context) (*middleware.Claims, bool) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return nil, false
+	}
+	return raw.(*middleware.Claims), true
+}
@@ -0,0 +1,70 @@
+package apikey
+
+import "time"
+
+// Scopes an API key can be granted. A scope is "<resource>:<action>", where
+// resource is the first path segment under /api/v1 and action is "read" for
+// GET/HEAD requests and "write" for everything else.
+var Scopes = []string{
+	"products:read", "products:write",
+	"suppliers:read", "suppliers:write",
+	"orders:read", "orders:write",
+	"rfqs:read", "rfqs:write",
+	"notifications:read", "notifications:write",
+	"messages:read", "messages:write",
+}
+
+// Owner types for a key.
+const (
+	OwnerUser     = "user"
+	OwnerSupplier = "supplier"
+)
+
+// APIKey is a long-lived credential for integrations. Only a SHA-256 hash of
+// the secret is stored. A key owned by a supplier profile (SupplierID set)
+// belongs to the organization and acts as the profile's account.
+type APIKey struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"userId"`
+	SupplierID *string    `db:"supplier_id" json:"supplierId,omitempty"`
+	Name       string     `db:"name" json:"name"`
+	Prefix     string     `db:"prefix" json:"prefix"`
+	KeyHash    string     `db:"key_hash" json:"-"`
+	Scopes     []string   `db:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt,omitempty"`
+	LastUsedIP *string    `db:"last_used_ip" json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updatedAt"`
+
+	// ActingUserID is the account requests made with the key run as: the
+	// supplier profile's user for organization keys, else UserID.
+	ActingUserID string `db:"-" json:"-"`
+}
+
+// Active reports whether the key can still authenticate at t.
+func (k *APIKey) Active(t time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || t.Before(*k.ExpiresAt))
+}
+
+// CreateInput is the payload for creating a key.
+type CreateInput struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// Owner is "user" (default) or "supplier" for the caller's supplier profile.
+	Owner     string     `json:"owner" binding:"omitempty,oneof=user supplier"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// UpdateInput changes a key's name or scopes. Omitted fields are kept.
+type UpdateInput struct {
+	Name   *string  `json:"name" binding:"omitempty,max=100"`
+	Scopes []string `json:"scopes" binding:"omitempty,min=1"`
+}
+
+// CreatedKey is returned once on creation; Key is never shown again.
+type CreatedKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
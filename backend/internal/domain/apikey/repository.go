@@ -0,0 +1,188 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+)
+
+var (
+	// ErrNotFound is returned when no API key matches.
+	ErrNotFound = apperr.NotFound("api_key_not_found", "api key not found")
+	// ErrNoSupplierProfile is returned when an organization key is requested
+	// by a user without a supplier profile.
+	ErrNoSupplierProfile = apperr.BadRequest("no_supplier_profile", "supplier profile not found")
+)
+
+// Repository persists API keys.
+type Repository interface {
+	Create(ctx context.Context, k *APIKey) error
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+	// GetByPrefix loads a key by its public prefix, including revoked and
+	// expired keys, and resolves ActingUserID.
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	// ListForOwner returns keys created by userID plus keys of supplierID
+	// (when not empty), newest first.
+	ListForOwner(ctx context.Context, userID, supplierID string) ([]*APIKey, error)
+	Update(ctx context.Context, k *APIKey) error
+	Revoke(ctx context.Context, id string, at time.Time) error
+	TouchLastUsed(ctx context.Context, id, ip string, at time.Time) error
+}
+
+type mySQLAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAPIKeyRepository returns a MySQL-backed implementation.
+func NewMySQLAPIKeyRepository(db *sql.DB) Repository {
+	return &mySQLAPIKeyRepository{db: db}
+}
+
+const selectColumns = `
+SELECT k.id, k.user_id, k.supplier_id, k.name, k.prefix, k.key_hash, k.scopes,
+       k.expires_at, k.last_used_at, k.last_used_ip, k.revoked_at, k.created_at, k.updated_at,
+       COALESCE(s.user_id, k.user_id)
+FROM api_keys k
+LEFT JOIN suppliers s ON s.id = k.supplier_id`
+
+func (r *mySQLAPIKeyRepository) Create(ctx context.Context, k *APIKey) error {
+	if k.ID == "" {
+		k.ID = uuid.NewString()
+	}
+	now := time.Now().UTC()
+	k.CreatedAt = now
+	k.UpdatedAt = now
+
+	const query = `
+INSERT INTO api_keys (id, user_id, supplier_id, name, prefix, key_hash, scopes, expires_at, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		k.ID,
+		k.UserID,
+		k.SupplierID,
+		k.Name,
+		k.Prefix,
+		k.KeyHash,
+		strings.Join(k.Scopes, " "),
+		k.ExpiresAt,
+		k.CreatedAt,
+		k.UpdatedAt,
+	)
+	return err
+}
+
+func (r *mySQLAPIKeyRepository) GetByID(ctx context.Context, id string) (*APIKey, error) {
+	return r.getOne(ctx, selectColumns+` WHERE k.id = ? LIMIT 1`, id)
+}
+
+func (r *mySQLAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	return r.getOne(ctx, selectColumns+` WHERE k.prefix = ? LIMIT 1`, prefix)
+}
+
+func (r *mySQLAPIKeyRepository) getOne(ctx context.Context, query string, arg string) (*APIKey, error) {
+	k, err := scanKey(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *mySQLAPIKeyRepository) ListForOwner(ctx context.Context, userID, supplierID string) ([]*APIKey, error) {
+	query := selectColumns + ` WHERE k.user_id = ?`
+	args := []interface{}{userID}
+	if supplierID != "" {
+		query += ` OR k.supplier_id = ?`
+		args = append(args, supplierID)
+	}
+	query += ` ORDER BY k.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *mySQLAPIKeyRepository) Update(ctx context.Context, k *APIKey) error {
+	k.UpdatedAt = time.Now().UTC()
+	const query = `UPDATE api_keys SET name = ?, scopes = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, k.Name, strings.Join(k.Scopes, " "), k.UpdatedAt, k.ID)
+	return err
+}
+
+func (r *mySQLAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	const query = `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res)
+}
+
+func (r *mySQLAPIKeyRepository) TouchLastUsed(ctx context.Context, id, ip string, at time.Time) error {
+	const query = `UPDATE api_keys SET last_used_at = ?, last_used_ip = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, at, ip, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKey(row rowScanner) (*APIKey, error) {
+	var (
+		k      APIKey
+		scopes string
+	)
+	if err := row.Scan(
+		&k.ID,
+		&k.UserID,
+		&k.SupplierID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&scopes,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.LastUsedIP,
+		&k.RevokedAt,
+		&k.CreatedAt,
+		&k.UpdatedAt,
+		&k.ActingUserID,
+	); err != nil {
+		return nil, err
+	}
+	k.Scopes = strings.Fields(scopes)
+	return &k, nil
+}
+
+func requireRow(res sql.Result) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
+
+// keyPrefix marks platform API keys so they are easy to recognise (and to
+// catch in secret scanners). Full format: gth_<prefix>_<secret>.
+const keyPrefix = "gth_"
+
+// lastUsedResolution limits how often last-used tracking writes to the DB.
+const lastUsedResolution = time.Minute
+
+var (
+	ErrRoleNotAllowed = apperr.Forbidden("api_keys_not_allowed", "api keys are not available for this role")
+	ErrInvalidScope   = apperr.BadRequest("invalid_scope", "invalid scope")
+	ErrExpiryInPast   = apperr.BadRequest("expiry_in_past", "expiresAt must be in the future")
+	ErrAlreadyRevoked = apperr.Conflict("api_key_already_revoked", "api key already revoked")
+)
+
+// eligibleRoles may create API keys.
+var eligibleRoles = map[auth.UserRole]bool{
+	auth.RoleSupplier: true,
+	auth.RoleBuyer:    true,
+}
+
+// supplierScopePermissions is the team permission a member needs for each
+// scope of an organization key. Other scopes need no more than the
+// SupplierMembersManage permission required to manage organization keys.
+var supplierScopePermissions = map[string]authz.Permission{
+	"products:write":  authz.ProductsWrite,
+	"suppliers:write": authz.SuppliersWrite,
+	"orders:read":     authz.OrdersView,
+	"orders:write":    authz.OrdersUpdateStatus,
+	"rfqs:write":      authz.RFQsRespond,
+	"messages:read":   authz.MessagesSend,
+	"messages:write":  authz.MessagesSend,
+}
+
+// Service manages API keys and authenticates requests made with them.
+type Service struct {
+	repo      Repository
+	users     auth.UserRepository
+	suppliers authz.SupplierResolver
+}
+
+func NewService(repo Repository, users auth.UserRepository, suppliers authz.SupplierResolver) *Service {
+	return &Service{repo: repo, users: users, suppliers: suppliers}
+}
+
+// List returns the caller's keys and the keys of their supplier profile.
+func (s *Service) List(ctx context.Context, userID string) ([]*APIKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.List")
+	defer span.End()
+
+	supplierID, _, err := s.suppliers.SupplierMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListForOwner(ctx, userID, supplierID)
+}
+
+// Create issues a new key. The returned secret is not stored and cannot be
+// retrieved later. Organization keys can only be created by owners and
+// managers, with scopes their team role allows.
+func (s *Service) Create(ctx context.Context, userID string, in CreateInput) (*CreatedKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.Create")
+	defer span.End()
+
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !eligibleRoles[u.Role] {
+		return nil, ErrRoleNotAllowed
+	}
+	scopes, err := normalizeScopes(in.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, ErrExpiryInPast
+	}
+
+	k := &APIKey{
+		UserID:       u.ID,
+		Name:         strings.TrimSpace(in.Name),
+		Scopes:       scopes,
+		ExpiresAt:    in.ExpiresAt,
+		ActingUserID: u.ID,
+	}
+	if in.Owner == OwnerSupplier {
+		supplierID, role, err := s.suppliers.SupplierMembership(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		if supplierID == "" {
+			return nil, ErrNoSupplierProfile
+		}
+		if !memberAllows(role, scopes) {
+			return nil, authz.ErrTeamRoleForbidden
+		}
+		k.SupplierID = &supplierID
+	}
+
+	prefix, secret, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	plain := keyPrefix + prefix + "_" + secret
+	k.Prefix = prefix
+	k.KeyHash = hashKey(plain)
+
+	if err := s.repo.Create(ctx, k); err != nil {
+		return nil, err
+	}
+	return &CreatedKey{APIKey: k, Key: plain}, nil
+}
+
+// Get returns one of the caller's keys.
+func (s *Service) Get(ctx context.Context, userID, id string) (*APIKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.Get")
+	defer span.End()
+
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOwner(ctx, userID, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Update renames a key or replaces its scopes. Organization keys can only be
+// changed by owners and managers, to scopes their team role allows.
+func (s *Service) Update(ctx context.Context, userID, id string, in UpdateInput) (*APIKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.Update")
+	defer span.End()
+
+	k, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if k.RevokedAt != nil {
+		return nil, ErrAlreadyRevoked
+	}
+	if in.Name != nil {
+		k.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Scopes != nil {
+		if k.Scopes, err = normalizeScopes(in.Scopes); err != nil {
+			return nil, err
+		}
+	}
+	if k.SupplierID != nil {
+		if err := s.checkManager(ctx, userID, k.Scopes); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Revoke disables a key immediately. Organization keys can be revoked by
+// their creator and by owners and managers.
+func (s *Service) Revoke(ctx context.Context, userID, id string) error {
+	ctx, span := tracing.Start(ctx, "apikey.Service.Revoke")
+	defer span.End()
+
+	k, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if k.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+	if k.SupplierID != nil && k.UserID != userID {
+		if err := s.checkManager(ctx, userID, nil); err != nil {
+			return err
+		}
+	}
+	return s.repo.Revoke(ctx, k.ID, time.Now().UTC())
+}
+
+// AuthenticateAPIKey resolves an X-API-Key header into the same claims an
+// access token carries, plus the key's ID and scopes. It implements
+// middleware.APIKeyAuthenticator. Organization keys stop working once their
+// creator leaves the organization or no longer has a team role that could
+// create them.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*middleware.Claims, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.AuthenticateAPIKey")
+	defer span.End()
+
+	rest, ok := strings.CutPrefix(key, keyPrefix)
+	if !ok {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+
+	k, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, middleware.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(k.KeyHash), []byte(hashKey(key))) != 1 || !k.Active(now) {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+	if k.SupplierID != nil {
+		supplierID, role, err := s.suppliers.SupplierMembership(ctx, k.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if supplierID != *k.SupplierID || !memberAllows(role, k.Scopes) {
+			return nil, middleware.ErrInvalidAPIKey
+		}
+	}
+
+	u, err := s.users.GetByID(ctx, k.ActingUserID)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, middleware.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	// Keys stop working while the account is pending deletion; signing in
+	// again cancels the deletion and brings them back.
+	if !u.Active() || u.DeletionScheduledAt != nil {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(ctx, k.ID, clientIP, now.UTC()); err != nil {
+			logging.FromContext(ctx).Warn("apikey: failed to record last use", "api_key_id", k.ID, "error", err)
+		}
+	}
+
+	return &middleware.Claims{
+		UserID:                 u.ID,
+		Role:                   string(u.Role),
+		TokenType:              middleware.TokenTypeAPIKey,
+		APIKeyID:               k.ID,
+		Scopes:                 k.Scopes,
+		EmailVerified:          u.EmailVerified(),
+		PasswordChangeRequired: u.MustChangePassword,
+	}, nil
+}
+
+// checkOwner hides keys that belong to neither the user nor their supplier
+// profile.
+func (s *Service) checkOwner(ctx context.Context, userID string, k *APIKey) error {
+	if k.UserID == userID {
+		return nil
+	}
+	if k.SupplierID != nil {
+		supplierID, _, err := s.suppliers.SupplierMembership(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if supplierID == *k.SupplierID {
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// checkManager refuses members of the caller's organization whose team role
+// cannot manage its keys or does not allow scopes.
+func (s *Service) checkManager(ctx context.Context, userID string, scopes []string) error {
+	_, role, err := s.suppliers.SupplierMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !memberAllows(role, scopes) {
+		return authz.ErrTeamRoleForbidden
+	}
+	return nil
+}
+
+// memberAllows reports whether team role may manage organization keys with
+// scopes.
+func memberAllows(role string, scopes []string) bool {
+	if !authz.MemberCan(role, authz.SupplierMembersManage) {
+		return false
+	}
+	for _, sc := range scopes {
+		if p, ok := supplierScopePermissions[sc]; ok && !authz.MemberCan(role, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeScopes validates scopes against Scopes and removes duplicates.
+func normalizeScopes(scopes []string) ([]string, error) {
+	allowed := make(map[string]bool, len(Scopes))
+	for _, sc := range Scopes {
+		allowed[sc] = true
+	}
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, sc := range scopes {
+		sc = strings.TrimSpace(sc)
+		if !allowed[sc] {
+			return nil, ErrInvalidScope.Detailf("%q", sc)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
+
+// generateKey returns a random lookup prefix (12 hex chars) and a 256-bit
+// secret.
+func generateKey() (prefix, secret string, err error) {
+	p := make([]byte, 6)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(p), base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,173 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+// memKeys keeps keys in memory, indexed by prefix.
+type memKeys struct {
+	Repository
+	byPrefix map[string]*APIKey
+}
+
+func (r *memKeys) Create(ctx context.Context, k *APIKey) error {
+	k.ID = "key-" + k.Prefix
+	r.byPrefix[k.Prefix] = k
+	return nil
+}
+
+func (r *memKeys) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	k, ok := r.byPrefix[prefix]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *k
+	return &c, nil
+}
+
+func (r *memKeys) TouchLastUsed(ctx context.Context, id, ip string, at time.Time) error { return nil }
+
+type memUsers struct {
+	auth.UserRepository
+	byID map[string]*auth.User
+}
+
+func (r *memUsers) GetByID(ctx context.Context, id string) (*auth.User, error) {
+	u, ok := r.byID[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	c := *u
+	return &c, nil
+}
+
+// memberships maps users to their organization and team role.
+type memberships map[string][2]string
+
+func (m memberships) SupplierMembership(ctx context.Context, userID string) (string, string, error) {
+	return m[userID][0], m[userID][1], nil
+}
+
+// newScopedRouter serves stub routes behind API key authentication.
+func newScopedRouter(svc *Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	api := router.Group("/api/v1", middleware.JWTAuth(nil, svc, nil))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/products/:id", ok)
+	api.POST("/products", ok)
+	api.GET("/orders", ok)
+	api.PATCH("/orders/:id/status", ok)
+	api.GET("/rfqs", ok)
+	api.POST("/messages", ok)
+	return router
+}
+
+func TestAPIKeyDeniedOutsideScope(t *testing.T) {
+	ctx := context.Background()
+	users := &memUsers{byID: map[string]*auth.User{"buyer-1": {ID: "buyer-1", Role: auth.RoleBuyer, Status: auth.StatusActive}}}
+	svc := NewService(&memKeys{byPrefix: map[string]*APIKey{}}, users, memberships{})
+	created, err := svc.Create(ctx, "buyer-1", CreateInput{Name: "erp", Scopes: []string{"products:read", "orders:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newScopedRouter(svc)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{method: http.MethodGet, path: "/api/v1/products/1", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/v1/orders", want: http.StatusOK},
+		{method: http.MethodPost, path: "/api/v1/products", want: http.StatusForbidden},
+		{method: http.MethodPatch, path: "/api/v1/orders/1/status", want: http.StatusForbidden},
+		{method: http.MethodGet, path: "/api/v1/rfqs", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/api/v1/messages", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-API-Key", created.Key)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusForbidden && !strings.Contains(rec.Body.String(), "missing_scope") {
+				t.Errorf("body %s, want missing_scope", rec.Body)
+			}
+		})
+	}
+}
+
+func TestAPIKeyDeniedAfterAccessChanges(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		owner string
+		// change alters the world after the key was created.
+		change func(k *APIKey, users *memUsers, members memberships)
+		want   int
+	}{
+		{name: "unchanged", change: func(*APIKey, *memUsers, memberships) {}, want: http.StatusOK},
+		{name: "revoked", change: func(k *APIKey, _ *memUsers, _ memberships) { k.RevokedAt = &past }, want: http.StatusUnauthorized},
+		{name: "expired", change: func(k *APIKey, _ *memUsers, _ memberships) { k.ExpiresAt = &past }, want: http.StatusUnauthorized},
+		{
+			name:   "account pending deletion",
+			change: func(_ *APIKey, users *memUsers, _ memberships) { users.byID["user-1"].DeletionScheduledAt = &past },
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:   "account suspended",
+			change: func(_ *APIKey, users *memUsers, _ memberships) { users.byID["user-1"].Status = auth.StatusSuspended },
+			want:   http.StatusUnauthorized,
+		},
+		{name: "organization key", owner: OwnerSupplier, change: func(*APIKey, *memUsers, memberships) {}, want: http.StatusOK},
+		{
+			name:   "creator demoted",
+			owner:  OwnerSupplier,
+			change: func(_ *APIKey, _ *memUsers, members memberships) { members["user-1"] = [2]string{"org-1", "sales"} },
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:   "creator left organization",
+			owner:  OwnerSupplier,
+			change: func(_ *APIKey, _ *memUsers, members memberships) { delete(members, "user-1") },
+			want:   http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := &memUsers{byID: map[string]*auth.User{"user-1": {ID: "user-1", Role: auth.RoleSupplier, Status: auth.StatusActive}}}
+			members := memberships{"user-1": {"org-1", "manager"}}
+			keys := &memKeys{byPrefix: map[string]*APIKey{}}
+			svc := NewService(keys, users, members)
+			created, err := svc.Create(ctx, "user-1", CreateInput{Name: "erp", Scopes: []string{"products:read"}, Owner: tt.owner})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.change(keys.byPrefix[created.Prefix], users, members)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+			req.Header.Set("X-API-Key", created.Key)
+			rec := httptest.NewRecorder()
+			newScopedRouter(svc).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+	"github.com/example/global-trade-hub/backend/internal/oidc"
 )
 
 // Handler exposes HTTP endpoints for auth / user operations.
@@ -19,98 +25,476 @@ func NewHandler(svc *Service) *Handler {
 	return &Handler{svc: svc}
 }
 
-// Register godoc
-// @Summary Register a new user
-// @Tags auth
-// @Accept json
-// @Produce json
-// @Param body body RegisterInput true "Registration payload"
-// @Success 201 {object} gin.H
-// @Failure 400,500 {object} gin.H
-// @Router /api/v1/auth/register [post]
+func newAuthResponse(user *User, tokens *TokenPair) AuthResponse {
+	return AuthResponse{User: user, Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+}
+
+// Register creates an account and signs it in.
 func (h *Handler) Register(c *gin.Context) {
 	var in RegisterInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	user, tokens, err := h.svc.Register(ctx, in)
+	user, tokens, err := h.svc.Register(ctx, in, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"user":         user,
-		"token":        tokens.AccessToken,
-		"refreshToken": tokens.RefreshToken,
-	})
+	c.JSON(http.StatusCreated, newAuthResponse(user, tokens))
 }
 
 // Login authenticates by email + password and returns a token pair.
 func (h *Handler) Login(c *gin.Context) {
 	var in LoginInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	user, tokens, err := h.svc.Login(ctx, in)
+	user, tokens, err := h.svc.Login(ctx, in, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		var mfaErr *MFARequiredError
+		if errors.As(err, &mfaErr) {
+			c.JSON(http.StatusOK, MFAChallenge{MFARequired: true, MFAToken: mfaErr.MFAToken, MFAExpiresAt: mfaErr.ExpiresAt})
+			return
+		}
+		if errors.Is(err, ErrUserNotFound) {
+			// Do not reveal which addresses are registered.
+			err = ErrInvalidCredentials
+		}
+		_ = c.Error(throttleError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"user":         user,
-		"token":        tokens.AccessToken,
-		"refreshToken": tokens.RefreshToken,
-	})
+	c.JSON(http.StatusOK, newAuthResponse(user, tokens))
+}
+
+// LoginMFA completes a two-step login with a TOTP or recovery code.
+func (h *Handler) LoginMFA(c *gin.Context) {
+	var in LoginMFAInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, tokens, err := h.svc.CompleteMFALogin(ctx, in, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) || errors.Is(err, ErrMFANotEnabled) {
+			err = apperr.WithStatus(err, http.StatusUnauthorized)
+		}
+		_ = c.Error(throttleError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(user, tokens))
+}
+
+// oidcStateCookie carries the signed OIDC flow state between the authorize
+// redirect and the provider callback.
+const oidcStateCookie = "oidc_state"
+
+// OIDCProviders lists the external identity providers for the login page.
+func (h *Handler) OIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"items": h.svc.OIDCProviders()})
+}
+
+// OIDCAuthorize redirects the browser to the identity provider.
+func (h *Handler) OIDCAuthorize(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	authURL, stateToken, err := h.svc.BeginOIDCLogin(ctx, c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, oidc.ErrUnknownProvider) {
+			_ = c.Error(apperr.NotFound("unknown_provider", err.Error()))
+			return
+		}
+		_ = c.Error(&apperr.Error{
+			Status:  http.StatusBadGateway,
+			Code:    "identity_provider_unavailable",
+			Message: "identity provider unavailable",
+			Err:     err,
+		})
+		return
+	}
+
+	// Lax so the cookie comes back on the provider's top-level redirect.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateToken, int(oidcStateTTL.Seconds()), "/api/v1/auth/oidc/", "", h.svc.oidc.SecureCookies(), true)
+	c.Redirect(http.StatusFound, authURL)
 }
 
-// RefreshToken parses a refresh token and issues a new access token.
+// OIDCCallback receives the provider redirect and sends the browser back to
+// the frontend with a one-time login code (or an error code).
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	stateToken, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/auth/oidc/", "", h.svc.oidc.SecureCookies(), true)
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.Redirect(http.StatusFound, h.svc.OIDCCallbackRedirect("", errCode))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	loginCode, err := h.svc.CompleteOIDCLogin(ctx, c.Param("provider"), c.Query("code"), c.Query("state"), stateToken)
+	if err != nil {
+		errCode := "server_error"
+		switch {
+		case errors.Is(err, ErrInvalidOIDCState):
+			errCode = "invalid_state"
+		case errors.Is(err, ErrOIDCEmailNotVerified):
+			errCode = "email_not_verified"
+		case errors.Is(err, ErrOIDCLinkNotAllowed):
+			errCode = "link_not_allowed"
+		case errors.Is(err, oidc.ErrUnknownProvider):
+			errCode = "unknown_provider"
+		case errors.Is(err, oidc.ErrInvalidIDToken):
+			errCode = "invalid_id_token"
+		}
+		logging.FromContext(ctx).Warn("auth: oidc callback failed", "provider", c.Param("provider"), "error", err)
+		c.Redirect(http.StatusFound, h.svc.OIDCCallbackRedirect("", errCode))
+		return
+	}
+
+	c.Redirect(http.StatusFound, h.svc.OIDCCallbackRedirect(loginCode, ""))
+}
+
+// OIDCExchange trades the one-time code from the callback for tokens.
+func (h *Handler) OIDCExchange(c *gin.Context) {
+	var in OIDCExchangeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, tokens, err := h.svc.ExchangeOIDCLoginCode(ctx, in.Code, clientInfo(c))
+	if err != nil {
+		var mfaErr *MFARequiredError
+		switch {
+		case errors.As(err, &mfaErr):
+			c.JSON(http.StatusOK, MFAChallenge{MFARequired: true, MFAToken: mfaErr.MFAToken, MFAExpiresAt: mfaErr.ExpiresAt})
+		case errors.Is(err, ErrInvalidActionToken):
+			_ = c.Error(apperr.WithStatus(err, http.StatusUnauthorized))
+		default:
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(user, tokens))
+}
+
+// RefreshToken rotates a refresh token and issues a new token pair.
 func (h *Handler) RefreshToken(c *gin.Context) {
-	var in struct {
-		RefreshToken string `json:"refreshToken" binding:"required"`
+	var in RefreshTokenInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, tokens, err := h.svc.RefreshToken(ctx, in.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			err = ErrInvalidRefreshToken
+		}
+		_ = c.Error(err)
+		return
 	}
+
+	c.JSON(http.StatusOK, newAuthResponse(user, tokens))
+}
+
+// Logout revokes the session the given refresh token belongs to.
+func (h *Handler) Logout(c *gin.Context) {
+	var in RefreshTokenInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.Logout(ctx, in.RefreshToken); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll revokes every refresh token of the authenticated user.
+func (h *Handler) LogoutAll(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.LogoutAll(ctx, claims.UserID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListSessions lists the devices the current user is signed in on (protected).
+func (h *Handler) ListSessions(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
+	claims := raw.(*middleware.Claims)
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	user, tokens, err := h.svc.RefreshToken(ctx, in.RefreshToken)
+	sessions, err := h.svc.ListSessions(ctx, claims.UserID, claims.SessionID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		_ = c.Error(err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"items": sessions})
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"user":         user,
-		"token":        tokens.AccessToken,
-		"refreshToken": tokens.RefreshToken,
-	})
+// RevokeSession signs the current user out of one of their sessions
+// (protected).
+func (h *Handler) RevokeSession(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.RevokeSession(ctx, claims.UserID, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserSessions lists another user's active sessions (admin only).
+func (h *Handler) ListUserSessions(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+	if !authz.Can(claims.Role, authz.UsersManage) {
+		_ = c.Error(authz.ErrAdminRequired)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	sessions, err := h.svc.ListUserSessions(ctx, c.Param("userId"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": sessions})
+}
+
+// RevokeUserSession signs another user out of one session (admin only).
+func (h *Handler) RevokeUserSession(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+	if !authz.Can(claims.Role, authz.UsersManage) {
+		_ = c.Error(authz.ErrAdminRequired)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.RevokeSession(ctx, c.Param("userId"), c.Param("sessionId")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeUserSessions signs another user out on all devices (admin only).
+func (h *Handler) RevokeUserSessions(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+	if !authz.Can(claims.Role, authz.UsersManage) {
+		_ = c.Error(authz.ErrAdminRequired)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.RevokeUserSessions(ctx, c.Param("userId")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail confirms an email address using the token from the
+// verification email.
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var in VerifyEmailInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := h.svc.VerifyEmail(ctx, in.Token)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ResendVerificationEmail sends a new verification link to the authenticated user.
+func (h *Handler) ResendVerificationEmail(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.svc.ResendVerificationEmail(ctx, claims.UserID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ForgotPassword sends a password reset link. It always answers 202 so the
+// endpoint cannot be used to discover registered addresses.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var in ForgotPasswordInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.svc.ForgotPassword(ctx, in.Email); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword sets a new password using the token from the reset email.
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var in ResetPasswordInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.ResetPassword(ctx, in); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ChangePassword changes the authenticated user's password. It is the only
+// endpoint (besides /me) available while a forced password change is pending.
+func (h *Handler) ChangePassword(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	var in ChangePasswordInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, tokens, err := h.svc.ChangePassword(ctx, claims.UserID, in, clientInfo(c))
+	if err != nil {
+		_ = c.Error(passwordError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(user, tokens))
 }
 
 // Me returns the current authenticated user info based on JWT claims.
 func (h *Handler) Me(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims, ok := raw.(*middleware.Claims)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 
@@ -120,9 +504,425 @@ func (h *Handler) Me(c *gin.Context) {
 	// Fetch full user from database
 	user, err := h.svc.GetUserByID(ctx, claims.UserID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		_ = c.Error(apperr.WithStatus(err, http.StatusUnauthorized))
+		return
+	}
+
+	mfa, err := h.svc.MFAStatus(ctx, user)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MeResponse{User: user, TwoFactor: mfa})
+}
+
+// UpdateProfile edits the authenticated user's name, phone, locale and
+// avatar.
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	var in UpdateProfileInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := h.svc.UpdateProfile(ctx, claims.UserID, in)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
+
+// BeginMFAEnrollment starts TOTP enrollment and returns the secret and
+// provisioning URI for the authenticator app.
+func (h *Handler) BeginMFAEnrollment(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	enrollment, err := h.svc.BeginMFAEnrollment(ctx, claims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// EnableMFA confirms TOTP enrollment and returns one-time recovery codes
+// together with a refreshed token pair.
+func (h *Handler) EnableMFA(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	var in MFACodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	codes, tokens, err := h.svc.EnableMFA(ctx, claims.UserID, claims.SessionID, in.Code, clientInfo(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recoveryCodes": codes,
+		"token":         tokens.AccessToken,
+		"refreshToken":  tokens.RefreshToken,
+	})
+}
+
+// DisableMFA turns two-factor authentication off.
+func (h *Handler) DisableMFA(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	var in DisableMFAInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.DisableMFA(ctx, claims.UserID, in); err != nil {
+		_ = c.Error(passwordError(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes.
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	var in MFACodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	codes, err := h.svc.RegenerateRecoveryCodes(ctx, claims.UserID, in.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// UnlockAccount clears the failed-login lockout of a user (admin only).
+func (h *Handler) UnlockAccount(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+	if !authz.Can(claims.Role, authz.UsersManage) {
+		_ = c.Error(authz.ErrAdminRequired)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.UnlockAccount(ctx, c.Param("userId")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation creates a staff account from an invitation link and signs
+// it in.
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	var in AcceptInvitationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, tokens, err := h.svc.AcceptInvitation(ctx, in, clientInfo(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newAuthResponse(user, tokens))
+}
+
+// InviteStaff emails an invitation for a new admin or staff account (admin
+// only).
+func (h *Handler) InviteStaff(c *gin.Context) {
+	var in InviteStaffInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	inv, err := h.svc.InviteStaff(ctx, claims.UserID, in)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+// ListInvitations lists pending staff invitations (admin only).
+func (h *Handler) ListInvitations(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	invitations, err := h.svc.ListInvitations(ctx)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": invitations})
+}
+
+// RevokeInvitation invalidates a pending staff invitation (admin only).
+func (h *Handler) RevokeInvitation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.RevokeInvitation(ctx, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListStaff lists admin and staff accounts (admin only).
+func (h *Handler) ListStaff(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	staff, err := h.svc.ListStaff(ctx)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": staff})
+}
+
+// UpdateStaffRole changes a staff member's role (admin only).
+func (h *Handler) UpdateStaffRole(c *gin.Context) {
+	var in UpdateStaffRoleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := h.svc.UpdateStaffRole(ctx, claims.UserID, c.Param("userId"), in.Role)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateStaffStatus suspends or reactivates a staff member (admin only).
+func (h *Handler) UpdateStaffStatus(c *gin.Context) {
+	var in UpdateStaffStatusInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := h.svc.UpdateStaffStatus(ctx, claims.UserID, c.Param("userId"), in.Status)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// throttleError reports a login throttle as a 429 with a Retry-After
+// header; other errors are returned unchanged.
+func throttleError(err error) error {
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return err
+	}
+	code := "login_throttled"
+	if throttled.Locked {
+		code = "account_locked"
+	}
+	return &apperr.Error{
+		Status:     http.StatusTooManyRequests,
+		Code:       code,
+		Message:    throttled.Error(),
+		RetryAfter: throttled.RetryAfter,
+		Err:        err,
+	}
+}
+
+// passwordError reports a wrong password confirming an action of a signed-in
+// user as ErrWrongPassword.
+func passwordError(err error) error {
+	if errors.Is(err, ErrInvalidCredentials) {
+		return ErrWrongPassword
+	}
+	return err
+}
+
+// clientInfo extracts the device details recorded alongside refresh tokens.
+func clientInfo(c *gin.Context) ClientInfo {
+	ua := c.Request.UserAgent()
+	if len(ua) > 512 {
+		ua = ua[:512]
+	}
+	return ClientInfo{
+		UserAgent: ua,
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// Impersonate issues a short-lived token to act as another user (admin
+// only). The request must give a reason, which is kept in the audit trail.
+func (h *Handler) Impersonate(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+	if claims.Impersonated() {
+		_ = c.Error(middleware.ErrImpersonationForbidden)
+		return
+	}
+
+	var in ImpersonateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	grant, err := h.svc.Impersonate(ctx, claims.UserID, c.Param("userId"), in, clientInfo(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, grant)
+}
+
+// ListImpersonations lists impersonations, newest first (admin only). The
+// userId query parameter limits them to one impersonated user.
+func (h *Handler) ListImpersonations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.svc.ListImpersonations(ctx, c.Query("userId"), limit, offset)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// ListImpersonatedRequests returns every request made during one
+// impersonation (admin only).
+func (h *Handler) ListImpersonatedRequests(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.svc.ImpersonatedRequests(ctx, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// EndImpersonation revokes an impersonation token before it expires (admin
+// only).
+func (h *Handler) EndImpersonation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.EndImpersonation(ctx, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
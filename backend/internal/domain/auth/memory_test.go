@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+)
+
+// newTestKeys returns a keyring signing with a single HMAC secret.
+func newTestKeys(t *testing.T) *jwtkeys.Keyring {
+	t.Helper()
+	keys, err := jwtkeys.New(&config.Config{
+		JWTSecret:   "auth-test-secret-at-least-32-characters",
+		JWTIssuer:   "global-trade-hub",
+		JWTAudience: "global-trade-hub-api",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+// memUsers keeps users in memory. Methods the tests do not use are left to
+// the embedded nil interface.
+type memUsers struct {
+	UserRepository
+	byID map[string]*User
+}
+
+// add stores an active user with a verified email and returns it.
+func (r *memUsers) add(role UserRole) *User {
+	now := time.Now().UTC()
+	u := &User{ID: uuid.NewString(), Email: uuid.NewString() + "@example.com", Role: role, Status: StatusActive, EmailVerifiedAt: &now}
+	c := *u
+	r.byID[u.ID] = &c
+	return u
+}
+
+func (r *memUsers) GetByEmail(ctx context.Context, email string) (*User, error) {
+	for _, u := range r.byID {
+		if u.Email == email {
+			c := *u
+			return &c, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *memUsers) GetByID(ctx context.Context, id string) (*User, error) {
+	u, ok := r.byID[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	c := *u
+	return &c, nil
+}
+
+func (r *memUsers) Create(ctx context.Context, u *User) error {
+	if _, err := r.GetByEmail(ctx, u.Email); err == nil {
+		return ErrEmailAlreadyUsed
+	}
+	u.ID = uuid.NewString()
+	u.Status = StatusActive
+	c := *u
+	r.byID[u.ID] = &c
+	return nil
+}
+
+func (r *memUsers) UpdatePassword(ctx context.Context, id, passwordHash string, mustChange bool) error {
+	r.byID[id].Password = passwordHash
+	r.byID[id].MustChangePassword = mustChange
+	return nil
+}
+
+func (r *memUsers) MarkEmailVerified(ctx context.Context, id string, at time.Time) error {
+	r.byID[id].EmailVerifiedAt = &at
+	return nil
+}
+
+// memRefreshTokens keeps refresh tokens in memory, rotating and revoking
+// them as the MySQL repository does. Sessions are left to the embedded nil
+// interface.
+type memRefreshTokens struct {
+	RefreshTokenRepository
+
+	mu           sync.Mutex
+	byID         map[string]*RefreshToken
+	revokedUsers []string
+}
+
+func newMemRefreshTokens() *memRefreshTokens {
+	return &memRefreshTokens{byID: map[string]*RefreshToken{}}
+}
+
+func (r *memRefreshTokens) Create(ctx context.Context, t *RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	t.CreatedAt = time.Now().UTC()
+	c := *t
+	r.byID[t.ID] = &c
+	return nil
+}
+
+func (r *memRefreshTokens) GetByID(ctx context.Context, id string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.byID[id]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *memRefreshTokens) MarkRotated(ctx context.Context, id, replacedBy string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.byID[id]
+	if !ok || t.RotatedAt != nil || t.RevokedAt != nil {
+		return ErrRefreshTokenAlreadyRotated
+	}
+	t.RotatedAt, t.ReplacedBy = &at, &replacedBy
+	return nil
+}
+
+func (r *memRefreshTokens) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.byID {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *memRefreshTokens) RevokeAllForUser(ctx context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedUsers = append(r.revokedUsers, userID)
+	for _, t := range r.byID {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+type memActionTokens struct {
+	ActionTokenRepository
+}
+
+func (r *memActionTokens) Create(ctx context.Context, t *ActionToken) error { return nil }
+
+func (r *memActionTokens) InvalidateForUser(ctx context.Context, userID string, purpose TokenPurpose, at time.Time) error {
+	return nil
+}
+
+// memMFA keeps TOTP enrollments in memory and advances the last used step as
+// the MySQL repository does. Recovery codes are left to the embedded nil
+// interface.
+type memMFA struct {
+	MFARepository
+	byUser map[string]*MFASettings
+}
+
+func (r *memMFA) Get(ctx context.Context, userID string) (*MFASettings, error) {
+	m, ok := r.byUser[userID]
+	if !ok {
+		return nil, ErrMFANotFound
+	}
+	c := *m
+	return &c, nil
+}
+
+func (r *memMFA) AdvanceStep(ctx context.Context, userID string, step int64) error {
+	m, ok := r.byUser[userID]
+	if !ok || m.LastUsedStep >= step {
+		return ErrMFACodeReplayed
+	}
+	m.LastUsedStep = step
+	return nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/totp"
+)
+
+func TestVerifyTOTPRejectsExpiredAndReplayedCodes(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := totp.Step(time.Now())
+
+	tests := []struct {
+		name string
+		// lastUsed is the step accepted before; offset picks the code's step
+		// relative to now.
+		lastUsed int64
+		offset   int64
+		code     string
+		want     error
+	}{
+		{name: "current code", offset: 0},
+		{name: "previous step within skew", offset: -1},
+		{name: "expired code", offset: -totpSkew - 1, want: ErrInvalidMFACode},
+		{name: "future code beyond skew", offset: totpSkew + 1, want: ErrInvalidMFACode},
+		{name: "replayed code", lastUsed: now, offset: 0, want: ErrInvalidMFACode},
+		{name: "older code after newer one", lastUsed: now, offset: -1, want: ErrInvalidMFACode},
+		{name: "malformed code", code: "12345x", want: ErrInvalidMFACode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			settings := &MFASettings{UserID: "user-1", TOTPSecret: secret, LastUsedStep: tt.lastUsed}
+			mfa := &memMFA{byUser: map[string]*MFASettings{"user-1": settings}}
+			svc := NewService(&memUsers{byID: map[string]*User{}}, newMemRefreshTokens(), &memActionTokens{}, mfa, newTestKeys(t))
+
+			code := tt.code
+			if code == "" {
+				if code, err = totp.CodeAt(secret, now+tt.offset); err != nil {
+					t.Fatal(err)
+				}
+			}
+			current, _ := mfa.Get(ctx, "user-1")
+			if err := svc.verifyTOTP(ctx, current, code); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if settings.LastUsedStep != tt.lastUsed {
+					t.Errorf("rejected code advanced last used step to %d", settings.LastUsedStep)
+				}
+				return
+			}
+			if settings.LastUsedStep != now+tt.offset {
+				t.Errorf("last used step %d, want %d", settings.LastUsedStep, now+tt.offset)
+			}
+			again, _ := mfa.Get(ctx, "user-1")
+			if err := svc.verifyTOTP(ctx, again, code); !errors.Is(err, ErrInvalidMFACode) {
+				t.Errorf("same code twice: %v, want %v", err, ErrInvalidMFACode)
+			}
+		})
+	}
+}
+
+// TestVerifyTOTPConcurrentReplay covers a code accepted by another request
+// after this one loaded the settings: the repository refuses the step.
+func TestVerifyTOTPConcurrentReplay(t *testing.T) {
+	ctx := context.Background()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := totp.Step(time.Now())
+	code, err := totp.CodeAt(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfa := &memMFA{byUser: map[string]*MFASettings{"user-1": {UserID: "user-1", TOTPSecret: secret}}}
+	svc := NewService(&memUsers{byID: map[string]*User{}}, newMemRefreshTokens(), &memActionTokens{}, mfa, newTestKeys(t))
+
+	stale, _ := mfa.Get(ctx, "user-1")
+	if err := mfa.AdvanceStep(ctx, "user-1", step); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.verifyTOTP(ctx, stale, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidMFACode)
+	}
+}
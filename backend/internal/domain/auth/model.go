@@ -2,7 +2,8 @@ package auth
 
 import "time"
 
-// UserRole matches frontend roles: buyer, supplier, market, visitor, admin.
+// UserRole matches frontend roles: buyer, supplier, market, visitor, admin,
+// plus the staff sub-roles below.
 type UserRole string
 
 const (
@@ -11,25 +12,84 @@ const (
 	RoleMarket   UserRole = "market"
 	RoleVisitor  UserRole = "visitor"
 	RoleAdmin    UserRole = "admin"
+
+	// Staff sub-roles, each limited to one area of the admin panel.
+	RoleModerator   UserRole = "moderator"
+	RoleKYCReviewer UserRole = "kyc_reviewer"
+	RoleFinance     UserRole = "finance"
+)
+
+// staffRoles can only be obtained through an invitation (see InviteStaff).
+var staffRoles = map[UserRole]bool{
+	RoleAdmin:       true,
+	RoleModerator:   true,
+	RoleKYCReviewer: true,
+	RoleFinance:     true,
+}
+
+// IsStaff reports whether r is an admin or staff role.
+func (r UserRole) IsStaff() bool {
+	return staffRoles[r]
+}
+
+// UserStatus is the account state set by admins. Only active accounts can
+// sign in or use their tokens.
+type UserStatus string
+
+const (
+	StatusActive    UserStatus = "active"
+	StatusInactive  UserStatus = "inactive"
+	StatusSuspended UserStatus = "suspended"
+	// StatusDeleted marks an account that was anonymized after its owner
+	// deleted it.
+	StatusDeleted UserStatus = "deleted"
 )
 
 // User represents an application user persisted in MySQL.
 type User struct {
-	ID        string    `db:"id" json:"id"`
-	Email     string    `db:"email" json:"email"`
-	Password  string    `db:"password_hash" json:"-"` // bcrypt hash; column in DB is password_hash (001_init_schema)
-	Role      UserRole  `db:"role" json:"role"`
-	FullName  string    `db:"full_name" json:"fullName"`
-	CreatedAt time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+	ID        string     `db:"id" json:"id"`
+	Email     string     `db:"email" json:"email"`
+	Password  string     `db:"password_hash" json:"-"` // bcrypt hash; column in DB is password_hash (001_init_schema)
+	Role      UserRole   `db:"role" json:"role"`
+	FullName  string     `db:"full_name" json:"fullName"`
+	Phone     string     `db:"phone" json:"phone"`
+	Locale    string     `db:"locale" json:"locale"`
+	AvatarURL string     `db:"avatar_url" json:"avatarUrl"`
+	Status    UserStatus `db:"status" json:"status"`
+	// MustChangePassword blocks the account from everything except changing
+	// its password (set for operator-created accounts such as the bootstrap admin).
+	MustChangePassword bool       `db:"must_change_password" json:"mustChangePassword"`
+	EmailVerifiedAt    *time.Time `db:"email_verified_at" json:"emailVerifiedAt"`
+	// DeletionScheduledAt is when a requested account deletion will be
+	// carried out; nil if none is pending.
+	DeletionScheduledAt *time.Time `db:"deletion_scheduled_at" json:"deletionScheduledAt,omitempty"`
+	CreatedAt           time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt           time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// EmailVerified reports whether the user has confirmed their email address.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Suspended reports whether an admin has suspended the account.
+func (u *User) Suspended() bool {
+	return u.Status == StatusSuspended
 }
 
-// RegisterInput is the payload for registration.
+// Active reports whether the account may sign in and use its tokens: it is
+// neither suspended, deactivated nor deleted.
+func (u *User) Active() bool {
+	return u.Status == StatusActive
+}
+
+// RegisterInput is the payload for public registration. Admin and staff
+// accounts are created through invitations instead.
 type RegisterInput struct {
 	Email    string   `json:"email" binding:"required,email"`
 	Password string   `json:"password" binding:"required,min=8"`
 	FullName string   `json:"fullName" binding:"required"`
-	Role     UserRole `json:"role" binding:"required,oneof=buyer supplier market visitor admin"`
+	Role     UserRole `json:"role" binding:"required,oneof=buyer supplier market visitor"`
 }
 
 // LoginInput is the payload for password-based login.
@@ -38,9 +98,345 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// ChangePasswordInput is the payload for changing the current user's password.
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+// UpdateProfileInput is the payload for editing the current user's profile.
+// Omitted fields are left unchanged.
+type UpdateProfileInput struct {
+	FullName  *string `json:"fullName" binding:"omitempty,min=1,max=255"`
+	Phone     *string `json:"phone" binding:"omitempty,max=50"`
+	Locale    *string `json:"locale" binding:"omitempty,bcp47_language_tag"`
+	AvatarURL *string `json:"avatarUrl" binding:"omitempty,url,max=2048"`
+}
+
+// DeleteAccountInput confirms an account deletion request with the password.
+type DeleteAccountInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// VerifyEmailInput is the payload for confirming an email address.
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordInput is the payload for requesting a password reset email.
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordInput is the payload for setting a new password from a reset link.
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8"`
+}
+
+// BootstrapAdminInput describes the first admin account created by cmd/admin.
+type BootstrapAdminInput struct {
+	Email    string
+	Password string
+	FullName string
+}
+
+// StaffInvitation is a pending offer of an admin or staff account, sent by
+// email as a signed link. Only a SHA-256 hash of the signed token is stored.
+type StaffInvitation struct {
+	ID         string     `db:"id" json:"id"`
+	Email      string     `db:"email" json:"email"`
+	Role       UserRole   `db:"role" json:"role"`
+	InvitedBy  string     `db:"invited_by" json:"invitedBy"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expiresAt"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"acceptedAt,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// Pending reports whether the invitation can still be accepted at t.
+func (i *StaffInvitation) Pending(t time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && t.Before(i.ExpiresAt)
+}
+
+// InviteStaffInput is the payload an admin sends to invite a staff member.
+type InviteStaffInput struct {
+	Email string   `json:"email" binding:"required,email"`
+	Role  UserRole `json:"role" binding:"required,oneof=admin moderator kyc_reviewer finance"`
+}
+
+// AcceptInvitationInput is the payload for creating an account from an
+// invitation link.
+type AcceptInvitationInput struct {
+	Token    string `json:"token" binding:"required"`
+	FullName string `json:"fullName" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// UpdateStaffRoleInput changes a staff member's role.
+type UpdateStaffRoleInput struct {
+	Role UserRole `json:"role" binding:"required,oneof=admin moderator kyc_reviewer finance"`
+}
+
+// UpdateStaffStatusInput suspends or reactivates a staff member.
+type UpdateStaffStatusInput struct {
+	Status UserStatus `json:"status" binding:"required,oneof=active suspended"`
+}
+
 // TokenPair contains access and refresh tokens.
 type TokenPair struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
 }
 
+// RefreshToken is the server-side record of an issued refresh token. Only a
+// SHA-256 hash of the signed token is stored. Tokens issued from the same
+// login share a FamilyID, which also serves as the session ID.
+type RefreshToken struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"userId"`
+	FamilyID   string     `db:"family_id" json:"familyId"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	UserAgent  string     `db:"user_agent" json:"userAgent"`
+	IPAddress  string     `db:"ip_address" json:"ipAddress"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expiresAt"`
+	RotatedAt  *time.Time `db:"rotated_at" json:"rotatedAt,omitempty"`
+	ReplacedBy *string    `db:"replaced_by" json:"replacedBy,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// ClientInfo describes the device a token pair is issued to.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is one login of a user on a device: a refresh token family and the
+// access tokens issued from it. ID equals the refresh tokens' FamilyID and
+// the access tokens' "sid" claim. UserAgent and IPAddress are those of the
+// most recent token refresh.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"userId"`
+	Device     string     `db:"-" json:"device"`
+	UserAgent  string     `db:"user_agent" json:"userAgent"`
+	IPAddress  string     `db:"ip_address" json:"ipAddress"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"lastSeenAt"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expiresAt"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	// Current marks the session of the token making the request.
+	Current bool `db:"-" json:"current"`
+}
+
+// Active reports whether the session can still be used at t.
+func (s *Session) Active(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
+
+// RefreshTokenInput is the payload for refresh and logout.
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// TokenPurpose identifies what a single-use action token may be used for.
+// The value doubles as the token's "typ" claim.
+type TokenPurpose string
+
+const (
+	PurposeVerifyEmail   TokenPurpose = "verify_email"
+	PurposePasswordReset TokenPurpose = "password_reset"
+	// PurposeOIDCLogin is the one-time code handed to the frontend after an
+	// OIDC callback, exchanged for tokens at /auth/oidc/exchange.
+	PurposeOIDCLogin TokenPurpose = "oidc_login"
+	// PurposeStaffInvite marks invitation tokens. They are tracked in
+	// staff_invitations rather than user_action_tokens, as there is no user yet.
+	PurposeStaffInvite TokenPurpose = "staff_invite"
+)
+
+// ActionToken is the server-side record of an emailed single-use token.
+type ActionToken struct {
+	ID        string       `db:"id" json:"id"`
+	UserID    string       `db:"user_id" json:"userId"`
+	Purpose   TokenPurpose `db:"purpose" json:"purpose"`
+	TokenHash string       `db:"token_hash" json:"-"`
+	ExpiresAt time.Time    `db:"expires_at" json:"expiresAt"`
+	UsedAt    *time.Time   `db:"used_at" json:"usedAt,omitempty"`
+	CreatedAt time.Time    `db:"created_at" json:"createdAt"`
+}
+
+// MFASettings holds a user's TOTP enrollment. 2FA is active once EnabledAt is
+// set; LastUsedStep is the last accepted TOTP time step (replay protection).
+type MFASettings struct {
+	UserID       string     `db:"user_id" json:"userId"`
+	TOTPSecret   string     `db:"totp_secret" json:"-"`
+	EnabledAt    *time.Time `db:"enabled_at" json:"enabledAt,omitempty"`
+	LastUsedStep int64      `db:"last_used_step" json:"-"`
+	CreatedAt    time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// Enabled reports whether enrollment was confirmed with a valid code.
+func (m *MFASettings) Enabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// MFAStatus is the 2FA summary exposed on /me.
+type MFAStatus struct {
+	Enabled bool `json:"enabled"`
+	// Required is true when the user's role must use 2FA by policy.
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// MFAEnrollment is returned when TOTP enrollment starts. OTPAuthURL is the
+// provisioning URI the client renders as a QR code.
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauthUrl"`
+}
+
+// MFACodeInput carries a TOTP code from the authenticator app.
+type MFACodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableMFAInput is the payload for turning 2FA off.
+type DisableMFAInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// LoginMFAInput is the second login step: the pending token from /auth/login
+// plus either a TOTP code or an unused recovery code.
+type LoginMFAInput struct {
+	MFAToken     string `json:"mfaToken" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recoveryCode" binding:"required_without=Code"`
+}
+
+// MeResponse is the /me payload: the user plus their 2FA status.
+type MeResponse struct {
+	*User
+	TwoFactor *MFAStatus `json:"twoFactor"`
+}
+
+// AuthResponse is returned by the endpoints that sign a user in: the user
+// plus a new token pair.
+type AuthResponse struct {
+	User         *User  `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// MFAChallenge is returned by a login that needs a second factor; the client
+// completes it at /auth/login/2fa with MFAToken.
+type MFAChallenge struct {
+	MFARequired  bool      `json:"mfaRequired"`
+	MFAToken     string    `json:"mfaToken"`
+	MFAExpiresAt time.Time `json:"mfaExpiresAt"`
+}
+
+// ExternalIdentity links a user to an account at an OIDC identity provider.
+type ExternalIdentity struct {
+	ID          string     `db:"id" json:"id"`
+	UserID      string     `db:"user_id" json:"userId"`
+	Provider    string     `db:"provider" json:"provider"`
+	Subject     string     `db:"subject" json:"-"`
+	Email       string     `db:"email" json:"email"`
+	LastLoginAt *time.Time `db:"last_login_at" json:"lastLoginAt,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// OIDCProvider is an identity provider offered on the login page.
+type OIDCProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// OIDCExchangeInput carries the one-time code from the OIDC callback redirect.
+type OIDCExchangeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// LoginThrottlePolicy configures brute-force protection for logins. Failures
+// are counted in a sliding Window per account and per client IP. After
+// FreeAttempts failures on an account, each further attempt must wait an
+// exponentially growing delay (BaseDelay, 2×BaseDelay, … up to MaxDelay).
+// Reaching MaxAccountFailures or MaxIPFailures locks the account or IP for
+// LockoutDuration.
+type LoginThrottlePolicy struct {
+	Window             time.Duration
+	FreeAttempts       int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockoutDuration    time.Duration
+}
+
+// DefaultLoginThrottlePolicy returns the policy used unless configured otherwise.
+func DefaultLoginThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		Window:             15 * time.Minute,
+		FreeAttempts:       3,
+		BaseDelay:          time.Second,
+		MaxDelay:           30 * time.Second,
+		MaxAccountFailures: 10,
+		MaxIPFailures:      100,
+		LockoutDuration:    15 * time.Minute,
+	}
+}
+
+// Impersonation is an admin's short-lived "login as user" session. Requests
+// made with its token are recorded as ImpersonatedRequest entries.
+type Impersonation struct {
+	ID           string     `db:"id" json:"id"`
+	ActorID      string     `db:"actor_id" json:"actorId"`
+	ActorEmail   string     `db:"-" json:"actorEmail"`
+	SubjectID    string     `db:"subject_id" json:"subjectId"`
+	SubjectEmail string     `db:"-" json:"subjectEmail"`
+	Reason       string     `db:"reason" json:"reason"`
+	IPAddress    string     `db:"ip_address" json:"ipAddress,omitempty"`
+	UserAgent    string     `db:"user_agent" json:"userAgent,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"createdAt"`
+	ExpiresAt    time.Time  `db:"expires_at" json:"expiresAt"`
+	EndedAt      *time.Time `db:"ended_at" json:"endedAt,omitempty"`
+	RequestCount int        `db:"-" json:"requestCount"`
+}
+
+// Active reports whether the impersonation token is still accepted at t.
+func (i *Impersonation) Active(t time.Time) bool {
+	return i.EndedAt == nil && t.Before(i.ExpiresAt)
+}
+
+// ImpersonatedRequest is one audited request made under impersonation.
+type ImpersonatedRequest struct {
+	ID              int64     `db:"id" json:"id"`
+	ImpersonationID string    `db:"impersonation_id" json:"impersonationId"`
+	ActorID         string    `db:"actor_id" json:"actorId"`
+	SubjectID       string    `db:"subject_id" json:"subjectId"`
+	Method          string    `db:"method" json:"method"`
+	Route           string    `db:"route" json:"route"`
+	Path            string    `db:"path" json:"path"`
+	Status          int       `db:"status" json:"status"`
+	IPAddress       string    `db:"ip_address" json:"ipAddress,omitempty"`
+	UserAgent       string    `db:"user_agent" json:"userAgent,omitempty"`
+	CreatedAt       time.Time `db:"created_at" json:"createdAt"`
+}
+
+// ImpersonateInput is the payload an admin sends to act as another user. The
+// reason is kept in the audit trail.
+type ImpersonateInput struct {
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// ImpersonationGrant is the access token for an impersonation. There is no
+// refresh token; a new impersonation must be started once it expires.
+type ImpersonationGrant struct {
+	Impersonation *Impersonation `json:"impersonation"`
+	User          *User          `json:"user"`
+	Token         string         `json:"token"`
+}
@@ -0,0 +1,411 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/oidc"
+)
+
+const (
+	testClientID     = "global-trade-hub"
+	testClientSecret = "idp-secret"
+)
+
+// testIdP is an OpenID provider serving discovery, JWKS and a token endpoint
+// that enforces PKCE. Authorization is simulated by authorize, which plays
+// the part of the user signing in at the provider.
+type testIdP struct {
+	t    *testing.T
+	srv  *httptest.Server
+	keys *jwtkeys.Keyring
+
+	mu        sync.Mutex
+	grants    map[string]idpGrant // by authorization code
+	exchanges int                 // successful code exchanges
+}
+
+type idpGrant struct {
+	challenge string
+	nonce     string
+	user      idpUser
+}
+
+// idpUser is the account signing in at the provider.
+type idpUser struct {
+	subject       string
+	email         string
+	emailVerified bool
+	name          string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	p := &testIdP{t: t, grants: map[string]idpGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, p.keys.JWKS())
+	})
+	mux.HandleFunc("POST /token", p.token)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+
+	dir := t.TempDir()
+	if _, err := jwtkeys.GenerateKeyFile(dir, jwtkeys.AlgRS256); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwtkeys.New(&config.Config{JWTIssuer: p.srv.URL, JWTAudience: testClientID, JWTKeysDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.keys = keys
+	return p
+}
+
+func (p *testIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.srv.URL,
+		"authorization_endpoint":                p.srv.URL + "/authorize",
+		"token_endpoint":                        p.srv.URL + "/token",
+		"jwks_uri":                              p.srv.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{jwtkeys.AlgRS256},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize checks the authorization request built by BeginOIDCLogin and
+// returns the code and state the provider redirects back with.
+func (p *testIdP) authorize(authURL string, user idpUser) (code, state string) {
+	p.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	q := u.Query()
+	if got := u.Scheme + "://" + u.Host + u.Path; got != p.srv.URL+"/authorize" {
+		p.t.Fatalf("authorization endpoint = %s, want %s/authorize", got, p.srv.URL)
+	}
+	if q.Get("client_id") != testClientID || q.Get("response_type") != "code" {
+		p.t.Fatalf("unexpected authorization request %s", u.RawQuery)
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		p.t.Fatalf("authorization request without an S256 PKCE challenge: %s", u.RawQuery)
+	}
+	if q.Get("state") == "" || q.Get("nonce") == "" {
+		p.t.Fatalf("authorization request without state or nonce: %s", u.RawQuery)
+	}
+
+	code = uuid.NewString()
+	p.mu.Lock()
+	p.grants[code] = idpGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), user: user}
+	p.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (p *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != testClientID || secret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	grant, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken, err := p.keys.Sign(jwt.MapClaims{
+		"iss":            p.srv.URL,
+		"aud":            testClientID,
+		"sub":            grant.user.subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.user.email,
+		"email_verified": grant.user.emailVerified,
+		"name":           grant.user.name,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	p.mu.Lock()
+	p.exchanges++
+	p.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": uuid.NewString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (p *testIdP) exchangeCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exchanges
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type memIdentities struct {
+	links      []*ExternalIdentity
+	usedStates map[string]bool
+}
+
+func (r *memIdentities) GetByProviderSubject(ctx context.Context, provider, subject string) (*ExternalIdentity, error) {
+	for _, l := range r.links {
+		if l.Provider == provider && l.Subject == subject {
+			return l, nil
+		}
+	}
+	return nil, ErrIdentityNotFound
+}
+
+func (r *memIdentities) Create(ctx context.Context, i *ExternalIdentity) error {
+	i.ID = uuid.NewString()
+	r.links = append(r.links, i)
+	return nil
+}
+
+func (r *memIdentities) TouchLogin(ctx context.Context, id string, at time.Time) error { return nil }
+
+func (r *memIdentities) ConsumeState(ctx context.Context, stateID string, expiresAt time.Time) error {
+	if r.usedStates[stateID] {
+		return ErrOIDCStateUsed
+	}
+	r.usedStates[stateID] = true
+	return nil
+}
+
+type oidcFixture struct {
+	svc        *Service
+	idp        *testIdP
+	users      *memUsers
+	tokens     *memRefreshTokens
+	identities *memIdentities
+}
+
+// newOIDCFixture wires a Service to a test IdP registered as provider "test",
+// whose accounts default to the supplier role.
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+	idp := newTestIdP(t)
+
+	keys := newTestKeys(t)
+	registry, err := oidc.New(&config.Config{
+		APIBaseURL:      "http://api.example.test",
+		OIDCDefaultRole: string(RoleBuyer),
+		OIDCProviders: []config.OIDCProviderConfig{{
+			Name:         "test",
+			Issuer:       idp.srv.URL,
+			ClientID:     testClientID,
+			ClientSecret: testClientSecret,
+			DefaultRole:  string(RoleSupplier),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &oidcFixture{
+		idp:        idp,
+		users:      &memUsers{byID: map[string]*User{}},
+		tokens:     newMemRefreshTokens(),
+		identities: &memIdentities{usedStates: map[string]bool{}},
+	}
+	f.svc = NewService(f.users, f.tokens, &memActionTokens{}, nil, keys)
+	f.svc.WithOIDC(registry, f.identities)
+	return f
+}
+
+// login runs a complete sign-in of user and returns the ID of the local user
+// the one-time login code was issued for.
+func (f *oidcFixture) login(t *testing.T, user idpUser) (string, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, stateToken, err := f.svc.BeginOIDCLogin(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := f.idp.authorize(authURL, user)
+	loginCode, err := f.svc.CompleteOIDCLogin(ctx, "test", code, state, stateToken)
+	if err != nil {
+		return "", err
+	}
+	return f.loginCodeUser(t, loginCode), nil
+}
+
+func (f *oidcFixture) loginCodeUser(t *testing.T, loginCode string) string {
+	t.Helper()
+	claims := &middleware.Claims{}
+	if err := f.svc.keys.Parse(loginCode, claims); err != nil {
+		t.Fatalf("parse login code: %v", err)
+	}
+	if claims.TokenType != string(PurposeOIDCLogin) {
+		t.Fatalf("login code type = %q, want %q", claims.TokenType, PurposeOIDCLogin)
+	}
+	return claims.UserID
+}
+
+func TestCompleteOIDCLoginExchangesCodeWithPKCE(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	userID, err := f.login(t, idpUser{subject: "sub-1", email: "ana@example.com", emailVerified: true})
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin: %v", err)
+	}
+	if userID == "" {
+		t.Fatal("login code has no user")
+	}
+	if n := f.idp.exchangeCount(); n != 1 {
+		t.Fatalf("code exchanges = %d, want 1", n)
+	}
+}
+
+func TestCompleteOIDCLoginRejectsReplayedState(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	authURL, stateToken, err := f.svc.BeginOIDCLogin(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := f.idp.authorize(authURL, idpUser{subject: "sub-1", email: "ana@example.com", emailVerified: true})
+	if _, err := f.svc.CompleteOIDCLogin(ctx, "test", code, state, stateToken); err != nil {
+		t.Fatalf("first callback: %v", err)
+	}
+
+	_, err = f.svc.CompleteOIDCLogin(ctx, "test", code, state, stateToken)
+	if !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("replayed callback: err = %v, want ErrInvalidOIDCState", err)
+	}
+	if n := f.idp.exchangeCount(); n != 1 {
+		t.Fatalf("code exchanges = %d, want the replay to stop before the provider", n)
+	}
+
+	// A state that does not belong to the flow cookie is refused as well.
+	authURL, stateToken, err = f.svc.BeginOIDCLogin(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ = f.idp.authorize(authURL, idpUser{subject: "sub-1", email: "ana@example.com", emailVerified: true})
+	if _, err := f.svc.CompleteOIDCLogin(ctx, "test", code, state, stateToken); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("mismatched state: err = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestCompleteOIDCLoginRequiresVerifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	_, err := f.login(t, idpUser{subject: "sub-1", email: "ana@example.com", emailVerified: false})
+	if !errors.Is(err, ErrOIDCEmailNotVerified) {
+		t.Fatalf("err = %v, want ErrOIDCEmailNotVerified", err)
+	}
+	if len(f.users.byID) != 0 || len(f.identities.links) != 0 {
+		t.Fatalf("unverified email created %d users and %d identity links", len(f.users.byID), len(f.identities.links))
+	}
+}
+
+func TestCompleteOIDCLoginLinksExistingVerifiedAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	verifiedAt := time.Now().Add(-time.Hour)
+	existing := &User{
+		ID:              "user-1",
+		Email:           "ana@example.com",
+		Password:        "existing-hash",
+		Role:            RoleBuyer,
+		Status:          StatusActive,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	f.users.byID[existing.ID] = existing
+
+	userID, err := f.login(t, idpUser{subject: "sub-1", email: "Ana@Example.com", emailVerified: true})
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin: %v", err)
+	}
+	if userID != existing.ID {
+		t.Fatalf("signed in as %q, want the existing account %q", userID, existing.ID)
+	}
+	if len(f.users.byID) != 1 {
+		t.Fatalf("users = %d, want no new account", len(f.users.byID))
+	}
+	if got := f.users.byID[existing.ID]; got.Password != "existing-hash" || got.Role != RoleBuyer {
+		t.Fatalf("existing account changed: role %q, password replaced %v", got.Role, got.Password != "existing-hash")
+	}
+	if len(f.tokens.revokedUsers) != 0 {
+		t.Fatalf("sessions of a verified account were revoked: %v", f.tokens.revokedUsers)
+	}
+	if len(f.identities.links) != 1 || f.identities.links[0].UserID != existing.ID || f.identities.links[0].Subject != "sub-1" {
+		t.Fatalf("identity links = %+v, want sub-1 linked to %s", f.identities.links, existing.ID)
+	}
+}
+
+func TestCompleteOIDCLoginCreatesUserWithDefaultRole(t *testing.T) {
+	f := newOIDCFixture(t)
+	user := idpUser{subject: "sub-1", email: "new@example.com", emailVerified: true, name: "New Supplier"}
+
+	userID, err := f.login(t, user)
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin: %v", err)
+	}
+	u, ok := f.users.byID[userID]
+	if !ok {
+		t.Fatalf("no account created for %q", userID)
+	}
+	if u.Role != RoleSupplier {
+		t.Fatalf("role = %q, want the provider's default %q", u.Role, RoleSupplier)
+	}
+	if u.Email != "new@example.com" || u.FullName != "New Supplier" || !u.EmailVerified() {
+		t.Fatalf("created user = %+v", u)
+	}
+
+	// The next sign-in finds the account through the linked identity.
+	again, err := f.login(t, user)
+	if err != nil {
+		t.Fatalf("second sign-in: %v", err)
+	}
+	if again != userID || len(f.users.byID) != 1 || len(f.identities.links) != 1 {
+		t.Fatalf("second sign-in as %q with %d users and %d links, want %q, 1 and 1",
+			again, len(f.users.byID), len(f.identities.links), userID)
+	}
+}
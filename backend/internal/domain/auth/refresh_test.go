@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	tests := []struct {
+		name string
+		// rotations is how many times the session is refreshed; reuse is
+		// the rotated token then presented again, 0 being the one issued at
+		// sign-in.
+		rotations int
+		reuse     int
+	}{
+		{name: "first token reused", rotations: 1, reuse: 0},
+		{name: "older token reused", rotations: 3, reuse: 0},
+		{name: "previous token reused", rotations: 3, reuse: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := &memUsers{byID: map[string]*User{}}
+			tokens := newMemRefreshTokens()
+			svc := NewService(users, tokens, &memActionTokens{}, nil, newTestKeys(t))
+			u := users.add(RoleBuyer)
+
+			first, err := svc.issueTokens(ctx, u, "", ClientInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := svc.issueTokens(ctx, u, "", ClientInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			issued := []string{first.RefreshToken}
+			for i := 0; i < tt.rotations; i++ {
+				_, pair, err := svc.RefreshToken(ctx, issued[i], ClientInfo{})
+				if err != nil {
+					t.Fatalf("rotation %d: %v", i+1, err)
+				}
+				issued = append(issued, pair.RefreshToken)
+			}
+			latest := issued[len(issued)-1]
+
+			if _, _, err := svc.RefreshToken(ctx, issued[tt.reuse], ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+				t.Fatalf("reused token: %v, want %v", err, ErrRefreshTokenReused)
+			}
+			if _, _, err := svc.RefreshToken(ctx, latest, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("latest token of the family after reuse: %v, want %v", err, ErrInvalidRefreshToken)
+			}
+			if _, _, err := svc.RefreshToken(ctx, other.RefreshToken, ClientInfo{}); err != nil {
+				t.Errorf("token of another session: %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenRejectsRevokedAndForeignTokens(t *testing.T) {
+	ctx := context.Background()
+	users := &memUsers{byID: map[string]*User{}}
+	tokens := newMemRefreshTokens()
+	svc := NewService(users, tokens, &memActionTokens{}, nil, newTestKeys(t))
+	u := users.add(RoleBuyer)
+
+	signedOut, err := svc.issueTokens(ctx, u, "", ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Logout(ctx, signedOut.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	active, err := svc.issueTokens(ctx, u, "", ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "signed out", token: signedOut.RefreshToken},
+		{name: "access token", token: active.AccessToken},
+		{name: "tampered", token: active.RefreshToken + "x"},
+		{name: "garbage", token: "not-a-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := svc.RefreshToken(ctx, tt.token, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("got %v, want %v", err, ErrInvalidRefreshToken)
+			}
+		})
+	}
+}
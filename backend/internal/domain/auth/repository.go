@@ -4,16 +4,48 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 )
 
 var (
 	// ErrUserNotFound is returned when user cannot be located.
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = apperr.NotFound("user_not_found", "user not found")
 	// ErrEmailAlreadyUsed is returned on unique email constraint violation.
-	ErrEmailAlreadyUsed = errors.New("email already in use")
+	ErrEmailAlreadyUsed = apperr.Conflict("email_already_used", "email already in use")
+	// ErrActionTokenNotFound is returned when no action token record exists.
+	ErrActionTokenNotFound = errors.New("action token not found")
+	// ErrActionTokenUsed is returned when an action token was already consumed.
+	ErrActionTokenUsed = errors.New("action token already used")
+	// ErrMFANotFound is returned when the user has not started 2FA enrollment.
+	ErrMFANotFound = errors.New("mfa settings not found")
+	// ErrMFACodeReplayed is returned when a TOTP step was already used.
+	ErrMFACodeReplayed = errors.New("mfa code already used")
+	// ErrRecoveryCodeNotFound is returned when no unused recovery code matches.
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+	// ErrIdentityNotFound is returned when no external identity link exists.
+	ErrIdentityNotFound = errors.New("external identity not found")
+	// ErrOIDCStateUsed is returned when an OIDC state token is redeemed twice.
+	ErrOIDCStateUsed = errors.New("oidc state already used")
+	// ErrRefreshTokenNotFound is returned when no refresh token record exists.
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenAlreadyRotated is returned when a refresh token has
+	// already been exchanged (or revoked) and cannot be rotated again.
+	ErrRefreshTokenAlreadyRotated = errors.New("refresh token already rotated")
+	// ErrSessionNotFound is returned when no session record exists.
+	ErrSessionNotFound = apperr.NotFound("session_not_found", "session not found")
+	// ErrInvitationNotFound is returned when no staff invitation exists.
+	ErrInvitationNotFound = apperr.NotFound("invitation_not_found", "invitation not found")
+	// ErrInvitationNotPending is returned when an invitation was already
+	// accepted or revoked.
+	ErrInvitationNotPending = apperr.Conflict("invitation_not_pending", "invitation already accepted or revoked")
+	// ErrImpersonationNotFound is returned when an impersonation does not exist.
+	ErrImpersonationNotFound = apperr.NotFound("impersonation_not_found", "impersonation not found")
 )
 
 // UserRepository defines persistence operations for users.
@@ -21,6 +53,21 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id string) (*User, error)
 	Create(ctx context.Context, u *User) error
+	UpdatePassword(ctx context.Context, id, passwordHash string, mustChange bool) error
+	CountByRole(ctx context.Context, role UserRole) (int, error)
+	// MarkEmailVerified sets users.email_verified_at and mirrors the flag onto
+	// the KYC verifications of suppliers owned by the user.
+	MarkEmailVerified(ctx context.Context, id string, at time.Time) error
+	// ListByRoles returns the users with any of roles, oldest first.
+	ListByRoles(ctx context.Context, roles []UserRole) ([]User, error)
+	// CountActiveByRole counts users with role whose status is active.
+	CountActiveByRole(ctx context.Context, role UserRole) (int, error)
+	UpdateRole(ctx context.Context, id string, role UserRole) error
+	UpdateStatus(ctx context.Context, id string, status UserStatus) error
+	// UpdateProfile saves the self-service profile fields of u.
+	UpdateProfile(ctx context.Context, u *User) error
+	// ScheduleDeletion sets or, with a nil at, clears a pending deletion.
+	ScheduleDeletion(ctx context.Context, id string, at *time.Time) error
 }
 
 type mySQLUserRepository struct {
@@ -32,19 +79,25 @@ func NewMySQLUserRepository(db *sql.DB) UserRepository {
 	return &mySQLUserRepository{db: db}
 }
 
-func (r *mySQLUserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
-	const query = `
-SELECT id, email, password_hash, role, full_name, created_at, updated_at
-FROM users
-WHERE email = ? LIMIT 1`
+const userColumns = `id, email, password_hash, role, full_name, COALESCE(phone, ''), COALESCE(locale, ''),
+       COALESCE(avatar_url, ''), COALESCE(status, 'active'), must_change_password, email_verified_at,
+       deletion_scheduled_at, created_at, updated_at`
 
+func scanUser(row rowScanner) (*User, error) {
 	var u User
-	if err := r.db.QueryRowContext(ctx, query, email).Scan(
+	if err := row.Scan(
 		&u.ID,
 		&u.Email,
 		&u.Password,
 		&u.Role,
 		&u.FullName,
+		&u.Phone,
+		&u.Locale,
+		&u.AvatarURL,
+		&u.Status,
+		&u.MustChangePassword,
+		&u.EmailVerifiedAt,
+		&u.DeletionScheduledAt,
 		&u.CreatedAt,
 		&u.UpdatedAt,
 	); err != nil {
@@ -56,41 +109,28 @@ WHERE email = ? LIMIT 1`
 	return &u, nil
 }
 
-func (r *mySQLUserRepository) GetByID(ctx context.Context, id string) (*User, error) {
-	const query = `
-SELECT id, email, password_hash, role, full_name, created_at, updated_at
-FROM users
-WHERE id = ? LIMIT 1`
+func (r *mySQLUserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = ? LIMIT 1`, email))
+}
 
-	var u User
-	if err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&u.ID,
-		&u.Email,
-		&u.Password,
-		&u.Role,
-		&u.FullName,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrUserNotFound
-		}
-		return nil, err
-	}
-	return &u, nil
+func (r *mySQLUserRepository) GetByID(ctx context.Context, id string) (*User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ? LIMIT 1`, id))
 }
 
 func (r *mySQLUserRepository) Create(ctx context.Context, u *User) error {
 	if u.ID == "" {
 		u.ID = uuid.NewString()
 	}
+	if u.Status == "" {
+		u.Status = StatusActive
+	}
 	now := time.Now().UTC()
 	u.CreatedAt = now
 	u.UpdatedAt = now
 
 	const query = `
-INSERT INTO users (id, email, password_hash, role, full_name, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?)`
+INSERT INTO users (id, email, password_hash, role, full_name, status, must_change_password, email_verified_at, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		u.ID,
@@ -98,11 +138,1257 @@ VALUES (?, ?, ?, ?, ?, ?, ?)`
 		u.Password,
 		u.Role,
 		u.FullName,
+		u.Status,
+		u.MustChangePassword,
+		u.EmailVerifiedAt,
 		u.CreatedAt,
 		u.UpdatedAt,
 	)
-	// NOTE: for a production system you should inspect MySQL error codes to
-	// detect duplicate email (e.g. using driver-specific error types).
+	// email is the only unique column besides the generated ID.
+	if apperr.IsDuplicateKey(err) {
+		return ErrEmailAlreadyUsed
+	}
+	return err
+}
+
+func (r *mySQLUserRepository) UpdatePassword(ctx context.Context, id, passwordHash string, mustChange bool) error {
+	const query = `
+UPDATE users
+SET password_hash = ?, must_change_password = ?, updated_at = ?
+WHERE id = ?`
+
+	res, err := r.db.ExecContext(ctx, query, passwordHash, mustChange, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *mySQLUserRepository) CountByRole(ctx context.Context, role UserRole) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role = ?`, role).Scan(&n)
+	return n, err
+}
+
+func (r *mySQLUserRepository) MarkEmailVerified(ctx context.Context, id string, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, ?), updated_at = ? WHERE id = ?`,
+		at, at, id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	const syncQuery = `
+UPDATE verifications v
+JOIN suppliers s ON s.id = v.supplier_id
+SET v.email_verified = TRUE, v.email_verified_at = COALESCE(v.email_verified_at, ?)
+WHERE s.user_id = ?`
+	if _, err := tx.ExecContext(ctx, syncQuery, at, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mySQLUserRepository) ListByRoles(ctx context.Context, roles []UserRole) ([]User, error) {
+	if len(roles) == 0 {
+		return []User{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(roles)), ",")
+	args := make([]any, len(roles))
+	for i, role := range roles {
+		args[i] = role
+	}
+
+	query := `
+SELECT ` + userColumns + `
+FROM users
+WHERE role IN (` + placeholders + `)
+ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+func (r *mySQLUserRepository) CountActiveByRole(ctx context.Context, role UserRole) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM users WHERE role = ? AND COALESCE(status, 'active') = ?`,
+		role, StatusActive,
+	).Scan(&n)
+	return n, err
+}
+
+func (r *mySQLUserRepository) UpdateRole(ctx context.Context, id string, role UserRole) error {
+	return r.updateColumn(ctx, "role", id, role)
+}
+
+func (r *mySQLUserRepository) UpdateStatus(ctx context.Context, id string, status UserStatus) error {
+	return r.updateColumn(ctx, "status", id, status)
+}
+
+func (r *mySQLUserRepository) UpdateProfile(ctx context.Context, u *User) error {
+	u.UpdatedAt = time.Now().UTC()
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users SET full_name = ?, phone = ?, locale = ?, avatar_url = ?, updated_at = ? WHERE id = ?`,
+		u.FullName, u.Phone, u.Locale, u.AvatarURL, u.UpdatedAt, u.ID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *mySQLUserRepository) ScheduleDeletion(ctx context.Context, id string, at *time.Time) error {
+	return r.updateColumn(ctx, "deletion_scheduled_at", id, at)
+}
+
+// updateColumn sets one column of a user; column is never user input.
+func (r *mySQLUserRepository) updateColumn(ctx context.Context, column, id string, value any) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users SET `+column+` = ?, updated_at = ? WHERE id = ?`,
+		value, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// RefreshTokenRepository persists issued refresh tokens for rotation and
+// revocation, together with the session (token family) they belong to.
+type RefreshTokenRepository interface {
+	// Create stores t and creates or refreshes its session record.
+	Create(ctx context.Context, t *RefreshToken) error
+	GetByID(ctx context.Context, id string) (*RefreshToken, error)
+	// MarkRotated atomically marks an active token as exchanged for
+	// replacedBy. It returns ErrRefreshTokenAlreadyRotated if the token was
+	// rotated or revoked concurrently.
+	MarkRotated(ctx context.Context, id, replacedBy string, at time.Time) error
+	// RevokeFamily revokes a session and all its refresh tokens.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// RevokeAllForUser revokes every session and refresh token of a user.
+	RevokeAllForUser(ctx context.Context, userID string, at time.Time) error
+
+	GetSession(ctx context.Context, id string) (*Session, error)
+	// ListActiveSessions returns the user's unrevoked, unexpired sessions,
+	// most recently seen first.
+	ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]Session, error)
+	TouchSession(ctx context.Context, id string, at time.Time) error
+}
+
+type mySQLRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLRefreshTokenRepository returns a MySQL-backed implementation.
+func NewMySQLRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &mySQLRefreshTokenRepository{db: db}
+}
+
+func (r *mySQLRefreshTokenRepository) Create(ctx context.Context, t *RefreshToken) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	t.CreatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const query = `
+INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, user_agent, ip_address, expires_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := tx.ExecContext(ctx, query,
+		t.ID,
+		t.UserID,
+		t.FamilyID,
+		t.TokenHash,
+		t.UserAgent,
+		t.IPAddress,
+		t.ExpiresAt,
+		t.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	// A refresh keeps the session's creation time (and any revocation) and
+	// records the device's latest address.
+	const sessionQuery = `
+INSERT INTO auth_sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    user_agent = VALUES(user_agent),
+    ip_address = VALUES(ip_address),
+    last_seen_at = VALUES(last_seen_at),
+    expires_at = VALUES(expires_at)`
+
+	if _, err := tx.ExecContext(ctx, sessionQuery,
+		t.FamilyID,
+		t.UserID,
+		t.UserAgent,
+		t.IPAddress,
+		t.CreatedAt,
+		t.CreatedAt,
+		t.ExpiresAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mySQLRefreshTokenRepository) GetByID(ctx context.Context, id string) (*RefreshToken, error) {
+	const query = `
+SELECT id, user_id, family_id, token_hash, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+       expires_at, rotated_at, replaced_by, revoked_at, created_at
+FROM refresh_tokens
+WHERE id = ? LIMIT 1`
+
+	var t RefreshToken
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.UserAgent,
+		&t.IPAddress,
+		&t.ExpiresAt,
+		&t.RotatedAt,
+		&t.ReplacedBy,
+		&t.RevokedAt,
+		&t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *mySQLRefreshTokenRepository) MarkRotated(ctx context.Context, id, replacedBy string, at time.Time) error {
+	const query = `
+UPDATE refresh_tokens
+SET rotated_at = ?, replaced_by = ?
+WHERE id = ? AND rotated_at IS NULL AND revoked_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, query, at, replacedBy, id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRefreshTokenAlreadyRotated
+	}
+	return nil
+}
+
+func (r *mySQLRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return r.revoke(ctx, "family_id", "id", familyID, at)
+}
+
+func (r *mySQLRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string, at time.Time) error {
+	return r.revoke(ctx, "user_id", "user_id", userID, at)
+}
+
+// revoke marks matching refresh tokens and sessions as revoked in one
+// transaction. The column names are constants chosen by the callers above.
+func (r *mySQLRefreshTokenRepository) revoke(ctx context.Context, tokenColumn, sessionColumn, value string, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tokenQuery := `UPDATE refresh_tokens SET revoked_at = ? WHERE ` + tokenColumn + ` = ? AND revoked_at IS NULL`
+	if _, err := tx.ExecContext(ctx, tokenQuery, at, value); err != nil {
+		return err
+	}
+	sessionQuery := `UPDATE auth_sessions SET revoked_at = ? WHERE ` + sessionColumn + ` = ? AND revoked_at IS NULL`
+	if _, err := tx.ExecContext(ctx, sessionQuery, at, value); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const sessionColumns = `id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+       created_at, last_seen_at, expires_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (*Session, error) {
+	var s Session
+	if err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.UserAgent,
+		&s.IPAddress,
+		&s.CreatedAt,
+		&s.LastSeenAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *mySQLRefreshTokenRepository) GetSession(ctx context.Context, id string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM auth_sessions WHERE id = ? LIMIT 1`
+
+	s, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *mySQLRefreshTokenRepository) ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + `
+FROM auth_sessions
+WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+ORDER BY last_seen_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *mySQLRefreshTokenRepository) TouchSession(ctx context.Context, id string, at time.Time) error {
+	const query = `UPDATE auth_sessions SET last_seen_at = ? WHERE id = ? AND last_seen_at < ?`
+	_, err := r.db.ExecContext(ctx, query, at, id, at)
+	return err
+}
+
+// ActionTokenRepository persists single-use email verification and password
+// reset tokens.
+type ActionTokenRepository interface {
+	Create(ctx context.Context, t *ActionToken) error
+	GetByID(ctx context.Context, id string) (*ActionToken, error)
+	// MarkUsed atomically consumes an unused token. It returns
+	// ErrActionTokenUsed if the token was already consumed.
+	MarkUsed(ctx context.Context, id string, at time.Time) error
+	// InvalidateForUser consumes every outstanding token of the given purpose,
+	// so that only the most recently issued one stays valid.
+	InvalidateForUser(ctx context.Context, userID string, purpose TokenPurpose, at time.Time) error
+}
+
+type mySQLActionTokenRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLActionTokenRepository returns a MySQL-backed implementation.
+func NewMySQLActionTokenRepository(db *sql.DB) ActionTokenRepository {
+	return &mySQLActionTokenRepository{db: db}
+}
+
+func (r *mySQLActionTokenRepository) Create(ctx context.Context, t *ActionToken) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	t.CreatedAt = time.Now().UTC()
+
+	const query = `
+INSERT INTO user_action_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		t.ID,
+		t.UserID,
+		t.Purpose,
+		t.TokenHash,
+		t.ExpiresAt,
+		t.CreatedAt,
+	)
+	return err
+}
+
+func (r *mySQLActionTokenRepository) GetByID(ctx context.Context, id string) (*ActionToken, error) {
+	const query = `
+SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at
+FROM user_action_tokens
+WHERE id = ? LIMIT 1`
+
+	var t ActionToken
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Purpose,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrActionTokenNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *mySQLActionTokenRepository) MarkUsed(ctx context.Context, id string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_action_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`,
+		at, id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrActionTokenUsed
+	}
+	return nil
+}
+
+func (r *mySQLActionTokenRepository) InvalidateForUser(ctx context.Context, userID string, purpose TokenPurpose, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE user_action_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL`,
+		at, userID, purpose,
+	)
+	return err
+}
+
+// MFARepository persists TOTP enrollments and recovery codes.
+type MFARepository interface {
+	Get(ctx context.Context, userID string) (*MFASettings, error)
+	// Upsert stores a new, not yet enabled, secret for the user.
+	Upsert(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string, at time.Time) error
+	// Delete removes the enrollment and all recovery codes.
+	Delete(ctx context.Context, userID string) error
+	// AdvanceStep records step as used. It returns ErrMFACodeReplayed if the
+	// step is not newer than the last accepted one.
+	AdvanceStep(ctx context.Context, userID string, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// UseRecoveryCode consumes an unused recovery code. It returns
+	// ErrRecoveryCodeNotFound if none matches.
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, at time.Time) error
+	CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error)
+}
+
+type mySQLMFARepository struct {
+	db *sql.DB
+}
+
+// NewMySQLMFARepository returns a MySQL-backed implementation.
+func NewMySQLMFARepository(db *sql.DB) MFARepository {
+	return &mySQLMFARepository{db: db}
+}
+
+func (r *mySQLMFARepository) Get(ctx context.Context, userID string) (*MFASettings, error) {
+	const query = `
+SELECT user_id, totp_secret, enabled_at, last_used_step, created_at, updated_at
+FROM user_mfa
+WHERE user_id = ? LIMIT 1`
+
+	var m MFASettings
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&m.UserID,
+		&m.TOTPSecret,
+		&m.EnabledAt,
+		&m.LastUsedStep,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFANotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mySQLMFARepository) Upsert(ctx context.Context, userID, secret string) error {
+	now := time.Now().UTC()
+	const query = `
+INSERT INTO user_mfa (user_id, totp_secret, enabled_at, last_used_step, created_at, updated_at)
+VALUES (?, ?, NULL, 0, ?, ?)
+ON DUPLICATE KEY UPDATE totp_secret = VALUES(totp_secret), enabled_at = NULL, last_used_step = 0, updated_at = VALUES(updated_at)`
+
+	_, err := r.db.ExecContext(ctx, query, userID, secret, now, now)
+	return err
+}
+
+func (r *mySQLMFARepository) Enable(ctx context.Context, userID string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_mfa SET enabled_at = ?, updated_at = ? WHERE user_id = ?`,
+		at, at, userID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMFANotFound
+	}
+	return nil
+}
+
+func (r *mySQLMFARepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *mySQLMFARepository) AdvanceStep(ctx context.Context, userID string, step int64) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_mfa SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`,
+		step, userID, step,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMFACodeReplayed
+	}
+	return nil
+}
+
+func (r *mySQLMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at) VALUES (?, ?, ?, ?)`,
+			uuid.NewString(), userID, h, now,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *mySQLMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL LIMIT 1`,
+		at, userID, codeHash,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (r *mySQLMFARepository) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`,
+		userID,
+	).Scan(&n)
+	return n, err
+}
+
+// LoginAttemptStore records failed logins and lockouts per key (an account or
+// a client IP). Implementations must be safe for concurrent use.
+type LoginAttemptStore interface {
+	RecordFailure(ctx context.Context, key string, at time.Time) error
+	// Failures returns the number of failures for key since the given time and
+	// the time of the most recent one.
+	Failures(ctx context.Context, key string, since time.Time) (count int, last time.Time, err error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil returns the end of the current lockout, or the zero time.
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Reset clears failures and any lockout for key.
+	Reset(ctx context.Context, key string) error
+}
+
+// memoryLoginAttemptStore keeps attempts in process memory. Suitable for a
+// single API instance; counters are lost on restart.
+type memoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	retention time.Duration
+	failures  map[string][]time.Time
+	locks     map[string]time.Time
+	writes    int
+}
+
+// NewMemoryLoginAttemptStore returns an in-memory store that forgets failures
+// older than retention.
+func NewMemoryLoginAttemptStore(retention time.Duration) LoginAttemptStore {
+	return &memoryLoginAttemptStore{
+		retention: retention,
+		failures:  make(map[string][]time.Time),
+		locks:     make(map[string]time.Time),
+	}
+}
+
+func (m *memoryLoginAttemptStore) RecordFailure(_ context.Context, key string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures[key] = append(prune(m.failures[key], at.Add(-m.retention)), at)
+
+	// Sweep the whole map now and then so keys that never come back (e.g.
+	// random emails) do not accumulate.
+	m.writes++
+	if m.writes%1000 == 0 {
+		cutoff := at.Add(-m.retention)
+		for k, ts := range m.failures {
+			if ts = prune(ts, cutoff); len(ts) == 0 {
+				delete(m.failures, k)
+			} else {
+				m.failures[k] = ts
+			}
+		}
+		for k, until := range m.locks {
+			if !until.After(at) {
+				delete(m.locks, k)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryLoginAttemptStore) Failures(_ context.Context, key string, since time.Time) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var last time.Time
+	count := 0
+	for _, t := range m.failures[key] {
+		if t.After(since) {
+			count++
+			if t.After(last) {
+				last = t
+			}
+		}
+	}
+	return count, last, nil
+}
+
+func (m *memoryLoginAttemptStore) Lock(_ context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locks[key] = until
+	return nil
+}
+
+func (m *memoryLoginAttemptStore) LockedUntil(_ context.Context, key string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.locks[key], nil
+}
+
+func (m *memoryLoginAttemptStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failures, key)
+	delete(m.locks, key)
+	return nil
+}
+
+// prune drops timestamps at or before cutoff. ts is sorted ascending.
+func prune(ts []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(ts) && !ts[i].After(cutoff) {
+		i++
+	}
+	return ts[i:]
+}
+
+// mySQLLoginAttemptStore shares counters between API instances through the
+// login_attempts and login_lockouts tables.
+type mySQLLoginAttemptStore struct {
+	db        *sql.DB
+	retention time.Duration
+}
+
+// NewMySQLLoginAttemptStore returns a MySQL-backed store that deletes
+// failures older than retention as new ones are recorded.
+func NewMySQLLoginAttemptStore(db *sql.DB, retention time.Duration) LoginAttemptStore {
+	return &mySQLLoginAttemptStore{db: db, retention: retention}
+}
+
+func (r *mySQLLoginAttemptStore) RecordFailure(ctx context.Context, key string, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO login_attempts (attempt_key, attempted_at) VALUES (?, ?)`,
+		key, at.UTC(),
+	); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM login_attempts WHERE attempt_key = ? AND attempted_at < ?`,
+		key, at.Add(-r.retention).UTC(),
+	)
+	return err
+}
+
+func (r *mySQLLoginAttemptStore) Failures(ctx context.Context, key string, since time.Time) (int, time.Time, error) {
+	var (
+		count int
+		last  sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), MAX(attempted_at) FROM login_attempts WHERE attempt_key = ? AND attempted_at > ?`,
+		key, since.UTC(),
+	).Scan(&count, &last)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, last.Time, nil
+}
+
+func (r *mySQLLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO login_lockouts (attempt_key, locked_until) VALUES (?, ?)
+ON DUPLICATE KEY UPDATE locked_until = VALUES(locked_until)`,
+		key, until.UTC(),
+	)
+	return err
+}
+
+func (r *mySQLLoginAttemptStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var until time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT locked_until FROM login_lockouts WHERE attempt_key = ? LIMIT 1`,
+		key,
+	).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return until, err
+}
+
+func (r *mySQLLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE attempt_key = ?`, key); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_lockouts WHERE attempt_key = ?`, key)
+	return err
+}
+
+// IdentityRepository persists links to external (OIDC) identities.
+type IdentityRepository interface {
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*ExternalIdentity, error)
+	Create(ctx context.Context, id *ExternalIdentity) error
+	TouchLogin(ctx context.Context, id string, at time.Time) error
+	// ConsumeState records the OIDC state token stateID as redeemed until
+	// expiresAt. It returns ErrOIDCStateUsed if it already was.
+	ConsumeState(ctx context.Context, stateID string, expiresAt time.Time) error
+}
+
+type mySQLIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLIdentityRepository returns a MySQL-backed implementation.
+func NewMySQLIdentityRepository(db *sql.DB) IdentityRepository {
+	return &mySQLIdentityRepository{db: db}
+}
+
+func (r *mySQLIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*ExternalIdentity, error) {
+	const query = `
+SELECT id, user_id, provider, subject, email, last_login_at, created_at
+FROM user_identities
+WHERE provider = ? AND subject = ? LIMIT 1`
+
+	var i ExternalIdentity
+	if err := r.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.Email,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *mySQLIdentityRepository) Create(ctx context.Context, i *ExternalIdentity) error {
+	if i.ID == "" {
+		i.ID = uuid.NewString()
+	}
+	i.CreatedAt = time.Now().UTC()
+
+	const query = `
+INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		i.ID,
+		i.UserID,
+		i.Provider,
+		i.Subject,
+		i.Email,
+		i.LastLoginAt,
+		i.CreatedAt,
+	)
+	return err
+}
+
+func (r *mySQLIdentityRepository) TouchLogin(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_identities SET last_login_at = ? WHERE id = ?`, at, id)
+	return err
+}
+
+func (r *mySQLIdentityRepository) ConsumeState(ctx context.Context, stateID string, expiresAt time.Time) error {
+	// Expired states can no longer be presented; drop them on the way.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_used_states WHERE expires_at < ?`, time.Now().UTC()); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO oidc_used_states (id, expires_at) VALUES (?, ?)`, stateID, expiresAt.UTC())
+	if apperr.IsDuplicateKey(err) {
+		return ErrOIDCStateUsed
+	}
+	return err
+}
+
+// InvitationRepository persists staff invitations.
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *StaffInvitation) error
+	GetByID(ctx context.Context, id string) (*StaffInvitation, error)
+	// ListPending returns invitations that are neither accepted, revoked nor
+	// expired at now, newest first.
+	ListPending(ctx context.Context, now time.Time) ([]StaffInvitation, error)
+	// RevokePendingForEmail revokes every pending invitation for email.
+	RevokePendingForEmail(ctx context.Context, email string, at time.Time) error
+	// MarkAccepted and Revoke return ErrInvitationNotPending if the
+	// invitation was already accepted or revoked.
+	MarkAccepted(ctx context.Context, id string, at time.Time) error
+	Revoke(ctx context.Context, id string, at time.Time) error
+}
+
+type mySQLInvitationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLInvitationRepository returns a MySQL-backed implementation.
+func NewMySQLInvitationRepository(db *sql.DB) InvitationRepository {
+	return &mySQLInvitationRepository{db: db}
+}
+
+const invitationColumns = `id, email, role, invited_by, token_hash, expires_at, accepted_at, revoked_at, created_at`
+
+func scanInvitation(row rowScanner) (*StaffInvitation, error) {
+	var i StaffInvitation
+	if err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Role,
+		&i.InvitedBy,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *mySQLInvitationRepository) Create(ctx context.Context, inv *StaffInvitation) error {
+	if inv.ID == "" {
+		inv.ID = uuid.NewString()
+	}
+	inv.CreatedAt = time.Now().UTC()
+
+	const query = `
+INSERT INTO staff_invitations (` + invitationColumns + `)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		inv.ID,
+		inv.Email,
+		inv.Role,
+		inv.InvitedBy,
+		inv.TokenHash,
+		inv.ExpiresAt,
+		inv.AcceptedAt,
+		inv.RevokedAt,
+		inv.CreatedAt,
+	)
+	return err
+}
+
+func (r *mySQLInvitationRepository) GetByID(ctx context.Context, id string) (*StaffInvitation, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+invitationColumns+` FROM staff_invitations WHERE id = ? LIMIT 1`, id)
+	inv, err := scanInvitation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (r *mySQLInvitationRepository) ListPending(ctx context.Context, now time.Time) ([]StaffInvitation, error) {
+	const query = `
+SELECT ` + invitationColumns + `
+FROM staff_invitations
+WHERE accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?
+ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []StaffInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *inv)
+	}
+	return out, rows.Err()
+}
+
+func (r *mySQLInvitationRepository) RevokePendingForEmail(ctx context.Context, email string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE staff_invitations SET revoked_at = ? WHERE email = ? AND accepted_at IS NULL AND revoked_at IS NULL`,
+		at, email,
+	)
+	return err
+}
+
+func (r *mySQLInvitationRepository) MarkAccepted(ctx context.Context, id string, at time.Time) error {
+	return r.close(ctx, "accepted_at", id, at)
+}
+
+func (r *mySQLInvitationRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.close(ctx, "revoked_at", id, at)
+}
+
+// close sets column (accepted_at or revoked_at) on a still pending invitation.
+func (r *mySQLInvitationRepository) close(ctx context.Context, column, id string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE staff_invitations SET `+column+` = ? WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL`,
+		at, id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrInvitationNotPending
+	}
+	return nil
+}
+
+// ImpersonationRepository persists admin impersonations and their audit
+// trail.
+type ImpersonationRepository interface {
+	Create(ctx context.Context, imp *Impersonation) error
+	Get(ctx context.Context, id string) (*Impersonation, error)
+	// List returns impersonations newest first, optionally only those of
+	// subjectID, with actor and subject emails and request counts.
+	List(ctx context.Context, subjectID string, limit, offset int) ([]Impersonation, error)
+	// End marks an active impersonation as ended; ended ones are left as is.
+	End(ctx context.Context, id string, at time.Time) error
+	RecordRequest(ctx context.Context, req *ImpersonatedRequest) error
+	ListRequests(ctx context.Context, impersonationID string) ([]ImpersonatedRequest, error)
+}
+
+type mySQLImpersonationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLImpersonationRepository returns a MySQL-backed implementation.
+func NewMySQLImpersonationRepository(db *sql.DB) ImpersonationRepository {
+	return &mySQLImpersonationRepository{db: db}
+}
+
+const impersonationColumns = `i.id, i.actor_id, i.subject_id, i.reason, COALESCE(i.ip_address, ''), COALESCE(i.user_agent, ''),
+       i.created_at, i.expires_at, i.ended_at`
+
+func scanImpersonation(row rowScanner, extra ...any) (*Impersonation, error) {
+	var i Impersonation
+	dest := append([]any{
+		&i.ID,
+		&i.ActorID,
+		&i.SubjectID,
+		&i.Reason,
+		&i.IPAddress,
+		&i.UserAgent,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.EndedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *mySQLImpersonationRepository) Create(ctx context.Context, imp *Impersonation) error {
+	if imp.ID == "" {
+		imp.ID = uuid.NewString()
+	}
+	imp.CreatedAt = time.Now().UTC()
+
+	const query = `
+INSERT INTO impersonation_sessions (id, actor_id, subject_id, reason, ip_address, user_agent, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		imp.ID,
+		imp.ActorID,
+		imp.SubjectID,
+		imp.Reason,
+		imp.IPAddress,
+		imp.UserAgent,
+		imp.CreatedAt,
+		imp.ExpiresAt,
+	)
+	return err
+}
+
+func (r *mySQLImpersonationRepository) Get(ctx context.Context, id string) (*Impersonation, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+impersonationColumns+` FROM impersonation_sessions i WHERE i.id = ? LIMIT 1`, id)
+	imp, err := scanImpersonation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrImpersonationNotFound
+		}
+		return nil, err
+	}
+	return imp, nil
+}
+
+func (r *mySQLImpersonationRepository) List(ctx context.Context, subjectID string, limit, offset int) ([]Impersonation, error) {
+	query := `
+SELECT ` + impersonationColumns + `, COALESCE(a.email, ''), COALESCE(s.email, ''),
+       (SELECT COUNT(*) FROM impersonation_requests q WHERE q.impersonation_id = i.id)
+FROM impersonation_sessions i
+LEFT JOIN users a ON a.id = i.actor_id
+LEFT JOIN users s ON s.id = i.subject_id`
+	args := []any{}
+	if subjectID != "" {
+		query += ` WHERE i.subject_id = ?`
+		args = append(args, subjectID)
+	}
+	query += ` ORDER BY i.created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Impersonation{}
+	for rows.Next() {
+		var actorEmail, subjectEmail string
+		var count int
+		imp, err := scanImpersonation(rows, &actorEmail, &subjectEmail, &count)
+		if err != nil {
+			return nil, err
+		}
+		imp.ActorEmail, imp.SubjectEmail, imp.RequestCount = actorEmail, subjectEmail, count
+		out = append(out, *imp)
+	}
+	return out, rows.Err()
+}
+
+func (r *mySQLImpersonationRepository) End(ctx context.Context, id string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE impersonation_sessions SET ended_at = ? WHERE id = ? AND ended_at IS NULL`,
+		at, id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		_, err := r.Get(ctx, id)
+		return err
+	}
+	return nil
+}
+
+func (r *mySQLImpersonationRepository) RecordRequest(ctx context.Context, req *ImpersonatedRequest) error {
+	const query = `
+INSERT INTO impersonation_requests
+    (impersonation_id, actor_id, subject_id, method, route, path, status, ip_address, user_agent, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.ExecContext(ctx, query,
+		req.ImpersonationID,
+		req.ActorID,
+		req.SubjectID,
+		req.Method,
+		req.Route,
+		req.Path,
+		req.Status,
+		req.IPAddress,
+		req.UserAgent,
+		req.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	req.ID, err = res.LastInsertId()
 	return err
 }
 
+func (r *mySQLImpersonationRepository) ListRequests(ctx context.Context, impersonationID string) ([]ImpersonatedRequest, error) {
+	const query = `
+SELECT id, impersonation_id, actor_id, subject_id, method, route, path, status,
+       COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+FROM impersonation_requests
+WHERE impersonation_id = ?
+ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, impersonationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ImpersonatedRequest{}
+	for rows.Next() {
+		var q ImpersonatedRequest
+		if err := rows.Scan(
+			&q.ID,
+			&q.ImpersonationID,
+			&q.ActorID,
+			&q.SubjectID,
+			&q.Method,
+			&q.Route,
+			&q.Path,
+			&q.Status,
+			&q.IPAddress,
+			&q.UserAgent,
+			&q.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, q)
+	}
+	return out, rows.Err()
+}
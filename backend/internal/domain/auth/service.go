@@ -2,38 +2,235 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+	"github.com/example/global-trade-hub/backend/internal/mail"
+	"github.com/example/global-trade-hub/backend/internal/metrics"
+	"github.com/example/global-trade-hub/backend/internal/oidc"
+	"github.com/example/global-trade-hub/backend/internal/totp"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
-// Hard-coded master admin (full panel access, independent from DB).
 const (
-	masterAdminID       = "master-admin"
-	masterAdminEmail    = "alireza@asllmarket.com"
-	masterAdminPassword = "Qwertyuiop123!"
-	masterAdminName     = "Master Administrator"
+	verifyEmailTokenTTL   = 48 * time.Hour
+	passwordResetTokenTTL = time.Hour
+	mfaPendingTokenTTL    = 5 * time.Minute
+	oidcStateTTL          = 10 * time.Minute
+	oidcLoginCodeTTL      = 2 * time.Minute
+	staffInviteTTL        = 7 * 24 * time.Hour
+
+	// tokenTypeMFAPending marks the short-lived token returned by Login when a
+	// second factor is still needed.
+	tokenTypeMFAPending = "mfa_pending"
+	// tokenTypeOIDCState marks the signed cookie that carries state, nonce
+	// and PKCE verifier through an OIDC redirect.
+	tokenTypeOIDCState = "oidc_state"
+
+	// sessionSeenResolution limits how often a session's last-seen time is
+	// written while its access tokens are in use.
+	sessionSeenResolution = time.Minute
+
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from one step before/after the current one.
+	totpSkew = 1
+)
+
+// mfaEligibleRoles are the roles that may enroll in two-factor authentication.
+var mfaEligibleRoles = map[UserRole]bool{
+	RoleAdmin:       true,
+	RoleModerator:   true,
+	RoleKYCReviewer: true,
+	RoleFinance:     true,
+	RoleSupplier:    true,
+}
+
+var (
+	// ErrInvalidRefreshToken is returned when a refresh token is malformed,
+	// expired, revoked or unknown.
+	ErrInvalidRefreshToken = apperr.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again. The whole token family is revoked when this happens.
+	ErrRefreshTokenReused = apperr.Unauthorized("refresh_token_reused", "refresh token reuse detected")
+	// ErrInvalidCredentials is returned when a password does not match.
+	ErrInvalidCredentials = apperr.Unauthorized("invalid_credentials", "invalid credentials")
+	// ErrWrongPassword is reported instead of ErrInvalidCredentials when a
+	// signed-in user confirms an action with a wrong password: clients take
+	// a 401 as an expired session, so this is a 400.
+	ErrWrongPassword = apperr.BadRequest("wrong_password", "password is incorrect")
+	// ErrAdminAlreadyExists is returned by BootstrapAdmin once any admin exists.
+	ErrAdminAlreadyExists = apperr.Conflict("admin_already_exists", "an admin account already exists")
+	// ErrInvalidActionToken is returned when an email verification or password
+	// reset token is malformed, expired, already used or for another purpose.
+	ErrInvalidActionToken = apperr.BadRequest("invalid_action_token", "invalid or expired token")
+	// ErrEmailAlreadyVerified is returned when requesting a verification email
+	// for an address that is already confirmed.
+	ErrEmailAlreadyVerified = apperr.Conflict("email_already_verified", "email already verified")
+	// ErrMFANotAllowed is returned when the user's role cannot enroll in 2FA.
+	ErrMFANotAllowed = apperr.Forbidden("mfa_not_allowed", "two-factor authentication is not available for this account")
+	// ErrMFAAlreadyEnabled is returned when starting enrollment while 2FA is on.
+	ErrMFAAlreadyEnabled = apperr.Conflict("mfa_already_enabled", "two-factor authentication already enabled")
+	// ErrMFANotEnabled is returned for operations that need active 2FA.
+	ErrMFANotEnabled = apperr.Conflict("mfa_not_enabled", "two-factor authentication not enabled")
+	// ErrMFARequiredByPolicy is returned when disabling 2FA for a role that
+	// must use it.
+	ErrMFARequiredByPolicy = apperr.Forbidden("mfa_required_by_policy", "two-factor authentication is mandatory for this account")
+	// ErrInvalidMFACode is returned for a wrong, expired or replayed code.
+	ErrInvalidMFACode = apperr.BadRequest("invalid_mfa_code", "invalid two-factor code")
+	// ErrInvalidMFAToken is returned when the pending login token is invalid.
+	ErrInvalidMFAToken = apperr.Unauthorized("invalid_mfa_token", "invalid or expired two-factor login token")
+	// ErrInvalidOIDCState is returned when an OIDC callback does not match the
+	// flow started in this browser (missing/expired cookie, wrong state).
+	ErrInvalidOIDCState = errors.New("invalid or expired sign-in attempt")
+	// ErrOIDCEmailNotVerified is returned when the identity provider does not
+	// vouch for the user's email address.
+	ErrOIDCEmailNotVerified = errors.New("identity provider did not return a verified email")
+	// ErrOIDCLinkNotAllowed is returned when an external identity would be
+	// linked to an account that must use a password (admins).
+	ErrOIDCLinkNotAllowed = errors.New("this account cannot sign in with an external identity provider")
+	// ErrAccountSuspended is returned when a suspended account tries to sign
+	// in or refresh its tokens.
+	ErrAccountSuspended = apperr.Forbidden("account_suspended", "account suspended")
+	// ErrAccountInactive is returned when a deactivated or deleted account
+	// tries to sign in or refresh its tokens.
+	ErrAccountInactive = apperr.Forbidden("account_inactive", "account inactive")
+	// ErrInvalidInvitation is returned when a staff invitation token is
+	// malformed, expired, revoked or already accepted.
+	ErrInvalidInvitation = apperr.BadRequest("invalid_invitation", "invalid or expired invitation")
+	// ErrNotStaff is returned by staff management for non-staff accounts.
+	ErrNotStaff = apperr.NotFound("not_staff", "user is not a staff member")
+	// ErrModifySelf is returned when an admin changes their own role or status.
+	ErrModifySelf = apperr.Conflict("modify_self", "you cannot change your own role or status")
+	// ErrLastAdmin is returned when a change would leave no active admin.
+	ErrLastAdmin = apperr.Conflict("last_admin", "at least one active admin must remain")
+	// ErrDeletionPending is returned when an account deletion was already
+	// requested.
+	ErrDeletionPending = apperr.Conflict("deletion_pending", "account deletion already scheduled")
+	// ErrImpersonationNotAllowed is returned when the target user cannot be
+	// impersonated (staff, the admin themselves, or inactive accounts).
+	ErrImpersonationNotAllowed = apperr.Forbidden("impersonation_not_allowed", "this account cannot be impersonated")
+	// ErrImpersonationDisabled is returned when no impersonation store is
+	// configured.
+	ErrImpersonationDisabled = apperr.New(http.StatusNotImplemented, "impersonation_disabled", "impersonation is not enabled")
 )
 
+// oidcStateClaims travel in the OIDC flow cookie between the authorize and
+// callback requests.
+type oidcStateClaims struct {
+	TokenType string `json:"typ"`
+	Provider  string `json:"prv"`
+	State     string `json:"state"`
+	Nonce     string `json:"nonce"`
+	Verifier  string `json:"pkce"`
+	jwt.RegisteredClaims
+}
+
+// LoginThrottledError is returned by Login while an account or client IP is
+// backing off or locked out after repeated failures.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return "too many failed login attempts; temporarily locked"
+	}
+	return "too many failed login attempts; slow down"
+}
+
+// MFARequiredError is returned by Login when the password was correct but the
+// account has 2FA enabled. MFAToken must be exchanged, together with a code,
+// at /auth/login/2fa.
+type MFARequiredError struct {
+	MFAToken  string
+	ExpiresAt time.Time
+}
+
+func (e *MFARequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
 // Service contains business logic for authentication and user management.
 type Service struct {
 	repo       UserRepository
-	jwtSecret  string
-	jwtIssuer  string
+	tokens     RefreshTokenRepository
+	actions    ActionTokenRepository
+	mfa        MFARepository
+	mailer     mail.Mailer
+	appBaseURL string
+	keys       *jwtkeys.Keyring
 	accessTTL  time.Duration
 	refreshTTL time.Duration
+
+	mfaIssuer        string
+	mfaRequiredRoles map[UserRole]bool
+
+	attempts       LoginAttemptStore
+	throttlePolicy LoginThrottlePolicy
+
+	oidc       *oidc.Registry
+	identities IdentityRepository
+
+	invitations InvitationRepository
+
+	impersonations   ImpersonationRepository
+	impersonationTTL time.Duration
+
+	metrics *metrics.Metrics
 }
 
-func NewService(repo UserRepository, secret, issuer string) *Service {
+func NewService(repo UserRepository, tokens RefreshTokenRepository, actions ActionTokenRepository, mfa MFARepository, keys *jwtkeys.Keyring) *Service {
 	return &Service{
-		repo:       repo,
-		jwtSecret:  secret,
-		jwtIssuer:  issuer,
-		accessTTL:  15 * time.Minute,
-		refreshTTL: 30 * 24 * time.Hour,
+		repo:             repo,
+		tokens:           tokens,
+		actions:          actions,
+		mfa:              mfa,
+		keys:             keys,
+		accessTTL:        15 * time.Minute,
+		refreshTTL:       30 * 24 * time.Hour,
+		mfaIssuer:        keys.Issuer(),
+		mfaRequiredRoles: map[UserRole]bool{RoleAdmin: true},
+	}
+}
+
+// WithInvitations enables invitation-based staff accounts.
+func (s *Service) WithInvitations(invitations InvitationRepository) {
+	s.invitations = invitations
+}
+
+// WithImpersonation lets admins act as other users with access tokens that
+// expire after ttl.
+func (s *Service) WithImpersonation(impersonations ImpersonationRepository, ttl time.Duration) {
+	s.impersonations = impersonations
+	s.impersonationTTL = ttl
+}
+
+// WithMFAPolicy sets the issuer name shown in authenticator apps and the roles
+// for which two-factor authentication is mandatory.
+func (s *Service) WithMFAPolicy(issuer string, requiredRoles []string) {
+	s.mfaIssuer = issuer
+	s.mfaRequiredRoles = make(map[UserRole]bool, len(requiredRoles))
+	for _, r := range requiredRoles {
+		s.mfaRequiredRoles[UserRole(r)] = true
 	}
 }
 
@@ -43,7 +240,35 @@ func (s *Service) WithTokenTTL(access, refresh time.Duration) {
 	s.refreshTTL = refresh
 }
 
-func (s *Service) Register(ctx context.Context, in RegisterInput) (*User, *TokenPair, error) {
+// WithLoginThrottle enables brute-force protection for logins. Without it,
+// login attempts are not limited.
+func (s *Service) WithLoginThrottle(store LoginAttemptStore, policy LoginThrottlePolicy) {
+	s.attempts = store
+	s.throttlePolicy = policy
+}
+
+// WithOIDC enables sign-in through the registry's identity providers.
+func (s *Service) WithOIDC(registry *oidc.Registry, identities IdentityRepository) {
+	s.oidc = registry
+	s.identities = identities
+}
+
+// WithMetrics counts registrations and failed logins in m.
+func (s *Service) WithMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// WithMailer configures how verification and password reset emails are sent.
+// appBaseURL is the frontend origin the emailed links point to.
+func (s *Service) WithMailer(mailer mail.Mailer, appBaseURL string) {
+	s.mailer = mailer
+	s.appBaseURL = strings.TrimRight(appBaseURL, "/")
+}
+
+func (s *Service) Register(ctx context.Context, in RegisterInput, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Register")
+	defer span.End()
+
 	// Hash password
 	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -59,137 +284,1808 @@ func (s *Service) Register(ctx context.Context, in RegisterInput) (*User, *Token
 	if err := s.repo.Create(ctx, u); err != nil {
 		return nil, nil, err
 	}
+	s.metrics.UserRegistered(string(u.Role), "password")
 
-	tokens, err := s.issueTokens(u)
+	// A failed email must not fail the registration; the user can ask for a
+	// new link via /auth/verify-email/resend.
+	if err := s.sendVerificationEmail(ctx, u); err != nil {
+		logging.FromContext(ctx).Warn("auth: failed to send verification email", "user_id", u.ID, "error", err)
+	}
+
+	tokens, err := s.issueTokens(ctx, u, "", client)
 	if err != nil {
 		return nil, nil, err
 	}
 	return u, tokens, nil
 }
 
-func (s *Service) Login(ctx context.Context, in LoginInput) (*User, *TokenPair, error) {
-	// 1) Check hard-coded master admin (not stored in DB)
-	if in.Email == masterAdminEmail && in.Password == masterAdminPassword {
-		now := time.Now()
-		master := &User{
-			ID:        masterAdminID,
-			Email:     masterAdminEmail,
-			Password:  "", // not used / not stored
-			Role:      RoleAdmin,
-			FullName:  masterAdminName,
-			CreatedAt: now,
-			UpdatedAt: now,
+func (s *Service) Login(ctx context.Context, in LoginInput, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Login")
+	defer span.End()
+
+	if err := s.checkLoginThrottle(ctx, in.Email, client.IPAddress); err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.repo.GetByEmail(ctx, in.Email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			s.recordLoginFailure(ctx, in.Email, client.IPAddress, nil, "unknown_email")
 		}
+		return nil, nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(in.Password)); err != nil {
+		s.recordLoginFailure(ctx, in.Email, client.IPAddress, u, "invalid_password")
+		return nil, nil, ErrInvalidCredentials
+	}
 
-		tokens, err := s.issueTokens(master)
+	settings, err := s.getMFA(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if settings.Enabled() {
+		challenge, err := s.issueMFAPendingToken(u)
 		if err != nil {
 			return nil, nil, err
 		}
-		return master, tokens, nil
+		return nil, nil, challenge
 	}
 
-	// 2) Fallback to normal DB-backed login
-	u, err := s.repo.GetByEmail(ctx, in.Email)
+	tokens, err := s.issueTokens(ctx, u, "", client)
 	if err != nil {
 		return nil, nil, err
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(in.Password)); err != nil {
+	s.resetLoginFailures(ctx, u.Email)
+	return u, tokens, nil
+}
+
+// CompleteMFALogin finishes a login started with Login by checking a TOTP
+// code or a recovery code against the pending token.
+func (s *Service) CompleteMFALogin(ctx context.Context, in LoginMFAInput, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.CompleteMFALogin")
+	defer span.End()
+
+	claims := &middleware.Claims{}
+	if err := s.keys.Parse(in.MFAToken, claims); err != nil || claims.TokenType != tokenTypeMFAPending {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	u, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, err := s.getMFA(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !settings.Enabled() {
+		return nil, nil, ErrMFANotEnabled
+	}
+
+	// Second-factor guesses count against the same account budget as
+	// password guesses.
+	if err := s.checkLoginThrottle(ctx, u.Email, client.IPAddress); err != nil {
 		return nil, nil, err
 	}
-	tokens, err := s.issueTokens(u)
+	if in.Code != "" {
+		err = s.verifyTOTP(ctx, settings, in.Code)
+	} else {
+		err = s.useRecoveryCode(ctx, u.ID, in.RecoveryCode)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.recordLoginFailure(ctx, u.Email, client.IPAddress, u, "invalid_mfa_code")
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := s.issueTokens(ctx, u, "", client)
 	if err != nil {
 		return nil, nil, err
 	}
+	s.resetLoginFailures(ctx, u.Email)
 	return u, tokens, nil
 }
 
-func (s *Service) GetUserByID(ctx context.Context, id string) (*User, error) {
-	// If the token belongs to the master admin, return a synthetic in-memory user
-	// so that /auth/me and other "current user" flows work without a DB row.
-	if id == masterAdminID {
-		now := time.Now()
-		return &User{
-			ID:        masterAdminID,
-			Email:     masterAdminEmail,
-			Password:  "",
-			Role:      RoleAdmin,
-			FullName:  masterAdminName,
-			CreatedAt: now,
-			UpdatedAt: now,
-		}, nil
+// OIDCProviders lists the identity providers users can sign in with.
+func (s *Service) OIDCProviders() []OIDCProvider {
+	out := []OIDCProvider{}
+	for _, p := range s.oidc.Providers() {
+		out = append(out, OIDCProvider{Name: p.Name, DisplayName: p.DisplayName})
 	}
-
-	return s.repo.GetByID(ctx, id)
+	return out
 }
 
-func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*User, *TokenPair, error) {
-	// Parse and validate the refresh token
-	token, err := jwt.ParseWithClaims(refreshToken, &middleware.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
+// BeginOIDCLogin starts an authorization code flow with PKCE. It returns the
+// provider URL to redirect the browser to and a signed state token that the
+// caller must store in a cookie for the callback.
+func (s *Service) BeginOIDCLogin(ctx context.Context, provider string) (authURL, stateToken string, err error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.BeginOIDCLogin")
+	defer span.End()
+
+	p, err := s.oidc.Get(provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomURLToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomURLToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err = p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	stateToken, err = s.keys.Sign(&oidcStateClaims{
+		TokenType: tokenTypeOIDCState,
+		Provider:  p.Name,
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			ExpiresAt: jwt.NewNumericDate(now.Add(oidcStateTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	})
 	if err != nil {
-		return nil, nil, err
+		return "", "", err
 	}
+	return authURL, stateToken, nil
+}
+
+// CompleteOIDCLogin handles the provider callback: it checks state against
+// the flow cookie, redeems the code, finds or creates the local user and
+// returns a one-time login code for ExchangeOIDCLoginCode. Each flow cookie
+// can be redeemed once; replays get ErrInvalidOIDCState.
+//
+// Users are matched by linked identity first, then by verified email (which
+// links the identity). Unknown emails get a new account with the provider's
+// default role.
+func (s *Service) CompleteOIDCLogin(ctx context.Context, provider, code, state, stateToken string) (string, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.CompleteOIDCLogin")
+	defer span.End()
 
-	claims, ok := token.Claims.(*middleware.Claims)
-	if !ok || !token.Valid {
-		return nil, nil, jwt.ErrSignatureInvalid
+	claims := &oidcStateClaims{}
+	if err := s.keys.Parse(stateToken, claims); err != nil ||
+		claims.TokenType != tokenTypeOIDCState || claims.Provider != provider ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 ||
+		claims.ID == "" || claims.ExpiresAt == nil {
+		return "", ErrInvalidOIDCState
+	}
+	if err := s.identities.ConsumeState(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		if errors.Is(err, ErrOIDCStateUsed) {
+			return "", ErrInvalidOIDCState
+		}
+		return "", err
 	}
 
-	// Get user from database
-	u, err := s.repo.GetByID(ctx, claims.UserID)
+	p, err := s.oidc.Get(provider)
+	if err != nil {
+		return "", err
+	}
+	identity, err := p.Exchange(ctx, code, claims.Verifier, claims.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := s.resolveOIDCUser(ctx, p, identity)
+	if err != nil {
+		return "", err
+	}
+	return s.issueActionToken(ctx, u, PurposeOIDCLogin, oidcLoginCodeTTL)
+}
+
+// ExchangeOIDCLoginCode trades the one-time code from the callback redirect
+// for a token pair. Users with 2FA get an *MFARequiredError as with Login.
+func (s *Service) ExchangeOIDCLoginCode(ctx context.Context, code string, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ExchangeOIDCLoginCode")
+	defer span.End()
+
+	t, err := s.consumeActionToken(ctx, code, PurposeOIDCLogin)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := s.repo.GetByID(ctx, t.UserID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Issue new tokens
-	tokens, err := s.issueTokens(u)
+	settings, err := s.getMFA(ctx, u.ID)
 	if err != nil {
 		return nil, nil, err
 	}
+	if settings.Enabled() {
+		challenge, err := s.issueMFAPendingToken(u)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, challenge
+	}
 
+	tokens, err := s.issueTokens(ctx, u, "", client)
+	if err != nil {
+		return nil, nil, err
+	}
 	return u, tokens, nil
 }
 
-func (s *Service) issueTokens(u *User) (*TokenPair, error) {
+// OIDCCallbackRedirect is the frontend URL the browser is sent to after the
+// provider callback, carrying either the one-time login code or an error.
+func (s *Service) OIDCCallbackRedirect(loginCode, errCode string) string {
+	q := url.Values{}
+	if loginCode != "" {
+		q.Set("code", loginCode)
+	}
+	if errCode != "" {
+		q.Set("error", errCode)
+	}
+	return s.appBaseURL + "/auth/oidc/callback?" + q.Encode()
+}
+
+func (s *Service) resolveOIDCUser(ctx context.Context, p *oidc.Provider, identity *oidc.Identity) (*User, error) {
+	now := time.Now().UTC()
+
+	link, err := s.identities.GetByProviderSubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		if err := s.identities.TouchLogin(ctx, link.ID, now); err != nil {
+			logging.FromContext(ctx).Warn("auth: failed to record identity login", "identity_id", link.ID, "error", err)
+		}
+		return s.repo.GetByID(ctx, link.UserID)
+	}
+	if !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	u, err := s.repo.GetByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		if u.Role.IsStaff() {
+			return nil, ErrOIDCLinkNotAllowed
+		}
+		if !u.EmailVerified() {
+			// Someone may have registered this address without owning it.
+			// The provider proved ownership, so lock out the unverified
+			// password and its sessions before linking.
+			if err := s.claimUnverifiedAccount(ctx, u, now); err != nil {
+				return nil, err
+			}
+		}
+	case errors.Is(err, ErrUserNotFound):
+		if u, err = s.createOIDCUser(ctx, p, identity, now); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if err := s.identities.Create(ctx, &ExternalIdentity{
+		UserID:      u.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *Service) claimUnverifiedAccount(ctx context.Context, u *User, now time.Time) error {
+	hash, err := unusablePasswordHash()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, u.ID, hash, false); err != nil {
+		return err
+	}
+	if err := s.tokens.RevokeAllForUser(ctx, u.ID, now); err != nil {
+		return err
+	}
+	if err := s.repo.MarkEmailVerified(ctx, u.ID, now); err != nil {
+		return err
+	}
+	u.Password = hash
+	u.EmailVerifiedAt = &now
+	return nil
+}
+
+// createOIDCUser provisions an account on first sign-in. It has no usable
+// password; the user can set one through the password reset flow.
+func (s *Service) createOIDCUser(ctx context.Context, p *oidc.Provider, identity *oidc.Identity, now time.Time) (*User, error) {
+	hash, err := unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	u := &User{
+		Email:           identity.Email,
+		Password:        hash,
+		Role:            UserRole(p.DefaultRole),
+		FullName:        name,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, err
+	}
+	s.metrics.UserRegistered(string(u.Role), "oidc")
+	return u, nil
+}
+
+// unusablePasswordHash hashes a random secret nobody knows.
+func unusablePasswordHash() (string, error) {
+	secret, err := randomURLToken(32)
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func randomURLToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// UnlockAccount clears failed login attempts and any lockout of the user's
+// account (admin action). IP lockouts are not affected.
+func (s *Service) UnlockAccount(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.UnlockAccount")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.attempts == nil {
+		return nil
+	}
+	return s.attempts.Reset(ctx, accountAttemptKey(u.Email))
+}
+
+// checkLoginThrottle rejects the attempt while the account or IP is locked,
+// or while the account's backoff delay since its last failure has not passed.
+func (s *Service) checkLoginThrottle(ctx context.Context, email, ip string) error {
+	if s.attempts == nil {
+		return nil
+	}
 	now := time.Now()
+	p := s.throttlePolicy
 
-	accessClaims := &middleware.Claims{
-		UserID: u.ID,
-		Role:   string(u.Role),
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    s.jwtIssuer,
-			Subject:   u.ID,
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
-			IssuedAt:  jwt.NewNumericDate(now),
-		},
+	var (
+		wait   time.Duration
+		locked bool
+	)
+	for _, key := range []string{accountAttemptKey(email), ipAttemptKey(ip)} {
+		until, err := s.attempts.LockedUntil(ctx, key)
+		if err != nil {
+			return err
+		}
+		if until.After(now) {
+			locked = true
+			if d := until.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	if !locked {
+		count, last, err := s.attempts.Failures(ctx, accountAttemptKey(email), now.Add(-p.Window))
+		if err != nil {
+			return err
+		}
+		if count > p.FreeAttempts {
+			delay := p.BaseDelay << min(count-p.FreeAttempts-1, 16)
+			if delay > p.MaxDelay {
+				delay = p.MaxDelay
+			}
+			if next := last.Add(delay); next.After(now) {
+				wait = next.Sub(now)
+			}
+		}
 	}
 
-	refreshClaims := &middleware.Claims{
-		UserID: u.ID,
-		Role:   string(u.Role),
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    s.jwtIssuer,
-			Subject:   u.ID,
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.refreshTTL)),
-			IssuedAt:  jwt.NewNumericDate(now),
-		},
+	if wait > 0 {
+		s.metrics.LoginFailed("throttled")
+		return &LoginThrottledError{RetryAfter: wait, Locked: locked}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the account and the IP
+// and starts a lockout once a limit is reached. u is nil for unknown emails;
+// reason labels the failure metric. Store errors are logged rather than
+// returned so the caller still sees the original authentication error.
+func (s *Service) recordLoginFailure(ctx context.Context, email, ip string, u *User, reason string) {
+	s.metrics.LoginFailed(reason)
+	if s.attempts == nil {
+		return
+	}
+	now := time.Now()
+	p := s.throttlePolicy
+
+	limits := map[string]int{
+		accountAttemptKey(email): p.MaxAccountFailures,
+		ipAttemptKey(ip):         p.MaxIPFailures,
+	}
+	for key, limit := range limits {
+		if err := s.attempts.RecordFailure(ctx, key, now); err != nil {
+			logging.FromContext(ctx).Warn("auth: failed to record login failure", "key", key, "error", err)
+			continue
+		}
+		count, _, err := s.attempts.Failures(ctx, key, now.Add(-p.Window))
+		if err != nil {
+			logging.FromContext(ctx).Warn("auth: failed to count login failures", "key", key, "error", err)
+			continue
+		}
+		if count < limit {
+			continue
+		}
+		until := now.Add(p.LockoutDuration)
+		if err := s.attempts.Lock(ctx, key, until); err != nil {
+			logging.FromContext(ctx).Warn("auth: failed to lock login", "key", key, "error", err)
+			continue
+		}
+		if key == accountAttemptKey(email) && u != nil && count == limit {
+			if err := s.sendLockoutNotice(ctx, u, until); err != nil {
+				logging.FromContext(ctx).Warn("auth: failed to send lockout notice", "user_id", u.ID, "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) resetLoginFailures(ctx context.Context, email string) {
+	if s.attempts == nil {
+		return
+	}
+	if err := s.attempts.Reset(ctx, accountAttemptKey(email)); err != nil {
+		logging.FromContext(ctx).Warn("auth: failed to reset login failures", "error", err)
+	}
+}
+
+func (s *Service) sendLockoutNotice(ctx context.Context, u *User, until time.Time) error {
+	return s.sendMail(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nWe locked sign-in to your account until %s (UTC) after too many failed login attempts.\n\nIf this was not you, reset your password once the lock expires:\n\n%s\n",
+			u.FullName, until.UTC().Format("2006-01-02 15:04"), s.appBaseURL+"/forgot-password",
+		),
+	})
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// MFAStatus returns the 2FA summary for the user.
+func (s *Service) MFAStatus(ctx context.Context, u *User) (*MFAStatus, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.MFAStatus")
+	defer span.End()
+
+	settings, err := s.getMFA(ctx, u.ID)
+	if err != nil {
+		return nil, err
 	}
+	status := &MFAStatus{
+		Enabled:  settings.Enabled(),
+		Required: s.mfaRequiredRoles[u.Role],
+	}
+	if status.Enabled {
+		if status.RecoveryCodesRemaining, err = s.mfa.CountUnusedRecoveryCodes(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
+// BeginMFAEnrollment generates a new TOTP secret for the user. 2FA is not
+// active until EnableMFA confirms a code from the authenticator app.
+func (s *Service) BeginMFAEnrollment(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.BeginMFAEnrollment")
+	defer span.End()
 
-	at, err := accessToken.SignedString([]byte(s.jwtSecret))
+	u, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	rt, err := refreshToken.SignedString([]byte(s.jwtSecret))
+	if !mfaEligibleRoles[u.Role] {
+		return nil, ErrMFANotAllowed
+	}
+	settings, err := s.getMFA(ctx, u.ID)
 	if err != nil {
 		return nil, err
 	}
+	if settings.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
 
-	return &TokenPair{
-		AccessToken:  at,
-		RefreshToken: rt,
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Upsert(ctx, u.ID, secret); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURL: totp.ProvisioningURI(s.mfaIssuer, u.Email, secret),
 	}, nil
 }
+
+// EnableMFA confirms enrollment with a code from the authenticator app and
+// returns freshly generated recovery codes (shown to the user only once). A
+// new token pair is issued in the caller's session so that a pending
+// enrollment requirement is lifted immediately.
+func (s *Service) EnableMFA(ctx context.Context, userID, sessionID, code string, client ClientInfo) ([]string, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.EnableMFA")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, err := s.mfa.Get(ctx, u.ID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotFound) {
+			return nil, nil, ErrMFANotEnabled
+		}
+		return nil, nil, err
+	}
+	if settings.Enabled() {
+		return nil, nil, ErrMFAAlreadyEnabled
+	}
+	if err := s.verifyTOTP(ctx, settings, code); err != nil {
+		return nil, nil, err
+	}
+	if err := s.mfa.Enable(ctx, u.ID, time.Now().UTC()); err != nil {
+		return nil, nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := s.issueTokens(ctx, u, sessionID, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return codes, tokens, nil
+}
+
+// DisableMFA turns 2FA off after re-checking the password and a current code.
+// Roles that must use 2FA cannot disable it.
+func (s *Service) DisableMFA(ctx context.Context, userID string, in DisableMFAInput) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.DisableMFA")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.mfaRequiredRoles[u.Role] {
+		return ErrMFARequiredByPolicy
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(in.Password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	settings, err := s.getMFA(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled() {
+		return ErrMFANotEnabled
+	}
+	if err := s.verifyTOTP(ctx, settings, in.Code); err != nil {
+		return err
+	}
+	return s.mfa.Delete(ctx, u.ID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a
+// current TOTP code.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.RegenerateRecoveryCodes")
+	defer span.End()
+
+	settings, err := s.getMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled() {
+		return nil, ErrMFANotEnabled
+	}
+	if err := s.verifyTOTP(ctx, settings, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// getMFA returns the user's 2FA settings, or nil if they never enrolled.
+func (s *Service) getMFA(ctx context.Context, userID string) (*MFASettings, error) {
+	settings, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+// verifyTOTP checks code and records its time step so it cannot be replayed.
+func (s *Service) verifyTOTP(ctx context.Context, settings *MFASettings, code string) error {
+	step, ok := totp.Validate(settings.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok || step <= settings.LastUsedStep {
+		return ErrInvalidMFACode
+	}
+	if err := s.mfa.AdvanceStep(ctx, settings.UserID, step); err != nil {
+		if errors.Is(err, ErrMFACodeReplayed) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) useRecoveryCode(ctx context.Context, userID, code string) error {
+	err := s.mfa.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)), time.Now().UTC())
+	if errors.Is(err, ErrRecoveryCodeNotFound) {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		// 10 lowercase hex characters, shown as xxxxx-xxxxx.
+		raw := hex.EncodeToString(buf)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashToken(raw)
+	}
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode strips separators and case so users can type codes
+// loosely.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func (s *Service) issueMFAPendingToken(u *User) (*MFARequiredError, error) {
+	now := time.Now()
+	expiresAt := now.Add(mfaPendingTokenTTL)
+	claims := &middleware.Claims{
+		UserID:    u.ID,
+		TokenType: tokenTypeMFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			Subject:   u.ID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	signed, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &MFARequiredError{MFAToken: signed, ExpiresAt: expiresAt}, nil
+}
+
+func (s *Service) GetUserByID(ctx context.Context, id string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.GetUserByID")
+	defer span.End()
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateProfile applies the fields set in in to the user's profile.
+func (s *Service) UpdateProfile(ctx context.Context, userID string, in UpdateProfileInput) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.UpdateProfile")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if in.FullName != nil {
+		u.FullName = strings.TrimSpace(*in.FullName)
+	}
+	if in.Phone != nil {
+		u.Phone = strings.TrimSpace(*in.Phone)
+	}
+	if in.Locale != nil {
+		u.Locale = *in.Locale
+	}
+	if in.AvatarURL != nil {
+		u.AvatarURL = *in.AvatarURL
+	}
+	if err := s.repo.UpdateProfile(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// ChangePassword replaces the user's password after verifying the current one
+// and clears any pending forced change. Every session is signed out, and the
+// caller gets a token pair for a new one.
+func (s *Service) ChangePassword(ctx context.Context, userID string, in ChangePasswordInput, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ChangePassword")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(in.CurrentPassword)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.UpdatePassword(ctx, u.ID, string(hash), false); err != nil {
+		return nil, nil, err
+	}
+	u.Password = string(hash)
+	u.MustChangePassword = false
+
+	if err := s.LogoutAll(ctx, u.ID); err != nil {
+		return nil, nil, err
+	}
+	tokens, err := s.issueTokens(ctx, u, "", client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, tokens, nil
+}
+
+// ScheduleDeletion records the user's request to delete their account after
+// verifying the password. The account is anonymized once grace has passed
+// unless the user signs in again before then. All sessions are signed out.
+func (s *Service) ScheduleDeletion(ctx context.Context, userID, password string, grace time.Duration) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ScheduleDeletion")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if u.DeletionScheduledAt != nil {
+		return nil, ErrDeletionPending
+	}
+
+	at := time.Now().UTC().Add(grace)
+	if err := s.repo.ScheduleDeletion(ctx, u.ID, &at); err != nil {
+		return nil, err
+	}
+	u.DeletionScheduledAt = &at
+	if err := s.LogoutAll(ctx, u.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.sendMail(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nWe received your request to delete your Global Trade Hub account. It will be deleted on %s (UTC).\n\nChanged your mind? Sign in before then to keep your account:\n\n%s\n",
+			u.FullName, at.Format("2006-01-02 15:04"), s.appBaseURL+"/login",
+		),
+	}); err != nil {
+		logging.FromContext(ctx).Warn("auth: failed to send deletion notice", "user_id", u.ID, "error", err)
+	}
+	return u, nil
+}
+
+// BootstrapAdmin creates the first admin account. It refuses to run once any
+// admin exists; further admins are invited (see InviteStaff). The account
+// must change its password on first login.
+func (s *Service) BootstrapAdmin(ctx context.Context, in BootstrapAdminInput) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.BootstrapAdmin")
+	defer span.End()
+
+	n, err := s.repo.CountByRole(ctx, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, ErrAdminAlreadyExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// The operator vouches for the address, so it starts out verified.
+	now := time.Now().UTC()
+	u := &User{
+		Email:              in.Email,
+		Password:           string(hash),
+		Role:               RoleAdmin,
+		FullName:           in.FullName,
+		MustChangePassword: true,
+		EmailVerifiedAt:    &now,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, err
+	}
+	s.metrics.UserRegistered(string(u.Role), "admin")
+	return u, nil
+}
+
+// InviteStaff emails a signed, expiring invitation to create an admin or staff
+// account with the given role. Earlier pending invitations for the same
+// address are revoked.
+func (s *Service) InviteStaff(ctx context.Context, inviterID string, in InviteStaffInput) (*StaffInvitation, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.InviteStaff")
+	defer span.End()
+
+	if !in.Role.IsStaff() {
+		return nil, ErrNotStaff
+	}
+	if _, err := s.repo.GetByEmail(ctx, in.Email); err == nil {
+		return nil, ErrEmailAlreadyUsed
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	inviter, err := s.repo.GetByID(ctx, inviterID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.invitations.RevokePendingForEmail(ctx, in.Email, now); err != nil {
+		return nil, err
+	}
+
+	inv := &StaffInvitation{
+		ID:        uuid.NewString(),
+		Email:     in.Email,
+		Role:      in.Role,
+		InvitedBy: inviter.ID,
+		ExpiresAt: now.Add(staffInviteTTL),
+	}
+	signed, err := s.keys.Sign(&middleware.Claims{
+		TokenType: string(PurposeStaffInvite),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        inv.ID,
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			Subject:   inv.Email,
+			ExpiresAt: jwt.NewNumericDate(inv.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	inv.TokenHash = hashToken(signed)
+	if err := s.invitations.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	if err := s.sendMail(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: "You have been invited to the Global Trade Hub team",
+		Body: fmt.Sprintf(
+			"Hello,\n\n%s invited you to join the Global Trade Hub team as %s. Create your account with the link below within %s:\n\n%s\n",
+			inviter.FullName, inv.Role, staffInviteTTL, s.actionLink("/accept-invitation", signed),
+		),
+	}); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ListInvitations returns the staff invitations that can still be accepted.
+func (s *Service) ListInvitations(ctx context.Context) ([]StaffInvitation, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ListInvitations")
+	defer span.End()
+	return s.invitations.ListPending(ctx, time.Now().UTC())
+}
+
+// RevokeInvitation invalidates a pending invitation link.
+func (s *Service) RevokeInvitation(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.RevokeInvitation")
+	defer span.End()
+	return s.invitations.Revoke(ctx, id, time.Now().UTC())
+}
+
+// AcceptInvitation creates the invited account and signs it in. The link was
+// delivered by email, so the address starts out verified.
+func (s *Service) AcceptInvitation(ctx context.Context, in AcceptInvitationInput, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.AcceptInvitation")
+	defer span.End()
+
+	claims := &middleware.Claims{}
+	if err := s.keys.Parse(in.Token, claims); err != nil || claims.TokenType != string(PurposeStaffInvite) || claims.ID == "" {
+		return nil, nil, ErrInvalidInvitation
+	}
+	inv, err := s.invitations.GetByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, ErrInvitationNotFound) {
+			return nil, nil, ErrInvalidInvitation
+		}
+		return nil, nil, err
+	}
+	now := time.Now().UTC()
+	if !inv.Pending(now) || subtle.ConstantTimeCompare([]byte(inv.TokenHash), []byte(hashToken(in.Token))) != 1 {
+		return nil, nil, ErrInvalidInvitation
+	}
+
+	if _, err := s.repo.GetByEmail(ctx, inv.Email); err == nil {
+		return nil, nil, ErrEmailAlreadyUsed
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return nil, nil, err
+	}
+
+	// Claim the invitation first so that it cannot create two accounts.
+	if err := s.invitations.MarkAccepted(ctx, inv.ID, now); err != nil {
+		if errors.Is(err, ErrInvitationNotPending) {
+			return nil, nil, ErrInvalidInvitation
+		}
+		return nil, nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := &User{
+		Email:           inv.Email,
+		Password:        string(hash),
+		Role:            inv.Role,
+		FullName:        in.FullName,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, nil, err
+	}
+	s.metrics.UserRegistered(string(u.Role), "invitation")
+
+	tokens, err := s.issueTokens(ctx, u, "", client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, tokens, nil
+}
+
+// ListStaff returns all admin and staff accounts.
+func (s *Service) ListStaff(ctx context.Context) ([]User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ListStaff")
+	defer span.End()
+
+	roles := make([]UserRole, 0, len(staffRoles))
+	for role := range staffRoles {
+		roles = append(roles, role)
+	}
+	return s.repo.ListByRoles(ctx, roles)
+}
+
+// UpdateStaffRole moves a staff member to another staff role. Their sessions
+// are revoked so that tokens carrying the old role stop working.
+func (s *Service) UpdateStaffRole(ctx context.Context, actorID, userID string, role UserRole) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.UpdateStaffRole")
+	defer span.End()
+
+	if !role.IsStaff() {
+		return nil, ErrNotStaff
+	}
+	u, err := s.staffTarget(ctx, actorID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Role == role {
+		return u, nil
+	}
+	if u.Role == RoleAdmin {
+		if err := s.ensureOtherActiveAdmin(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateRole(ctx, u.ID, role); err != nil {
+		return nil, err
+	}
+	if err := s.tokens.RevokeAllForUser(ctx, u.ID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	u.Role = role
+	return u, nil
+}
+
+// UpdateStaffStatus suspends or reactivates a staff member. Suspending signs
+// them out everywhere.
+func (s *Service) UpdateStaffStatus(ctx context.Context, actorID, userID string, status UserStatus) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.UpdateStaffStatus")
+	defer span.End()
+
+	u, err := s.staffTarget(ctx, actorID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setStatus(ctx, u, status)
+}
+
+// UpdateUserStatus sets the status of any account (active, inactive or
+// suspended) for admin actorID, with the same guards as UpdateStaffStatus:
+// admins cannot change their own status or deactivate the last active admin,
+// and accounts that are no longer active are signed out everywhere.
+func (s *Service) UpdateUserStatus(ctx context.Context, actorID, userID string, status UserStatus) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.UpdateUserStatus")
+	defer span.End()
+
+	if actorID == userID {
+		return nil, ErrModifySelf
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setStatus(ctx, u, status)
+}
+
+// setStatus changes the status of u, revoking its sessions unless the new
+// status is active.
+func (s *Service) setStatus(ctx context.Context, u *User, status UserStatus) (*User, error) {
+	if u.Status == status {
+		return u, nil
+	}
+	if status != StatusActive && u.Role == RoleAdmin {
+		if err := s.ensureOtherActiveAdmin(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, u.ID, status); err != nil {
+		return nil, err
+	}
+	if status != StatusActive {
+		if err := s.tokens.RevokeAllForUser(ctx, u.ID, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+	u.Status = status
+	return u, nil
+}
+
+// staffTarget loads the staff member an admin wants to change.
+func (s *Service) staffTarget(ctx context.Context, actorID, userID string) (*User, error) {
+	if actorID == userID {
+		return nil, ErrModifySelf
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.Role.IsStaff() {
+		return nil, ErrNotStaff
+	}
+	return u, nil
+}
+
+// ensureOtherActiveAdmin fails if admin u is the last active one.
+func (s *Service) ensureOtherActiveAdmin(ctx context.Context, u *User) error {
+	n, err := s.repo.CountActiveByRole(ctx, RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if n <= 1 && u.Active() {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// ResendVerificationEmail issues a new email verification link, invalidating
+// any earlier one.
+func (s *Service) ResendVerificationEmail(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.ResendVerificationEmail")
+	defer span.End()
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendVerificationEmail(ctx, u)
+}
+
+// VerifyEmail consumes an email verification token and marks the address as
+// verified.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.VerifyEmail")
+	defer span.End()
+
+	t, err := s.consumeActionToken(ctx, token, PurposeVerifyEmail)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MarkEmailVerified(ctx, t.UserID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, t.UserID)
+}
+
+// ForgotPassword emails a password reset link if the address belongs to an
+// account. Unknown addresses are ignored so callers cannot probe for accounts.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.ForgotPassword")
+	defer span.End()
+
+	u, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueActionToken(ctx, u, PurposePasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendMail(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nWe received a request to reset your password. Use the link below within %s:\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			u.FullName, passwordResetTokenTTL, s.actionLink("/reset-password", token),
+		),
+	})
+}
+
+// ResetPassword consumes a password reset token and sets a new password. All
+// sessions of the user are revoked. Since the link was delivered by email, the
+// address is also marked as verified.
+func (s *Service) ResetPassword(ctx context.Context, in ResetPasswordInput) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.ResetPassword")
+	defer span.End()
+
+	t, err := s.consumeActionToken(ctx, in.Token, PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if err := s.repo.UpdatePassword(ctx, t.UserID, string(hash), false); err != nil {
+		return err
+	}
+	if err := s.repo.MarkEmailVerified(ctx, t.UserID, now); err != nil {
+		return err
+	}
+	return s.tokens.RevokeAllForUser(ctx, t.UserID, now)
+}
+
+func (s *Service) sendVerificationEmail(ctx context.Context, u *User) error {
+	token, err := s.issueActionToken(ctx, u, PurposeVerifyEmail, verifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendMail(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm your email address by opening the link below within %s:\n\n%s\n",
+			u.FullName, verifyEmailTokenTTL, s.actionLink("/verify-email", token),
+		),
+	})
+}
+
+func (s *Service) sendMail(ctx context.Context, msg mail.Message) error {
+	if s.mailer == nil {
+		return errors.New("mailer not configured")
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+func (s *Service) actionLink(path, token string) string {
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// issueActionToken signs a single-use token for purpose and stores its hash.
+// Earlier unused tokens of the same purpose are invalidated.
+func (s *Service) issueActionToken(ctx context.Context, u *User, purpose TokenPurpose, ttl time.Duration) (string, error) {
+	now := time.Now().UTC()
+	if err := s.actions.InvalidateForUser(ctx, u.ID, purpose, now); err != nil {
+		return "", err
+	}
+
+	id := uuid.NewString()
+	claims := &middleware.Claims{
+		UserID:    u.ID,
+		TokenType: string(purpose),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			Subject:   u.ID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	signed, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.actions.Create(ctx, &ActionToken{
+		ID:        id,
+		UserID:    u.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(signed),
+		ExpiresAt: now.Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return signed, nil
+}
+
+// consumeActionToken verifies a single-use token for purpose and marks it used.
+func (s *Service) consumeActionToken(ctx context.Context, token string, purpose TokenPurpose) (*ActionToken, error) {
+	claims := &middleware.Claims{}
+	if err := s.keys.Parse(token, claims); err != nil || claims.TokenType != string(purpose) || claims.ID == "" {
+		return nil, ErrInvalidActionToken
+	}
+
+	t, err := s.actions.GetByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, ErrActionTokenNotFound) {
+			return nil, ErrInvalidActionToken
+		}
+		return nil, err
+	}
+	if t.Purpose != purpose || t.UsedAt != nil || time.Now().After(t.ExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(hashToken(token))) != 1 {
+		return nil, ErrInvalidActionToken
+	}
+
+	if err := s.actions.MarkUsed(ctx, t.ID, time.Now().UTC()); err != nil {
+		if errors.Is(err, ErrActionTokenUsed) {
+			return nil, ErrInvalidActionToken
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. The presented
+// token is single-use: it is rotated to a new token in the same family, and
+// presenting it again revokes the entire family.
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (*User, *TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.RefreshToken")
+	defer span.End()
+
+	stored, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	if stored.RevokedAt != nil || now.After(stored.ExpiresAt) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if stored.RotatedAt != nil {
+		return nil, nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+	}
+
+	u, err := s.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Claim the old token before issuing the new one so that two concurrent
+	// refreshes with the same token cannot both succeed.
+	nextID := uuid.NewString()
+	if err := s.tokens.MarkRotated(ctx, stored.ID, nextID, now); err != nil {
+		if errors.Is(err, ErrRefreshTokenAlreadyRotated) {
+			return nil, nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := s.issueTokensWithID(ctx, u, stored.FamilyID, nextID, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return u, tokens, nil
+}
+
+// Logout revokes the session (token family) the given refresh token belongs to.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.Logout")
+	defer span.End()
+
+	stored, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.tokens.RevokeFamily(ctx, stored.FamilyID, time.Now().UTC())
+}
+
+// LogoutAll revokes every session of the user, on all devices.
+func (s *Service) LogoutAll(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.LogoutAll")
+	defer span.End()
+	return s.tokens.RevokeAllForUser(ctx, userID, time.Now().UTC())
+}
+
+// ListSessions returns the user's active sessions, marking currentSessionID
+// (which may be empty) as the current one.
+func (s *Service) ListSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ListSessions")
+	defer span.End()
+
+	sessions, err := s.tokens.ListActiveSessions(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Device = describeDevice(sessions[i].UserAgent)
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// ListUserSessions is the admin view of another user's active sessions.
+func (s *Service) ListUserSessions(ctx context.Context, userID string) ([]Session, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ListUserSessions")
+	defer span.End()
+
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.ListSessions(ctx, userID, "")
+}
+
+// RevokeSession signs the user out of one session. Its refresh tokens stop
+// working immediately and its access tokens are rejected by JWTAuth.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.RevokeSession")
+	defer span.End()
+
+	session, err := s.tokens.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || !session.Active(time.Now()) {
+		return ErrSessionNotFound
+	}
+	return s.tokens.RevokeFamily(ctx, session.ID, time.Now().UTC())
+}
+
+// RevokeUserSessions is the admin action to sign a user out everywhere.
+func (s *Service) RevokeUserSessions(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.RevokeUserSessions")
+	defer span.End()
+
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+	return s.LogoutAll(ctx, userID)
+}
+
+// ValidateSession implements middleware.SessionValidator: access tokens are
+// only accepted while their session and their user are active. It also
+// records when the session was last used.
+func (s *Service) ValidateSession(ctx context.Context, claims *middleware.Claims) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.ValidateSession")
+	defer span.End()
+
+	if claims.SessionID == "" {
+		return middleware.ErrSessionRevoked
+	}
+	u, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return middleware.ErrSessionRevoked
+		}
+		return err
+	}
+	if !u.Active() {
+		return middleware.ErrSessionRevoked
+	}
+	if claims.Impersonated() {
+		return s.validateImpersonation(ctx, claims)
+	}
+	session, err := s.tokens.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return middleware.ErrSessionRevoked
+		}
+		return err
+	}
+
+	now := time.Now()
+	if session.UserID != claims.UserID || !session.Active(now) {
+		return middleware.ErrSessionRevoked
+	}
+	if now.Sub(session.LastSeenAt) >= sessionSeenResolution {
+		if err := s.tokens.TouchSession(ctx, session.ID, now.UTC()); err != nil {
+			logging.FromContext(ctx).Warn("auth: failed to record last use of session", "session_id", session.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Impersonate issues a short-lived access token that lets admin actorID act
+// as subjectID. The token carries the admin in its "act" claim and has no
+// refresh token. Staff accounts cannot be impersonated.
+func (s *Service) Impersonate(ctx context.Context, actorID, subjectID string, in ImpersonateInput, client ClientInfo) (*ImpersonationGrant, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Impersonate")
+	defer span.End()
+
+	if s.impersonations == nil {
+		return nil, ErrImpersonationDisabled
+	}
+	actor, err := s.repo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := s.repo.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if subject.ID == actor.ID || subject.Role.IsStaff() || subject.Status != StatusActive {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	now := time.Now()
+	imp := &Impersonation{
+		ActorID:      actor.ID,
+		ActorEmail:   actor.Email,
+		SubjectID:    subject.ID,
+		SubjectEmail: subject.Email,
+		Reason:       strings.TrimSpace(in.Reason),
+		IPAddress:    client.IPAddress,
+		UserAgent:    client.UserAgent,
+		ExpiresAt:    now.Add(s.impersonationTTL).UTC(),
+	}
+	if err := s.impersonations.Create(ctx, imp); err != nil {
+		return nil, err
+	}
+
+	claims := &middleware.Claims{
+		UserID:        subject.ID,
+		Role:          string(subject.Role),
+		TokenType:     middleware.TokenTypeAccess,
+		SessionID:     imp.ID,
+		EmailVerified: subject.EmailVerified(),
+		Actor:         &middleware.Actor{Subject: actor.ID, Role: string(actor.Role)},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			Subject:   subject.ID,
+			ExpiresAt: jwt.NewNumericDate(imp.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("auth: impersonation started", "actor_id", actor.ID, "subject_id", subject.ID, "impersonation_id", imp.ID)
+	return &ImpersonationGrant{Impersonation: imp, User: subject, Token: token}, nil
+}
+
+// validateImpersonation accepts an impersonation token while the
+// impersonation is active and the admin behind it still is one.
+func (s *Service) validateImpersonation(ctx context.Context, claims *middleware.Claims) error {
+	if s.impersonations == nil {
+		return middleware.ErrSessionRevoked
+	}
+	imp, err := s.impersonations.Get(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, ErrImpersonationNotFound) {
+			return middleware.ErrSessionRevoked
+		}
+		return err
+	}
+	if imp.SubjectID != claims.UserID || imp.ActorID != claims.Actor.Subject || !imp.Active(time.Now()) {
+		return middleware.ErrSessionRevoked
+	}
+
+	actor, err := s.repo.GetByID(ctx, imp.ActorID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return middleware.ErrSessionRevoked
+		}
+		return err
+	}
+	if actor.Role != RoleAdmin || actor.Status != StatusActive {
+		return middleware.ErrSessionRevoked
+	}
+	return nil
+}
+
+// ListImpersonations returns impersonations newest first, optionally only
+// those of subjectID.
+func (s *Service) ListImpersonations(ctx context.Context, subjectID string, limit, offset int) ([]Impersonation, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ListImpersonations")
+	defer span.End()
+
+	if s.impersonations == nil {
+		return nil, ErrImpersonationDisabled
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.impersonations.List(ctx, subjectID, limit, offset)
+}
+
+// EndImpersonation revokes an impersonation token before it expires.
+func (s *Service) EndImpersonation(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.EndImpersonation")
+	defer span.End()
+
+	if s.impersonations == nil {
+		return ErrImpersonationDisabled
+	}
+	return s.impersonations.End(ctx, id, time.Now().UTC())
+}
+
+// ImpersonatedRequests returns the audit trail of one impersonation.
+func (s *Service) ImpersonatedRequests(ctx context.Context, id string) ([]ImpersonatedRequest, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.ImpersonatedRequests")
+	defer span.End()
+
+	if s.impersonations == nil {
+		return nil, ErrImpersonationDisabled
+	}
+	if _, err := s.impersonations.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.impersonations.ListRequests(ctx, id)
+}
+
+// RecordImpersonatedRequest implements middleware.ImpersonationAuditor.
+func (s *Service) RecordImpersonatedRequest(ctx context.Context, req middleware.ImpersonatedRequest) error {
+	ctx, span := tracing.Start(ctx, "auth.Service.RecordImpersonatedRequest")
+	defer span.End()
+
+	if s.impersonations == nil {
+		return ErrImpersonationDisabled
+	}
+	return s.impersonations.RecordRequest(ctx, &ImpersonatedRequest{
+		ImpersonationID: req.ImpersonationID,
+		ActorID:         req.ActorID,
+		SubjectID:       req.SubjectID,
+		Method:          req.Method,
+		Route:           req.Route,
+		Path:            req.Path,
+		Status:          req.Status,
+		IPAddress:       req.IPAddress,
+		UserAgent:       req.UserAgent,
+		CreatedAt:       req.At,
+	})
+}
+
+// lookupRefreshToken verifies the signature and type of a refresh token and
+// loads its server-side record.
+func (s *Service) lookupRefreshToken(ctx context.Context, refreshToken string) (*RefreshToken, error) {
+	claims := &middleware.Claims{}
+	if err := s.keys.Parse(refreshToken, claims); err != nil || claims.TokenType != middleware.TokenTypeRefresh || claims.ID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	stored, err := s.tokens.GetByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(stored.TokenHash), []byte(hashToken(refreshToken))) != 1 {
+		return nil, ErrInvalidRefreshToken
+	}
+	return stored, nil
+}
+
+func (s *Service) revokeReusedFamily(ctx context.Context, familyID string) error {
+	if err := s.tokens.RevokeFamily(ctx, familyID, time.Now().UTC()); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// issueTokens signs a new token pair and persists the refresh token. An empty
+// familyID starts a new session.
+func (s *Service) issueTokens(ctx context.Context, u *User, familyID string, client ClientInfo) (*TokenPair, error) {
+	return s.issueTokensWithID(ctx, u, familyID, uuid.NewString(), client)
+}
+
+func (s *Service) issueTokensWithID(ctx context.Context, u *User, familyID, refreshID string, client ClientInfo) (*TokenPair, error) {
+	switch {
+	case u.Suspended():
+		return nil, ErrAccountSuspended
+	case !u.Active():
+		return nil, ErrAccountInactive
+	}
+	// Signing in again within the grace period keeps the account.
+	if u.DeletionScheduledAt != nil {
+		if err := s.repo.ScheduleDeletion(ctx, u.ID, nil); err != nil {
+			return nil, err
+		}
+		u.DeletionScheduledAt = nil
+	}
+	if familyID == "" {
+		familyID = uuid.NewString()
+	}
+
+	enrollmentRequired := false
+	if s.mfaRequiredRoles[u.Role] {
+		settings, err := s.getMFA(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		enrollmentRequired = !settings.Enabled()
+	}
+
+	now := time.Now()
+
+	accessClaims := &middleware.Claims{
+		UserID:                 u.ID,
+		Role:                   string(u.Role),
+		TokenType:              middleware.TokenTypeAccess,
+		SessionID:              familyID,
+		EmailVerified:          u.EmailVerified(),
+		MFAEnrollmentRequired:  enrollmentRequired,
+		PasswordChangeRequired: u.MustChangePassword,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			Subject:   u.ID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	refreshClaims := &middleware.Claims{
+		UserID:    u.ID,
+		Role:      string(u.Role),
+		TokenType: middleware.TokenTypeRefresh,
+		SessionID: familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        refreshID,
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience()},
+			Subject:   u.ID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.refreshTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	at, err := s.keys.Sign(accessClaims)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := s.keys.Sign(refreshClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokens.Create(ctx, &RefreshToken{
+		ID:        refreshID,
+		UserID:    u.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(rt),
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: now.Add(s.refreshTTL).UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  at,
+		RefreshToken: rt,
+	}, nil
+}
+
+// describeDevice turns a User-Agent header into a short label such as
+// "Chrome on Windows" for session lists.
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		platform = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		platform = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"), strings.Contains(userAgent, "Macintosh"):
+		platform = "macOS"
+	case strings.Contains(userAgent, "CrOS"):
+		platform = "ChromeOS"
+	case strings.Contains(userAgent, "Linux"):
+		platform = "Linux"
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+	// Non-browser clients (curl/8.4.0, okhttp/4.12, …): use the product name.
+	product, _, _ := strings.Cut(userAgent, "/")
+	product, _, _ = strings.Cut(product, " ")
+	return product
+}
+
+// hashToken returns the hex-encoded SHA-256 of a signed token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct horse battery staple"
+
+// newThrottledService returns a service with one buyer whose password is
+// testPassword, throttled by policy with an in-memory attempt store.
+func newThrottledService(t *testing.T, policy LoginThrottlePolicy) (*Service, *User) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &memUsers{byID: map[string]*User{}}
+	u := users.add(RoleBuyer)
+	users.byID[u.ID].Password = string(hash)
+
+	svc := NewService(users, newMemRefreshTokens(), &memActionTokens{}, &memMFA{byUser: map[string]*MFASettings{}}, newTestKeys(t))
+	svc.WithLoginThrottle(NewMemoryLoginAttemptStore(policy.Window), policy)
+	return svc, u
+}
+
+func TestLoginThrottle(t *testing.T) {
+	// No backoff delay unless a case sets one, so only lockouts throttle.
+	base := LoginThrottlePolicy{
+		Window:             15 * time.Minute,
+		FreeAttempts:       3,
+		MaxAccountFailures: 5,
+		MaxIPFailures:      100,
+		LockoutDuration:    15 * time.Minute,
+	}
+
+	tests := []struct {
+		name      string
+		baseDelay time.Duration
+		failures  int
+		password  string
+		// wantErr is the plain error expected when the attempt is not
+		// throttled; wantLocked is only checked when it is.
+		wantErr    error
+		throttled  bool
+		wantLocked bool
+	}{
+		{name: "under free attempts", failures: 3, password: "wrong", wantErr: ErrInvalidCredentials},
+		{name: "correct password under limit", failures: 4, password: testPassword},
+		{name: "backoff after free attempts", baseDelay: time.Minute, failures: 4, password: testPassword, throttled: true},
+		{name: "locked at account limit", failures: 5, password: "wrong", throttled: true, wantLocked: true},
+		{name: "correct password while locked", failures: 5, password: testPassword, throttled: true, wantLocked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			policy := base
+			policy.BaseDelay, policy.MaxDelay = tt.baseDelay, tt.baseDelay
+			svc, u := newThrottledService(t, policy)
+			client := ClientInfo{IPAddress: "203.0.113.7"}
+
+			for i := 0; i < tt.failures; i++ {
+				if _, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: "wrong"}, client); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("failure %d: %v", i+1, err)
+				}
+			}
+
+			_, tokens, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: tt.password}, client)
+			var throttled *LoginThrottledError
+			if errors.As(err, &throttled) != tt.throttled {
+				t.Fatalf("got %v, throttled want %v", err, tt.throttled)
+			}
+			if tt.throttled {
+				if throttled.Locked != tt.wantLocked || throttled.RetryAfter <= 0 {
+					t.Errorf("got %+v, want locked %v with a retry delay", throttled, tt.wantLocked)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && tokens == nil {
+				t.Fatal("no tokens issued")
+			}
+		})
+	}
+}
+
+func TestLoginThrottleLocksIP(t *testing.T) {
+	ctx := context.Background()
+	svc, u := newThrottledService(t, LoginThrottlePolicy{
+		Window:             15 * time.Minute,
+		FreeAttempts:       3,
+		MaxAccountFailures: 10,
+		MaxIPFailures:      3,
+		LockoutDuration:    15 * time.Minute,
+	})
+	attacker := ClientInfo{IPAddress: "203.0.113.7"}
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, _, err := svc.Login(ctx, LoginInput{Email: email, Password: "wrong"}, attacker); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("%s: %v", email, err)
+		}
+	}
+
+	var throttled *LoginThrottledError
+	if _, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: testPassword}, attacker); !errors.As(err, &throttled) || !throttled.Locked {
+		t.Fatalf("locked IP: %v, want a lockout", err)
+	}
+	if _, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: testPassword}, ClientInfo{IPAddress: "198.51.100.1"}); err != nil {
+		t.Fatalf("other IP: %v", err)
+	}
+}
+
+func TestLoginThrottleReset(t *testing.T) {
+	policy := LoginThrottlePolicy{
+		Window:             15 * time.Minute,
+		FreeAttempts:       3,
+		MaxAccountFailures: 5,
+		MaxIPFailures:      100,
+		LockoutDuration:    15 * time.Minute,
+	}
+
+	tests := []struct {
+		name string
+		// failures precede reset, which clears the account's counter.
+		failures int
+		reset    func(ctx context.Context, svc *Service, u *User) error
+	}{
+		{
+			name:     "successful login",
+			failures: 4,
+			reset: func(ctx context.Context, svc *Service, u *User) error {
+				_, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: testPassword}, ClientInfo{IPAddress: "203.0.113.7"})
+				return err
+			},
+		},
+		{
+			name:     "admin unlock",
+			failures: 5,
+			reset: func(ctx context.Context, svc *Service, u *User) error {
+				return svc.UnlockAccount(ctx, u.ID)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, u := newThrottledService(t, policy)
+			client := ClientInfo{IPAddress: "203.0.113.7"}
+
+			for i := 0; i < tt.failures; i++ {
+				if _, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: "wrong"}, client); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("failure %d: %v", i+1, err)
+				}
+			}
+			if err := tt.reset(ctx, svc, u); err != nil {
+				t.Fatalf("reset: %v", err)
+			}
+
+			// The full budget is available again: four more failures stay
+			// under the account limit.
+			for i := 0; i < policy.MaxAccountFailures-1; i++ {
+				if _, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: "wrong"}, client); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("failure %d after reset: %v", i+1, err)
+				}
+			}
+			if _, _, err := svc.Login(ctx, LoginInput{Email: u.Email, Password: testPassword}, client); err != nil {
+				t.Fatalf("login after reset: %v", err)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
 )
 
 type Handler struct {
@@ -23,12 +26,13 @@ func (h *Handler) List(c *gin.Context) {
 
 	categories, err := h.svc.List(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	if categories == nil {
 		categories = []*DBCategory{}
 	}
+	httpcache.SetListVersion(c, httpcache.Latest(categories, func(cat *DBCategory) time.Time { return cat.UpdatedAt }), len(categories))
 	c.JSON(http.StatusOK, gin.H{"items": categories})
 }
 
@@ -41,11 +45,11 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	cat, subcategories, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	if cat == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		_ = c.Error(apperr.NotFound("category_not_found", "category not found"))
 		return
 	}
 	if subcategories == nil {
@@ -1,6 +1,10 @@
 package category
 
-import "context"
+import (
+	"context"
+
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
 
 type Service struct {
 	repo Repository
@@ -12,11 +16,16 @@ func NewService(repo Repository) *Service {
 
 // List returns all categories; each category can optionally include subcategories (for GetByID we do).
 func (s *Service) List(ctx context.Context) ([]*DBCategory, error) {
+	ctx, span := tracing.Start(ctx, "category.Service.List")
+	defer span.End()
 	return s.repo.ListCategories(ctx)
 }
 
 // GetByID returns a category by ID with its subcategories.
 func (s *Service) GetByID(ctx context.Context, id string) (*DBCategory, []*DBSubcategory, error) {
+	ctx, span := tracing.Start(ctx, "category.Service.GetByID")
+	defer span.End()
+
 	cat, err := s.repo.GetCategoryByID(ctx, id)
 	if err != nil || cat == nil {
 		return nil, nil, err
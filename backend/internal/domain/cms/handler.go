@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
 )
 
 // Handler exposes HTTP handlers for CMS-related endpoints.
@@ -22,7 +25,7 @@ func NewHandler(svc *Service) *Handler {
 func (h *Handler) SubmitContact(c *gin.Context) {
 	var in CreateContactMessageInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
@@ -31,7 +34,7 @@ func (h *Handler) SubmitContact(c *gin.Context) {
 
 	msg, err := h.svc.CreateContactMessage(ctx, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -45,10 +48,11 @@ func (h *Handler) ListBlogPosts(c *gin.Context) {
 
 	posts, err := h.svc.ListBlogPosts(ctx, 100, 0)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	httpcache.SetListVersion(c, httpcache.Latest(posts, func(p *BlogPost) time.Time { return p.UpdatedAt }), len(posts))
 	c.JSON(http.StatusOK, gin.H{"items": posts})
 }
 
@@ -62,10 +66,10 @@ func (h *Handler) GetBlogPost(c *gin.Context) {
 	post, err := h.svc.GetBlogPostByID(ctx, id)
 	if err != nil {
 		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "blog post not found"})
+			_ = c.Error(apperr.NotFound("blog_post_not_found", "blog post not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -79,10 +83,11 @@ func (h *Handler) ListFAQs(c *gin.Context) {
 
 	faqs, err := h.svc.ListFAQs(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	httpcache.SetListVersion(c, httpcache.Latest(faqs, func(f *FAQ) time.Time { return f.UpdatedAt }), len(faqs))
 	c.JSON(http.StatusOK, gin.H{"items": faqs})
 }
 
@@ -93,7 +98,7 @@ func (h *Handler) ListJobs(c *gin.Context) {
 
 	jobs, err := h.svc.ListJobs(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -107,7 +112,7 @@ func (h *Handler) ListPressReleases(c *gin.Context) {
 
 	releases, err := h.svc.ListPressReleases(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -3,14 +3,15 @@ package cms
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 )
 
 var (
-	ErrNotFound = errors.New("cms resource not found")
+	ErrNotFound = apperr.NotFound("cms_resource_not_found", "cms resource not found")
 )
 
 // Repository defines persistence for CMS entities (currently contact messages, blog posts, FAQs, jobs, press).
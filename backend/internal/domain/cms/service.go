@@ -1,6 +1,10 @@
 package cms
 
-import "context"
+import (
+	"context"
+
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
 
 // Service contains business logic for CMS (contact, blog, etc.).
 type Service struct {
@@ -14,6 +18,9 @@ func NewService(repo Repository) *Service {
 
 // CreateContactMessage persists a new contact form submission.
 func (s *Service) CreateContactMessage(ctx context.Context, in CreateContactMessageInput) (*ContactMessage, error) {
+	ctx, span := tracing.Start(ctx, "cms.Service.CreateContactMessage")
+	defer span.End()
+
 	// Basic normalization / defaults
 	if in.InquiryType == "" {
 		in.InquiryType = "general"
@@ -39,26 +46,36 @@ func (s *Service) CreateContactMessage(ctx context.Context, in CreateContactMess
 
 // ListBlogPosts returns a paginated list of blog posts.
 func (s *Service) ListBlogPosts(ctx context.Context, limit, offset int) ([]*BlogPost, error) {
+	ctx, span := tracing.Start(ctx, "cms.Service.ListBlogPosts")
+	defer span.End()
 	return s.repo.ListBlogPosts(ctx, limit, offset)
 }
 
 // GetBlogPostByID returns a single blog post.
 func (s *Service) GetBlogPostByID(ctx context.Context, id string) (*BlogPost, error) {
+	ctx, span := tracing.Start(ctx, "cms.Service.GetBlogPostByID")
+	defer span.End()
 	return s.repo.GetBlogPostByID(ctx, id)
 }
 
 // ListFAQs returns all FAQs.
 func (s *Service) ListFAQs(ctx context.Context) ([]*FAQ, error) {
+	ctx, span := tracing.Start(ctx, "cms.Service.ListFAQs")
+	defer span.End()
 	return s.repo.ListFAQs(ctx)
 }
 
 // ListJobs returns all jobs (careers).
 func (s *Service) ListJobs(ctx context.Context) ([]*Job, error) {
+	ctx, span := tracing.Start(ctx, "cms.Service.ListJobs")
+	defer span.End()
 	return s.repo.ListJobs(ctx)
 }
 
 // ListPressReleases returns all press releases.
 func (s *Service) ListPressReleases(ctx context.Context) ([]*PressRelease, error) {
+	ctx, span := tracing.Start(ctx, "cms.Service.ListPressReleases")
+	defer span.End()
 	return s.repo.ListPressReleases(ctx)
 }
 
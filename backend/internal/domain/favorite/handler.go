@@ -3,12 +3,13 @@ package favorite
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
@@ -23,40 +24,40 @@ func NewHandler(svc *Service) *Handler {
 func (h *Handler) List(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, 50)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	list, err := h.svc.ListByUserID(ctx, claims.UserID, limit, offset)
+	list, err := h.svc.ListByUserID(ctx, claims.UserID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
-	if list == nil {
-		list = []*Favorite{}
-	}
-	c.JSON(http.StatusOK, gin.H{"items": list})
+	c.JSON(http.StatusOK, list)
 }
 
 // Add adds a product to favorites (protected).
 func (h *Handler) Add(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	productID := c.Param("productId")
 	if productID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "productId required"})
+		_ = c.Error(apperr.Validation("productId required", apperr.FieldError{Field: "productId", Code: "required"}))
 		return
 	}
 
@@ -65,7 +66,7 @@ func (h *Handler) Add(c *gin.Context) {
 
 	fav, err := h.svc.Add(ctx, claims.UserID, productID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, fav)
@@ -75,14 +76,14 @@ func (h *Handler) Add(c *gin.Context) {
 func (h *Handler) Remove(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	productID := c.Param("productId")
 	if productID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "productId required"})
+		_ = c.Error(apperr.Validation("productId required", apperr.FieldError{Field: "productId", Code: "required"}))
 		return
 	}
 
@@ -90,11 +91,7 @@ func (h *Handler) Remove(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.Remove(ctx, claims.UserID, productID); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "favorite not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
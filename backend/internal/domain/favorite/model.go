@@ -1,6 +1,10 @@
 package favorite
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 // Favorite represents a user's favorite product.
 type Favorite struct {
@@ -10,4 +14,9 @@ type Favorite struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 }
 
+// Cursor implements pagination.Row.
+func (f *Favorite) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: f.CreatedAt, ID: f.ID}
+}
+
 func (Favorite) TableName() string { return "favorites" }
@@ -3,16 +3,18 @@ package favorite
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
-var ErrNotFound = errors.New("favorite not found")
+var ErrNotFound = apperr.NotFound("favorite_not_found", "favorite not found")
 
 type Repository interface {
-	ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*Favorite, error)
+	ListByUserID(ctx context.Context, userID string, page pagination.Params) (*pagination.Page[*Favorite], error)
 	Add(ctx context.Context, userID, productID string) (*Favorite, error)
 	Remove(ctx context.Context, userID, productID string) error
 	Exists(ctx context.Context, userID, productID string) (bool, error)
@@ -26,15 +28,15 @@ func NewMySQLFavoriteRepository(db *sql.DB) Repository {
 	return &mySQLFavoriteRepository{db: db}
 }
 
-func (r *mySQLFavoriteRepository) ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*Favorite, error) {
-	const query = `
+func (r *mySQLFavoriteRepository) ListByUserID(ctx context.Context, userID string, page pagination.Params) (*pagination.Page[*Favorite], error) {
+	query := `
 SELECT id, user_id, product_id, created_at
 FROM favorites
-WHERE user_id = ?
-ORDER BY created_at DESC
+WHERE user_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(userID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +50,14 @@ LIMIT ? OFFSET ?`
 		}
 		list = append(list, &f)
 	}
-	return list, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM favorites WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(list, page, total), nil
 }
 
 func (r *mySQLFavoriteRepository) Add(ctx context.Context, userID, productID string) (*Favorite, error) {
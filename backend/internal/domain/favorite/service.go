@@ -1,6 +1,11 @@
 package favorite
 
-import "context"
+import (
+	"context"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
 
 type Service struct {
 	repo Repository
@@ -10,24 +15,27 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*Favorite, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 50
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListByUserID(ctx, userID, limit, offset)
+func (s *Service) ListByUserID(ctx context.Context, userID string, page pagination.Params) (*pagination.Page[*Favorite], error) {
+	ctx, span := tracing.Start(ctx, "favorite.Service.ListByUserID")
+	defer span.End()
+
+	return s.repo.ListByUserID(ctx, userID, page.Normalize(50))
 }
 
 func (s *Service) Add(ctx context.Context, userID, productID string) (*Favorite, error) {
+	ctx, span := tracing.Start(ctx, "favorite.Service.Add")
+	defer span.End()
 	return s.repo.Add(ctx, userID, productID)
 }
 
 func (s *Service) Remove(ctx context.Context, userID, productID string) error {
+	ctx, span := tracing.Start(ctx, "favorite.Service.Remove")
+	defer span.End()
 	return s.repo.Remove(ctx, userID, productID)
 }
 
 func (s *Service) Exists(ctx context.Context, userID, productID string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "favorite.Service.Exists")
+	defer span.End()
 	return s.repo.Exists(ctx, userID, productID)
 }
@@ -3,27 +3,30 @@ package message
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
-	svc *Service
+	svc       *Service
+	suppliers authz.SupplierResolver
 }
 
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *Service, suppliers authz.SupplierResolver) *Handler {
+	return &Handler{svc: svc, suppliers: suppliers}
 }
 
 // ListConversations returns all conversations for the authenticated user.
 func (h *Handler) ListConversations(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -33,7 +36,7 @@ func (h *Handler) ListConversations(c *gin.Context) {
 
 	conversations, err := h.svc.ListConversations(ctx, claims.UserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -43,19 +46,22 @@ func (h *Handler) ListConversations(c *gin.Context) {
 // ListMessages returns messages in a conversation.
 func (h *Handler) ListMessages(c *gin.Context) {
 	conversationID := c.Param("conversationId")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, 50)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	messages, err := h.svc.ListByConversationID(ctx, conversationID, limit, offset)
+	messages, err := h.svc.ListByConversationID(ctx, conversationID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": messages})
+	c.JSON(http.StatusOK, messages)
 }
 
 // GetByID returns a single message.
@@ -67,11 +73,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	message, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -82,13 +84,13 @@ func (h *Handler) GetByID(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateMessageInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -96,9 +98,20 @@ func (h *Handler) Create(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	message, err := h.svc.Create(ctx, claims.UserID, in)
+	// Members of a supplier organization write on its behalf if their team
+	// role allows it.
+	supplierID, role, err := h.suppliers.SupplierMembership(ctx, claims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if !authz.MemberCan(role, authz.MessagesSend) {
+		supplierID = ""
+	}
+
+	message, err := h.svc.Create(ctx, claims.UserID, supplierID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -113,11 +126,7 @@ func (h *Handler) MarkAsRead(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.MarkAsRead(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -132,11 +141,7 @@ func (h *Handler) Delete(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -1,19 +1,31 @@
 package message
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 // Message represents communication between users (buyer-supplier, etc.)
 type Message struct {
-	ID             string     `db:"id" json:"id"`
-	ConversationID string     `db:"conversation_id" json:"conversationId"`
-	SenderID       string     `db:"sender_id" json:"senderId"`
-	ReceiverID     string     `db:"receiver_id" json:"receiverId"`
-	Subject        string     `db:"subject" json:"subject"`
-	Body           string     `db:"body" json:"body"`
-	Attachments    string     `db:"attachments" json:"attachments"` // JSON array of URLs
-	Read           bool       `db:"read" json:"read"`
-	ReadAt         *time.Time `db:"read_at" json:"readAt,omitempty"`
-	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
+	ID             string `db:"id" json:"id"`
+	ConversationID string `db:"conversation_id" json:"conversationId"`
+	SenderID       string `db:"sender_id" json:"senderId"`
+	// SenderSupplierID is the supplier organization the sender wrote for, if
+	// they are a member of one.
+	SenderSupplierID string     `db:"sender_supplier_id" json:"senderSupplierId,omitempty"`
+	ReceiverID       string     `db:"receiver_id" json:"receiverId"`
+	Subject          string     `db:"subject" json:"subject"`
+	Body             string     `db:"body" json:"body"`
+	Attachments      string     `db:"attachments" json:"attachments"` // JSON array of URLs
+	Read             bool       `db:"read" json:"read"`
+	ReadAt           *time.Time `db:"read_at" json:"readAt,omitempty"`
+	CreatedAt        time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// Cursor implements pagination.Row.
+func (m *Message) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
 }
 
 type CreateMessageInput struct {
@@ -7,14 +7,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 var (
-	ErrNotFound = errors.New("message not found")
+	ErrNotFound = apperr.NotFound("message_not_found", "message not found")
 )
 
 type Repository interface {
-	ListByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error)
+	ListByConversationID(ctx context.Context, conversationID string, page pagination.Params) (*pagination.Page[*Message], error)
 	ListConversations(ctx context.Context, userID string) ([]*ConversationPreview, error)
 	GetByID(ctx context.Context, id string) (*Message, error)
 	Create(ctx context.Context, m *Message) error
@@ -30,15 +33,16 @@ func NewMySQLMessageRepository(db *sql.DB) Repository {
 	return &mySQLMessageRepository{db: db}
 }
 
-func (r *mySQLMessageRepository) ListByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error) {
-	const query = `
-SELECT id, conversation_id, sender_id, receiver_id, subject, body, attachments, ` + "`read`" + `, read_at, created_at
+func (r *mySQLMessageRepository) ListByConversationID(ctx context.Context, conversationID string, page pagination.Params) (*pagination.Page[*Message], error) {
+	query := `
+SELECT id, conversation_id, sender_id, COALESCE(sender_supplier_id, ''), receiver_id, subject, body,
+       attachments, ` + "`read`" + `, read_at, created_at
 FROM messages
-WHERE conversation_id = ?
-ORDER BY created_at DESC
+WHERE conversation_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, conversationID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(conversationID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +52,7 @@ LIMIT ? OFFSET ?`
 	for rows.Next() {
 		var m Message
 		if err := rows.Scan(
-			&m.ID, &m.ConversationID, &m.SenderID, &m.ReceiverID,
+			&m.ID, &m.ConversationID, &m.SenderID, &m.SenderSupplierID, &m.ReceiverID,
 			&m.Subject, &m.Body, &m.Attachments, &m.Read,
 			&m.ReadAt, &m.CreatedAt,
 		); err != nil {
@@ -56,7 +60,14 @@ LIMIT ? OFFSET ?`
 		}
 		messages = append(messages, &m)
 	}
-	return messages, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM messages WHERE conversation_id = ?", conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(messages, page, total), nil
 }
 
 func (r *mySQLMessageRepository) ListConversations(ctx context.Context, userID string) ([]*ConversationPreview, error) {
@@ -105,13 +116,14 @@ ORDER BY m.created_at DESC`
 
 func (r *mySQLMessageRepository) GetByID(ctx context.Context, id string) (*Message, error) {
 	const query = `
-SELECT id, conversation_id, sender_id, receiver_id, subject, body, attachments, ` + "`read`" + `, read_at, created_at
+SELECT id, conversation_id, sender_id, COALESCE(sender_supplier_id, ''), receiver_id, subject, body,
+       attachments, ` + "`read`" + `, read_at, created_at
 FROM messages
 WHERE id = ? LIMIT 1`
 
 	var m Message
 	if err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.ID, &m.ConversationID, &m.SenderID, &m.ReceiverID,
+		&m.ID, &m.ConversationID, &m.SenderID, &m.SenderSupplierID, &m.ReceiverID,
 		&m.Subject, &m.Body, &m.Attachments, &m.Read,
 		&m.ReadAt, &m.CreatedAt,
 	); err != nil {
@@ -131,11 +143,12 @@ func (r *mySQLMessageRepository) Create(ctx context.Context, m *Message) error {
 
 	const query = `
 INSERT INTO messages (
-	id, conversation_id, sender_id, receiver_id, subject, body, attachments, ` + "`read`" + `, read_at, created_at
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	id, conversation_id, sender_id, sender_supplier_id, receiver_id, subject, body, attachments,
+	` + "`read`" + `, read_at, created_at
+) VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.ExecContext(ctx, query,
-		m.ID, m.ConversationID, m.SenderID, m.ReceiverID,
+		m.ID, m.ConversationID, m.SenderID, m.SenderSupplierID, m.ReceiverID,
 		m.Subject, m.Body, m.Attachments, m.Read,
 		m.ReadAt, m.CreatedAt,
 	)
@@ -3,6 +3,9 @@ package message
 import (
 	"context"
 	"fmt"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
@@ -13,36 +16,43 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) ListByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 50
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListByConversationID(ctx, conversationID, limit, offset)
+func (s *Service) ListByConversationID(ctx context.Context, conversationID string, page pagination.Params) (*pagination.Page[*Message], error) {
+	ctx, span := tracing.Start(ctx, "message.Service.ListByConversationID")
+	defer span.End()
+
+	return s.repo.ListByConversationID(ctx, conversationID, page.Normalize(50))
 }
 
 func (s *Service) ListConversations(ctx context.Context, userID string) ([]*ConversationPreview, error) {
+	ctx, span := tracing.Start(ctx, "message.Service.ListConversations")
+	defer span.End()
 	return s.repo.ListConversations(ctx, userID)
 }
 
 func (s *Service) GetByID(ctx context.Context, id string) (*Message, error) {
+	ctx, span := tracing.Start(ctx, "message.Service.GetByID")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) Create(ctx context.Context, senderID string, in CreateMessageInput) (*Message, error) {
+// Create sends a message. senderSupplierID is the organization the sender
+// writes for, or empty.
+func (s *Service) Create(ctx context.Context, senderID, senderSupplierID string, in CreateMessageInput) (*Message, error) {
+	ctx, span := tracing.Start(ctx, "message.Service.Create")
+	defer span.End()
+
 	// Generate conversation ID (sorted user IDs to ensure consistency)
 	conversationID := generateConversationID(senderID, in.ReceiverID)
 
 	msg := &Message{
-		ConversationID: conversationID,
-		SenderID:       senderID,
-		ReceiverID:     in.ReceiverID,
-		Subject:        in.Subject,
-		Body:           in.Body,
-		Attachments:    in.Attachments,
-		Read:           false,
+		ConversationID:   conversationID,
+		SenderID:         senderID,
+		SenderSupplierID: senderSupplierID,
+		ReceiverID:       in.ReceiverID,
+		Subject:          in.Subject,
+		Body:             in.Body,
+		Attachments:      in.Attachments,
+		Read:             false,
 	}
 
 	if err := s.repo.Create(ctx, msg); err != nil {
@@ -52,10 +62,14 @@ func (s *Service) Create(ctx context.Context, senderID string, in CreateMessageI
 }
 
 func (s *Service) MarkAsRead(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "message.Service.MarkAsRead")
+	defer span.End()
 	return s.repo.MarkAsRead(ctx, id)
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "message.Service.Delete")
+	defer span.End()
 	return s.repo.Delete(ctx, id)
 }
 
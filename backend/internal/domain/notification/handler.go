@@ -3,12 +3,13 @@ package notification
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
@@ -23,24 +24,27 @@ func NewHandler(svc *Service) *Handler {
 func (h *Handler) GetMyNotifications(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, 50)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	notifications, err := h.svc.ListByUserID(ctx, claims.UserID, limit, offset)
+	notifications, err := h.svc.ListByUserID(ctx, claims.UserID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": notifications})
+	c.JSON(http.StatusOK, notifications)
 }
 
 // MarkAsRead marks a notification as read.
@@ -51,11 +55,7 @@ func (h *Handler) MarkAsRead(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.MarkAsRead(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -66,7 +66,7 @@ func (h *Handler) MarkAsRead(c *gin.Context) {
 func (h *Handler) MarkAllAsRead(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -75,7 +75,7 @@ func (h *Handler) MarkAllAsRead(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.MarkAllAsRead(ctx, claims.UserID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -90,11 +90,7 @@ func (h *Handler) Delete(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
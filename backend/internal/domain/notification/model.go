@@ -1,6 +1,10 @@
 package notification
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 type NotificationType string
 
@@ -37,6 +41,11 @@ type Notification struct {
 	ReadAt      *time.Time           `db:"read_at" json:"readAt,omitempty"`
 }
 
+// Cursor implements pagination.Row.
+func (n *Notification) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
 type CreateNotificationInput struct {
 	UserID      string               `json:"userId" binding:"required"`
 	Type        NotificationType     `json:"type" binding:"required"`
@@ -7,14 +7,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 var (
-	ErrNotFound = errors.New("notification not found")
+	ErrNotFound = apperr.NotFound("notification_not_found", "notification not found")
 )
 
 type Repository interface {
-	ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*Notification, error)
+	ListByUserID(ctx context.Context, userID string, page pagination.Params) (*pagination.Page[*Notification], error)
 	GetByID(ctx context.Context, id string) (*Notification, error)
 	Create(ctx context.Context, n *Notification) error
 	MarkAsRead(ctx context.Context, id string) error
@@ -30,15 +33,15 @@ func NewMySQLNotificationRepository(db *sql.DB) Repository {
 	return &mySQLNotificationRepository{db: db}
 }
 
-func (r *mySQLNotificationRepository) ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*Notification, error) {
-	const query = "SELECT id, user_id, type, priority, title, description, icon, action_url, action_label, " +
+func (r *mySQLNotificationRepository) ListByUserID(ctx context.Context, userID string, page pagination.Params) (*pagination.Page[*Notification], error) {
+	query := "SELECT id, user_id, type, priority, title, description, icon, action_url, action_label, " +
 		"`read`, metadata, created_at, read_at " +
 		"FROM notifications " +
-		"WHERE user_id = ? " +
-		"ORDER BY created_at DESC " +
+		"WHERE user_id = ? AND " + page.Where("created_at", "id") + " " +
+		"ORDER BY created_at DESC, id DESC " +
 		"LIMIT ? OFFSET ?"
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(userID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +59,14 @@ func (r *mySQLNotificationRepository) ListByUserID(ctx context.Context, userID s
 		}
 		notifications = append(notifications, &n)
 	}
-	return notifications, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM notifications WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(notifications, page, total), nil
 }
 
 func (r *mySQLNotificationRepository) GetByID(ctx context.Context, id string) (*Notification, error) {
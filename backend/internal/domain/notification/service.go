@@ -2,6 +2,9 @@ package notification
 
 import (
 	"context"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
@@ -12,17 +15,17 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*Notification, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 50
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListByUserID(ctx, userID, limit, offset)
+func (s *Service) ListByUserID(ctx context.Context, userID string, page pagination.Params) (*pagination.Page[*Notification], error) {
+	ctx, span := tracing.Start(ctx, "notification.Service.ListByUserID")
+	defer span.End()
+
+	return s.repo.ListByUserID(ctx, userID, page.Normalize(50))
 }
 
 func (s *Service) Create(ctx context.Context, in CreateNotificationInput) (*Notification, error) {
+	ctx, span := tracing.Start(ctx, "notification.Service.Create")
+	defer span.End()
+
 	n := &Notification{
 		UserID:      in.UserID,
 		Type:        in.Type,
@@ -43,13 +46,19 @@ func (s *Service) Create(ctx context.Context, in CreateNotificationInput) (*Noti
 }
 
 func (s *Service) MarkAsRead(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "notification.Service.MarkAsRead")
+	defer span.End()
 	return s.repo.MarkAsRead(ctx, id)
 }
 
 func (s *Service) MarkAllAsRead(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "notification.Service.MarkAllAsRead")
+	defer span.End()
 	return s.repo.MarkAllAsRead(ctx, userID)
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "notification.Service.Delete")
+	defer span.End()
 	return s.repo.Delete(ctx, id)
 }
@@ -3,97 +3,131 @@ package order
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
-	svc *Service
+	svc       *Service
+	suppliers authz.SupplierResolver
 }
 
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *Service, suppliers authz.SupplierResolver) *Handler {
+	return &Handler{svc: svc, suppliers: suppliers}
+}
+
+// canView reports whether the caller may see o: its buyer, a member of its
+// supplier organization, or anyone who may manage every order.
+func (h *Handler) canView(ctx context.Context, claims *middleware.Claims, o *Order) (bool, error) {
+	if authz.Allowed(claims, authz.OrdersManage, o.BuyerID) {
+		return true, nil
+	}
+	return authz.ActsForSupplier(ctx, h.suppliers, claims, o.SupplierID, authz.OrdersView)
 }
 
 // List returns all orders (admin only).
 func (h *Handler) List(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	orders, err := h.svc.List(ctx, limit, offset)
+	orders, err := h.svc.List(ctx, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": orders})
+	c.JSON(http.StatusOK, orders)
 }
 
 // GetMyOrders returns orders for the authenticated buyer.
 func (h *Handler) GetMyOrders(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	orders, err := h.svc.ListByBuyerID(ctx, claims.UserID, limit, offset)
+	orders, err := h.svc.ListByBuyerID(ctx, claims.UserID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": orders})
+	c.JSON(http.StatusOK, orders)
 }
 
 // GetSupplierOrders returns orders for a supplier.
 func (h *Handler) GetSupplierOrders(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	// Ensure user is supplier or admin
-	if claims.Role != string(auth.RoleSupplier) && claims.Role != string(auth.RoleAdmin) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
-
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	supplierID := c.Param("supplierId")
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	orders, err := h.svc.ListBySupplierID(ctx, supplierID, limit, offset)
+	// Members only see their organization's orders; admins see any supplier's.
+	if !authz.Can(claims.Role, authz.OrdersManage) {
+		owns, err := authz.ActsForSupplier(ctx, h.suppliers, claims, supplierID, authz.OrdersView)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if !owns {
+			_ = c.Error(authz.ErrAccessDenied)
+			return
+		}
+	}
+
+	orders, err := h.svc.ListBySupplierID(ctx, supplierID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": orders})
+	c.JSON(http.StatusOK, orders)
 }
 
-// GetByID returns a single order.
+// GetByID returns a single order to its buyer, its supplier's members or an
+// admin.
 func (h *Handler) GetByID(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
 	id := c.Param("id")
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
@@ -101,11 +135,18 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	order, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
+		return
+	}
+
+	allowed, err := h.canView(ctx, claims, order)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if !allowed {
+		// Do not reveal that someone else's order exists.
+		_ = c.Error(ErrNotFound)
 		return
 	}
 
@@ -116,13 +157,13 @@ func (h *Handler) GetByID(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateOrderInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -132,32 +173,51 @@ func (h *Handler) Create(c *gin.Context) {
 
 	order, err := h.svc.Create(ctx, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, order)
 }
 
-// UpdateStatus updates the order status.
+// UpdateStatus updates the order status (the order's supplier or an admin).
 func (h *Handler) UpdateStatus(c *gin.Context) {
 	var in UpdateOrderStatusInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
+	claims := raw.(*middleware.Claims)
 	id := c.Param("id")
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	order, err := h.svc.UpdateStatus(ctx, id, in)
+	existing, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		_ = c.Error(err)
+		return
+	}
+	allowed := authz.Can(claims.Role, authz.OrdersManage)
+	if !allowed {
+		if allowed, err = authz.ActsForSupplier(ctx, h.suppliers, claims, existing.SupplierID, authz.OrdersUpdateStatus); err != nil {
+			_ = c.Error(err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	if !allowed {
+		_ = c.Error(authz.ErrAccessDenied)
+		return
+	}
+
+	order, err := h.svc.UpdateStatus(ctx, id, claims.UserID, in)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -172,11 +232,7 @@ func (h *Handler) Delete(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -1,6 +1,10 @@
 package order
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 type OrderStatus string
 
@@ -42,10 +46,16 @@ type Order struct {
 	TrackingNumber   string        `db:"tracking_number" json:"trackingNumber"`
 	EstimatedDelivery time.Time    `db:"estimated_delivery" json:"estimatedDelivery"`
 	DeliveredAt      *time.Time    `db:"delivered_at" json:"deliveredAt,omitempty"`
+	StatusUpdatedBy  string        `db:"status_updated_by" json:"statusUpdatedBy,omitempty"` // member who last changed the status
 	CreatedAt        time.Time     `db:"created_at" json:"createdAt"`
 	UpdatedAt        time.Time     `db:"updated_at" json:"updatedAt"`
 }
 
+// Cursor implements pagination.Row.
+func (o *Order) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+}
+
 type CreateOrderInput struct {
 	ProductID        string  `json:"productId" binding:"required"`
 	SupplierID       string  `json:"supplierId" binding:"required"`
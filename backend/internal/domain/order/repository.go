@@ -7,16 +7,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 var (
-	ErrNotFound = errors.New("order not found")
+	ErrNotFound = apperr.NotFound("order_not_found", "order not found")
 )
 
 type Repository interface {
-	List(ctx context.Context, limit, offset int) ([]*Order, error)
-	ListByBuyerID(ctx context.Context, buyerID string, limit, offset int) ([]*Order, error)
-	ListBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*Order, error)
+	List(ctx context.Context, page pagination.Params) (*pagination.Page[*Order], error)
+	ListByBuyerID(ctx context.Context, buyerID string, page pagination.Params) (*pagination.Page[*Order], error)
+	ListBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*Order], error)
 	GetByID(ctx context.Context, id string) (*Order, error)
 	GetByOrderNumber(ctx context.Context, orderNumber string) (*Order, error)
 	Create(ctx context.Context, o *Order) error
@@ -32,16 +35,18 @@ func NewMySQLOrderRepository(db *sql.DB) Repository {
 	return &mySQLOrderRepository{db: db}
 }
 
-func (r *mySQLOrderRepository) List(ctx context.Context, limit, offset int) ([]*Order, error) {
-	const query = `
+func (r *mySQLOrderRepository) List(ctx context.Context, page pagination.Params) (*pagination.Page[*Order], error) {
+	query := `
 SELECT id, order_number, buyer_id, supplier_id, product_id, quantity, unit_price, 
        total_amount, currency, status, payment_status, payment_method, shipping_address, 
-       shipping_method, tracking_number, estimated_delivery, delivered_at, created_at, updated_at
+       shipping_method, tracking_number, estimated_delivery, delivered_at,
+       COALESCE(status_updated_by, ''), created_at, updated_at
 FROM orders
-ORDER BY created_at DESC
+WHERE ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,26 +59,34 @@ LIMIT ? OFFSET ?`
 			&o.ID, &o.OrderNumber, &o.BuyerID, &o.SupplierID, &o.ProductID, &o.Quantity,
 			&o.UnitPrice, &o.TotalAmount, &o.Currency, &o.Status, &o.PaymentStatus,
 			&o.PaymentMethod, &o.ShippingAddress, &o.ShippingMethod, &o.TrackingNumber,
-			&o.EstimatedDelivery, &o.DeliveredAt, &o.CreatedAt, &o.UpdatedAt,
+			&o.EstimatedDelivery, &o.DeliveredAt, &o.StatusUpdatedBy, &o.CreatedAt, &o.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
 		orders = append(orders, &o)
 	}
-	return orders, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM orders")
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(orders, page, total), nil
 }
 
-func (r *mySQLOrderRepository) ListByBuyerID(ctx context.Context, buyerID string, limit, offset int) ([]*Order, error) {
-	const query = `
+func (r *mySQLOrderRepository) ListByBuyerID(ctx context.Context, buyerID string, page pagination.Params) (*pagination.Page[*Order], error) {
+	query := `
 SELECT id, order_number, buyer_id, supplier_id, product_id, quantity, unit_price, 
        total_amount, currency, status, payment_status, payment_method, shipping_address, 
-       shipping_method, tracking_number, estimated_delivery, delivered_at, created_at, updated_at
+       shipping_method, tracking_number, estimated_delivery, delivered_at,
+       COALESCE(status_updated_by, ''), created_at, updated_at
 FROM orders
-WHERE buyer_id = ?
-ORDER BY created_at DESC
+WHERE buyer_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, buyerID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(buyerID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -86,26 +99,34 @@ LIMIT ? OFFSET ?`
 			&o.ID, &o.OrderNumber, &o.BuyerID, &o.SupplierID, &o.ProductID, &o.Quantity,
 			&o.UnitPrice, &o.TotalAmount, &o.Currency, &o.Status, &o.PaymentStatus,
 			&o.PaymentMethod, &o.ShippingAddress, &o.ShippingMethod, &o.TrackingNumber,
-			&o.EstimatedDelivery, &o.DeliveredAt, &o.CreatedAt, &o.UpdatedAt,
+			&o.EstimatedDelivery, &o.DeliveredAt, &o.StatusUpdatedBy, &o.CreatedAt, &o.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
 		orders = append(orders, &o)
 	}
-	return orders, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM orders WHERE buyer_id = ?", buyerID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(orders, page, total), nil
 }
 
-func (r *mySQLOrderRepository) ListBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*Order, error) {
-	const query = `
+func (r *mySQLOrderRepository) ListBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*Order], error) {
+	query := `
 SELECT id, order_number, buyer_id, supplier_id, product_id, quantity, unit_price, 
        total_amount, currency, status, payment_status, payment_method, shipping_address, 
-       shipping_method, tracking_number, estimated_delivery, delivered_at, created_at, updated_at
+       shipping_method, tracking_number, estimated_delivery, delivered_at,
+       COALESCE(status_updated_by, ''), created_at, updated_at
 FROM orders
-WHERE supplier_id = ?
-ORDER BY created_at DESC
+WHERE supplier_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, supplierID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(supplierID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -118,20 +139,28 @@ LIMIT ? OFFSET ?`
 			&o.ID, &o.OrderNumber, &o.BuyerID, &o.SupplierID, &o.ProductID, &o.Quantity,
 			&o.UnitPrice, &o.TotalAmount, &o.Currency, &o.Status, &o.PaymentStatus,
 			&o.PaymentMethod, &o.ShippingAddress, &o.ShippingMethod, &o.TrackingNumber,
-			&o.EstimatedDelivery, &o.DeliveredAt, &o.CreatedAt, &o.UpdatedAt,
+			&o.EstimatedDelivery, &o.DeliveredAt, &o.StatusUpdatedBy, &o.CreatedAt, &o.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
 		orders = append(orders, &o)
 	}
-	return orders, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM orders WHERE supplier_id = ?", supplierID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(orders, page, total), nil
 }
 
 func (r *mySQLOrderRepository) GetByID(ctx context.Context, id string) (*Order, error) {
 	const query = `
 SELECT id, order_number, buyer_id, supplier_id, product_id, quantity, unit_price, 
        total_amount, currency, status, payment_status, payment_method, shipping_address, 
-       shipping_method, tracking_number, estimated_delivery, delivered_at, created_at, updated_at
+       shipping_method, tracking_number, estimated_delivery, delivered_at,
+       COALESCE(status_updated_by, ''), created_at, updated_at
 FROM orders
 WHERE id = ? LIMIT 1`
 
@@ -140,7 +169,7 @@ WHERE id = ? LIMIT 1`
 		&o.ID, &o.OrderNumber, &o.BuyerID, &o.SupplierID, &o.ProductID, &o.Quantity,
 		&o.UnitPrice, &o.TotalAmount, &o.Currency, &o.Status, &o.PaymentStatus,
 		&o.PaymentMethod, &o.ShippingAddress, &o.ShippingMethod, &o.TrackingNumber,
-		&o.EstimatedDelivery, &o.DeliveredAt, &o.CreatedAt, &o.UpdatedAt,
+		&o.EstimatedDelivery, &o.DeliveredAt, &o.StatusUpdatedBy, &o.CreatedAt, &o.UpdatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -154,7 +183,8 @@ func (r *mySQLOrderRepository) GetByOrderNumber(ctx context.Context, orderNumber
 	const query = `
 SELECT id, order_number, buyer_id, supplier_id, product_id, quantity, unit_price, 
        total_amount, currency, status, payment_status, payment_method, shipping_address, 
-       shipping_method, tracking_number, estimated_delivery, delivered_at, created_at, updated_at
+       shipping_method, tracking_number, estimated_delivery, delivered_at,
+       COALESCE(status_updated_by, ''), created_at, updated_at
 FROM orders
 WHERE order_number = ? LIMIT 1`
 
@@ -163,7 +193,7 @@ WHERE order_number = ? LIMIT 1`
 		&o.ID, &o.OrderNumber, &o.BuyerID, &o.SupplierID, &o.ProductID, &o.Quantity,
 		&o.UnitPrice, &o.TotalAmount, &o.Currency, &o.Status, &o.PaymentStatus,
 		&o.PaymentMethod, &o.ShippingAddress, &o.ShippingMethod, &o.TrackingNumber,
-		&o.EstimatedDelivery, &o.DeliveredAt, &o.CreatedAt, &o.UpdatedAt,
+		&o.EstimatedDelivery, &o.DeliveredAt, &o.StatusUpdatedBy, &o.CreatedAt, &o.UpdatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -203,12 +233,12 @@ func (r *mySQLOrderRepository) Update(ctx context.Context, o *Order) error {
 	const query = `
 UPDATE orders
 SET status = ?, payment_status = ?, shipping_method = ?, tracking_number = ?, 
-    estimated_delivery = ?, delivered_at = ?, updated_at = ?
+    estimated_delivery = ?, delivered_at = ?, status_updated_by = NULLIF(?, ''), updated_at = ?
 WHERE id = ?`
 
 	res, err := r.db.ExecContext(ctx, query,
 		o.Status, o.PaymentStatus, o.ShippingMethod, o.TrackingNumber,
-		o.EstimatedDelivery, o.DeliveredAt, o.UpdatedAt,
+		o.EstimatedDelivery, o.DeliveredAt, o.StatusUpdatedBy, o.UpdatedAt,
 		o.ID,
 	)
 	if err != nil {
@@ -4,51 +4,57 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/metrics"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
-	repo Repository
+	repo    Repository
+	metrics *metrics.Metrics
 }
 
 func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) List(ctx context.Context, limit, offset int) ([]*Order, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.List(ctx, limit, offset)
+// WithMetrics counts created orders in m.
+func (s *Service) WithMetrics(m *metrics.Metrics) {
+	s.metrics = m
 }
 
-func (s *Service) ListByBuyerID(ctx context.Context, buyerID string, limit, offset int) ([]*Order, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListByBuyerID(ctx, buyerID, limit, offset)
+func (s *Service) List(ctx context.Context, page pagination.Params) (*pagination.Page[*Order], error) {
+	ctx, span := tracing.Start(ctx, "order.Service.List")
+	defer span.End()
+
+	return s.repo.List(ctx, page.Normalize(pagination.DefaultLimit))
 }
 
-func (s *Service) ListBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*Order, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListBySupplierID(ctx, supplierID, limit, offset)
+func (s *Service) ListByBuyerID(ctx context.Context, buyerID string, page pagination.Params) (*pagination.Page[*Order], error) {
+	ctx, span := tracing.Start(ctx, "order.Service.ListByBuyerID")
+	defer span.End()
+
+	return s.repo.ListByBuyerID(ctx, buyerID, page.Normalize(pagination.DefaultLimit))
+}
+
+func (s *Service) ListBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*Order], error) {
+	ctx, span := tracing.Start(ctx, "order.Service.ListBySupplierID")
+	defer span.End()
+
+	return s.repo.ListBySupplierID(ctx, supplierID, page.Normalize(pagination.DefaultLimit))
 }
 
 func (s *Service) GetByID(ctx context.Context, id string) (*Order, error) {
+	ctx, span := tracing.Start(ctx, "order.Service.GetByID")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
 func (s *Service) Create(ctx context.Context, buyerID string, in CreateOrderInput) (*Order, error) {
+	ctx, span := tracing.Start(ctx, "order.Service.Create")
+	defer span.End()
+
 	totalAmount := float64(in.Quantity) * in.UnitPrice
 
 	// Generate order number
@@ -77,16 +83,22 @@ func (s *Service) Create(ctx context.Context, buyerID string, in CreateOrderInpu
 	if err := s.repo.Create(ctx, order); err != nil {
 		return nil, err
 	}
+	s.metrics.OrderCreated()
 	return order, nil
 }
 
-func (s *Service) UpdateStatus(ctx context.Context, id string, in UpdateOrderStatusInput) (*Order, error) {
+// UpdateStatus changes the order status; updatedBy is the acting user.
+func (s *Service) UpdateStatus(ctx context.Context, id, updatedBy string, in UpdateOrderStatusInput) (*Order, error) {
+	ctx, span := tracing.Start(ctx, "order.Service.UpdateStatus")
+	defer span.End()
+
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	order.Status = in.Status
+	order.StatusUpdatedBy = updatedBy
 	if in.TrackingNumber != nil {
 		order.TrackingNumber = *in.TrackingNumber
 	}
@@ -101,5 +113,7 @@ func (s *Service) UpdateStatus(ctx context.Context, id string, in UpdateOrderSta
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "order.Service.Delete")
+	defer span.End()
 	return s.repo.Delete(ctx, id)
 }
@@ -3,38 +3,44 @@ package product
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 // Handler exposes product HTTP endpoints.
 type Handler struct {
-	svc *Service
+	svc       *Service
+	suppliers authz.SupplierResolver
 }
 
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *Service, suppliers authz.SupplierResolver) *Handler {
+	return &Handler{svc: svc, suppliers: suppliers}
 }
 
 func (h *Handler) List(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	products, err := h.svc.List(ctx, limit, offset)
+	products, err := h.svc.List(ctx, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": products})
+	c.JSON(http.StatusOK, products)
 }
 
 func (h *Handler) GetByID(c *gin.Context) {
@@ -45,67 +51,108 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	p, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	httpcache.SetLastModified(c, p.UpdatedAt)
 	c.JSON(http.StatusOK, p)
 }
 
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	// Only supplier or admin can create products.
-	if claims.Role != string(auth.RoleSupplier) && claims.Role != string(auth.RoleAdmin) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only suppliers or admins can create products"})
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	p, err := h.svc.Create(ctx, claims.UserID, in)
+	supplierID, role, err := h.suppliers.SupplierMembership(ctx, claims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	switch {
+	case supplierID != "":
+		if !authz.MemberCan(role, authz.ProductsWrite) {
+			_ = c.Error(authz.ErrTeamRoleForbidden)
+			return
+		}
+	case authz.Can(claims.Role, authz.ProductsManage):
+		// Staff without an organization list products under their own ID.
+		supplierID = claims.UserID
+	default:
+		_ = c.Error(authz.ErrSupplierProfileRequired)
+		return
+	}
+
+	p, err := h.svc.Create(ctx, supplierID, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, p)
 }
 
+// authorizeOwner loads product id and checks that the caller is a member of
+// the owning supplier organization whose team role may edit products, or that
+// they may manage all products. It writes the error response when it fails.
+func (h *Handler) authorizeOwner(ctx context.Context, c *gin.Context, claims *middleware.Claims, id string) bool {
+	p, err := h.svc.GetByID(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+	if authz.Can(claims.Role, authz.ProductsManage) {
+		return true
+	}
+	owns, err := authz.ActsForSupplier(ctx, h.suppliers, claims, p.SupplierID, authz.ProductsWrite)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+	if !owns {
+		_ = c.Error(authz.ErrAccessDenied)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) Update(c *gin.Context) {
 	var in UpdateInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 	id := c.Param("id")
 
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	p, err := h.svc.Update(ctx, id, in)
+	if !h.authorizeOwner(ctx, c, claims, id) {
+		return
+	}
+
+	p, err := h.svc.Update(ctx, id, claims.UserID, in)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -115,15 +162,22 @@ func (h *Handler) Update(c *gin.Context) {
 func (h *Handler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
+	if !h.authorizeOwner(ctx, c, claims, id) {
+		return
+	}
+
 	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
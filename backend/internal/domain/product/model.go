@@ -1,6 +1,10 @@
 package product
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 // Product represents a simplified product entity backing the frontend catalogue.
 type Product struct {
@@ -11,11 +15,18 @@ type Product struct {
 	Price       float64   `db:"price" json:"price"`
 	MOQ         int       `db:"moq" json:"moq"`
 	Currency    string    `db:"currency" json:"currency"`
-	SupplierID  string    `db:"supplier_id" json:"supplierId"`
+	SupplierID  string    `db:"supplier_id" json:"supplierId"` // supplier organization
+	CreatedBy   string    `db:"created_by" json:"createdBy,omitempty"`
+	UpdatedBy   string    `db:"updated_by" json:"updatedBy,omitempty"`
 	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
 }
 
+// Cursor implements pagination.Row.
+func (p *Product) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
 type CreateInput struct {
 	Name        string  `json:"name" binding:"required"`
 	Description string  `json:"description" binding:"required"`
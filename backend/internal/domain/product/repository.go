@@ -7,14 +7,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 var (
-	ErrNotFound = errors.New("product not found")
+	ErrNotFound = apperr.NotFound("product_not_found", "product not found")
 )
 
 type Repository interface {
-	List(ctx context.Context, limit, offset int) ([]*Product, error)
+	List(ctx context.Context, page pagination.Params) (*pagination.Page[*Product], error)
 	GetByID(ctx context.Context, id string) (*Product, error)
 	Create(ctx context.Context, p *Product) error
 	Update(ctx context.Context, p *Product) error
@@ -29,14 +32,16 @@ func NewMySQLProductRepository(db *sql.DB) Repository {
 	return &mySQLProductRepository{db: db}
 }
 
-func (r *mySQLProductRepository) List(ctx context.Context, limit, offset int) ([]*Product, error) {
-	const query = `
-SELECT id, name, description, image_url, price, moq, currency, supplier_id, created_at, updated_at
+func (r *mySQLProductRepository) List(ctx context.Context, page pagination.Params) (*pagination.Page[*Product], error) {
+	query := `
+SELECT id, name, description, image_url, price, moq, currency, supplier_id,
+       COALESCE(created_by, ''), COALESCE(updated_by, ''), created_at, updated_at
 FROM products
-ORDER BY created_at DESC
+WHERE ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +59,8 @@ LIMIT ? OFFSET ?`
 			&p.MOQ,
 			&p.Currency,
 			&p.SupplierID,
+			&p.CreatedBy,
+			&p.UpdatedBy,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
@@ -61,12 +68,20 @@ LIMIT ? OFFSET ?`
 		}
 		products = append(products, &p)
 	}
-	return products, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM products")
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(products, page, total), nil
 }
 
 func (r *mySQLProductRepository) GetByID(ctx context.Context, id string) (*Product, error) {
 	const query = `
-SELECT id, name, description, image_url, price, moq, currency, supplier_id, created_at, updated_at
+SELECT id, name, description, image_url, price, moq, currency, supplier_id,
+       COALESCE(created_by, ''), COALESCE(updated_by, ''), created_at, updated_at
 FROM products
 WHERE id = ? LIMIT 1`
 
@@ -80,6 +95,8 @@ WHERE id = ? LIMIT 1`
 		&p.MOQ,
 		&p.Currency,
 		&p.SupplierID,
+		&p.CreatedBy,
+		&p.UpdatedBy,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	); err != nil {
@@ -100,8 +117,8 @@ func (r *mySQLProductRepository) Create(ctx context.Context, p *Product) error {
 	p.UpdatedAt = now
 
 	const query = `
-INSERT INTO products (id, name, description, image_url, price, moq, currency, supplier_id, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+INSERT INTO products (id, name, description, image_url, price, moq, currency, supplier_id, created_by, updated_by, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		p.ID,
@@ -112,6 +129,8 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 		p.MOQ,
 		p.Currency,
 		p.SupplierID,
+		p.CreatedBy,
+		p.UpdatedBy,
 		p.CreatedAt,
 		p.UpdatedAt,
 	)
@@ -123,7 +142,7 @@ func (r *mySQLProductRepository) Update(ctx context.Context, p *Product) error {
 
 	const query = `
 UPDATE products
-SET name = ?, description = ?, image_url = ?, price = ?, moq = ?, currency = ?, updated_at = ?
+SET name = ?, description = ?, image_url = ?, price = ?, moq = ?, currency = ?, updated_by = ?, updated_at = ?
 WHERE id = ?`
 
 	res, err := r.db.ExecContext(ctx, query,
@@ -133,6 +152,7 @@ WHERE id = ?`
 		p.Price,
 		p.MOQ,
 		p.Currency,
+		p.UpdatedBy,
 		p.UpdatedAt,
 		p.ID,
 	)
@@ -2,32 +2,47 @@ package product
 
 import (
 	"context"
+
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 // Service contains product-related business logic (validation, access rules).
 type Service struct {
-	repo Repository
+	repo  Repository
+	cache *httpcache.Cache
 }
 
 func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) List(ctx context.Context, limit, offset int) ([]*Product, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.List(ctx, limit, offset)
+// WithCache sets the response cache whose copies of a product are dropped
+// when it changes.
+func (s *Service) WithCache(cache *httpcache.Cache) {
+	s.cache = cache
+}
+
+func (s *Service) List(ctx context.Context, page pagination.Params) (*pagination.Page[*Product], error) {
+	ctx, span := tracing.Start(ctx, "product.Service.List")
+	defer span.End()
+
+	return s.repo.List(ctx, page.Normalize(pagination.DefaultLimit))
 }
 
 func (s *Service) GetByID(ctx context.Context, id string) (*Product, error) {
+	ctx, span := tracing.Start(ctx, "product.Service.GetByID")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) Create(ctx context.Context, supplierID string, in CreateInput) (*Product, error) {
+// Create adds a product to supplierID's catalogue; createdBy is the acting
+// member.
+func (s *Service) Create(ctx context.Context, supplierID, createdBy string, in CreateInput) (*Product, error) {
+	ctx, span := tracing.Start(ctx, "product.Service.Create")
+	defer span.End()
+
 	p := &Product{
 		Name:        in.Name,
 		Description: in.Description,
@@ -36,6 +51,8 @@ func (s *Service) Create(ctx context.Context, supplierID string, in CreateInput)
 		MOQ:         in.MOQ,
 		Currency:    in.Currency,
 		SupplierID:  supplierID,
+		CreatedBy:   createdBy,
+		UpdatedBy:   createdBy,
 	}
 	if err := s.repo.Create(ctx, p); err != nil {
 		return nil, err
@@ -43,7 +60,10 @@ func (s *Service) Create(ctx context.Context, supplierID string, in CreateInput)
 	return p, nil
 }
 
-func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*Product, error) {
+func (s *Service) Update(ctx context.Context, id, updatedBy string, in UpdateInput) (*Product, error) {
+	ctx, span := tracing.Start(ctx, "product.Service.Update")
+	defer span.End()
+
 	p, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -67,14 +87,23 @@ func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*Produ
 	if in.Currency != nil {
 		p.Currency = *in.Currency
 	}
+	p.UpdatedBy = updatedBy
 
 	if err := s.repo.Update(ctx, p); err != nil {
 		return nil, err
 	}
+	s.cache.Invalidate("product:" + id)
 	return p, nil
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	ctx, span := tracing.Start(ctx, "product.Service.Delete")
+	defer span.End()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Invalidate("product:" + id)
+	return nil
 }
 
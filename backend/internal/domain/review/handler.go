@@ -3,12 +3,13 @@ package review
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
@@ -22,41 +23,41 @@ func NewHandler(svc *Service) *Handler {
 // ListByProduct returns reviews for a product (public). Route: GET /products/:id/reviews
 func (h *Handler) ListByProduct(c *gin.Context) {
 	productID := c.Param("id")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	list, err := h.svc.ListByProductID(ctx, productID, limit, offset)
+	list, err := h.svc.ListByProductID(ctx, productID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
-	if list == nil {
-		list = []*Review{}
-	}
-	c.JSON(http.StatusOK, gin.H{"items": list})
+	c.JSON(http.StatusOK, list)
 }
 
 // ListBySupplier returns reviews for a supplier (public). Route: GET /suppliers/:id/reviews
 func (h *Handler) ListBySupplier(c *gin.Context) {
 	supplierID := c.Param("id")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	list, err := h.svc.ListBySupplierID(ctx, supplierID, limit, offset)
+	list, err := h.svc.ListBySupplierID(ctx, supplierID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
-	if list == nil {
-		list = []*Review{}
-	}
-	c.JSON(http.StatusOK, gin.H{"items": list})
+	c.JSON(http.StatusOK, list)
 }
 
 // CreateInput for creating a review.
@@ -73,18 +74,18 @@ type CreateInput struct {
 func (h *Handler) Create(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	var in CreateInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 	if in.ProductID == "" && in.SupplierID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "productId or supplierId required"})
+		_ = c.Error(apperr.Validation("productId or supplierId required", apperr.FieldError{Field: "productId", Code: "required_without", Param: "supplierId"}))
 		return
 	}
 
@@ -93,7 +94,7 @@ func (h *Handler) Create(c *gin.Context) {
 
 	rev, err := h.svc.Create(ctx, claims.UserID, in.ProductID, in.SupplierID, in.Rating, in.Title, in.Comment, in.VerifiedPurchase)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, rev)
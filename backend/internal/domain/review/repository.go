@@ -3,17 +3,19 @@ package review
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
-var ErrNotFound = errors.New("review not found")
+var ErrNotFound = apperr.NotFound("review_not_found", "review not found")
 
 type Repository interface {
-	ListByProductID(ctx context.Context, productID string, limit, offset int) ([]*Review, error)
-	ListBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*Review, error)
+	ListByProductID(ctx context.Context, productID string, page pagination.Params) (*pagination.Page[*Review], error)
+	ListBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*Review], error)
 	Create(ctx context.Context, r *Review) error
 }
 
@@ -25,15 +27,15 @@ func NewMySQLReviewRepository(db *sql.DB) Repository {
 	return &mySQLReviewRepository{db: db}
 }
 
-func (r *mySQLReviewRepository) ListByProductID(ctx context.Context, productID string, limit, offset int) ([]*Review, error) {
-	const query = `
+func (r *mySQLReviewRepository) ListByProductID(ctx context.Context, productID string, page pagination.Params) (*pagination.Page[*Review], error) {
+	query := `
 SELECT id, product_id, supplier_id, reviewer_id, rating, title, comment, verified_purchase, helpful_count, created_at, updated_at
 FROM reviews
-WHERE product_id = ?
-ORDER BY created_at DESC
+WHERE product_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, productID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(productID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -50,18 +52,25 @@ LIMIT ? OFFSET ?`
 		}
 		list = append(list, &rev)
 	}
-	return list, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM reviews WHERE product_id = ?", productID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(list, page, total), nil
 }
 
-func (r *mySQLReviewRepository) ListBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*Review, error) {
-	const query = `
+func (r *mySQLReviewRepository) ListBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*Review], error) {
+	query := `
 SELECT id, product_id, supplier_id, reviewer_id, rating, title, comment, verified_purchase, helpful_count, created_at, updated_at
 FROM reviews
-WHERE supplier_id = ?
-ORDER BY created_at DESC
+WHERE supplier_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, supplierID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(supplierID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +87,14 @@ LIMIT ? OFFSET ?`
 		}
 		list = append(list, &rev)
 	}
-	return list, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM reviews WHERE supplier_id = ?", supplierID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(list, page, total), nil
 }
 
 func (r *mySQLReviewRepository) Create(ctx context.Context, rev *Review) error {
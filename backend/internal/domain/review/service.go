@@ -1,6 +1,11 @@
 package review
 
-import "context"
+import (
+	"context"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
 
 type Service struct {
 	repo Repository
@@ -10,27 +15,24 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) ListByProductID(ctx context.Context, productID string, limit, offset int) ([]*Review, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListByProductID(ctx, productID, limit, offset)
+func (s *Service) ListByProductID(ctx context.Context, productID string, page pagination.Params) (*pagination.Page[*Review], error) {
+	ctx, span := tracing.Start(ctx, "review.Service.ListByProductID")
+	defer span.End()
+
+	return s.repo.ListByProductID(ctx, productID, page.Normalize(pagination.DefaultLimit))
 }
 
-func (s *Service) ListBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*Review, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListBySupplierID(ctx, supplierID, limit, offset)
+func (s *Service) ListBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*Review], error) {
+	ctx, span := tracing.Start(ctx, "review.Service.ListBySupplierID")
+	defer span.End()
+
+	return s.repo.ListBySupplierID(ctx, supplierID, page.Normalize(pagination.DefaultLimit))
 }
 
 func (s *Service) Create(ctx context.Context, reviewerID, productID, supplierID string, rating int, title, comment string, verifiedPurchase bool) (*Review, error) {
+	ctx, span := tracing.Start(ctx, "review.Service.Create")
+	defer span.End()
+
 	if rating < 1 || rating > 5 {
 		rating = 5
 	}
@@ -3,61 +3,70 @@ package rfq
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
-	svc *Service
+	svc       *Service
+	suppliers authz.SupplierResolver
 }
 
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *Service, suppliers authz.SupplierResolver) *Handler {
+	return &Handler{svc: svc, suppliers: suppliers}
 }
 
 // ListRFQs returns all RFQs (admin endpoint).
 func (h *Handler) ListRFQs(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	rfqs, err := h.svc.ListRFQs(ctx, limit, offset)
+	rfqs, err := h.svc.ListRFQs(ctx, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": rfqs})
+	c.JSON(http.StatusOK, rfqs)
 }
 
 // GetMyRFQs returns RFQs for the authenticated buyer.
 func (h *Handler) GetMyRFQs(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	rfqs, err := h.svc.ListMyRFQs(ctx, claims.UserID, limit, offset)
+	rfqs, err := h.svc.ListMyRFQs(ctx, claims.UserID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": rfqs})
+	c.JSON(http.StatusOK, rfqs)
 }
 
 // GetByID returns a single RFQ.
@@ -69,11 +78,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	rfq, err := h.svc.GetRFQByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "rfq not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -84,13 +89,13 @@ func (h *Handler) GetByID(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateRFQInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -100,7 +105,7 @@ func (h *Handler) Create(c *gin.Context) {
 
 	rfq, err := h.svc.CreateRFQ(ctx, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -116,24 +121,25 @@ func (h *Handler) ListResponses(c *gin.Context) {
 
 	responses, err := h.svc.ListResponsesByRFQID(ctx, rfqID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"items": responses})
 }
 
-// CreateResponse creates a supplier's response to an RFQ.
+// CreateResponse creates a supplier's response to an RFQ on behalf of the
+// caller's organization.
 func (h *Handler) CreateResponse(c *gin.Context) {
 	var in CreateRFQResponseInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -141,9 +147,27 @@ func (h *Handler) CreateResponse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.svc.CreateResponse(ctx, claims.UserID, in)
+	supplierID, role, err := h.suppliers.SupplierMembership(ctx, claims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	switch {
+	case supplierID != "":
+		if !authz.MemberCan(role, authz.RFQsRespond) {
+			_ = c.Error(authz.ErrTeamRoleForbidden)
+			return
+		}
+	case authz.Can(claims.Role, authz.RFQsManage):
+		supplierID = claims.UserID
+	default:
+		_ = c.Error(authz.ErrSupplierProfileRequired)
+		return
+	}
+
+	resp, err := h.svc.CreateResponse(ctx, supplierID, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -1,6 +1,10 @@
 package rfq
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 type RFQStatus string
 
@@ -44,6 +48,11 @@ type RFQ struct {
 	UpdatedAt             time.Time  `db:"updated_at" json:"updatedAt"`
 }
 
+// Cursor implements pagination.Row.
+func (rfq *RFQ) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: rfq.CreatedAt, ID: rfq.ID}
+}
+
 // RFQResponse is a supplier's reply to an RFQ.
 type RFQResponse struct {
 	ID                string         `db:"id" json:"id" gorm:"column:id;type:varchar(36);primaryKey"`
@@ -60,6 +69,7 @@ type RFQResponse struct {
 	Status            ResponseStatus `db:"status" json:"status" gorm:"column:status;type:varchar(20);default:'pending'"`
 	SubmittedAt       time.Time      `db:"submitted_at" json:"submittedAt" gorm:"column:submitted_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	ExpiresAt         *time.Time     `db:"expires_at" json:"expiresAt,omitempty" gorm:"column:expires_at;type:timestamp"`
+	RespondedBy       string         `db:"responded_by" json:"respondedBy,omitempty" gorm:"column:responded_by;type:varchar(36)"` // acting member
 	CreatedAt         time.Time      `db:"created_at" json:"createdAt" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	UpdatedAt         time.Time      `db:"updated_at" json:"updatedAt" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP"`
 }
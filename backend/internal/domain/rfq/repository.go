@@ -7,17 +7,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 var (
-	ErrNotFound = errors.New("rfq not found")
+	ErrNotFound = apperr.NotFound("rfq_not_found", "rfq not found")
 )
 
 type Repository interface {
 	// RFQ
-	ListRFQs(ctx context.Context, limit, offset int) ([]*RFQ, error)
-	ListRFQsByBuyerID(ctx context.Context, buyerID string, limit, offset int) ([]*RFQ, error)
-	ListRFQsBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*RFQ, error)
+	ListRFQs(ctx context.Context, page pagination.Params) (*pagination.Page[*RFQ], error)
+	ListRFQsByBuyerID(ctx context.Context, buyerID string, page pagination.Params) (*pagination.Page[*RFQ], error)
+	ListRFQsBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*RFQ], error)
 	GetRFQByID(ctx context.Context, id string) (*RFQ, error)
 	CreateRFQ(ctx context.Context, rfq *RFQ) error
 	UpdateRFQ(ctx context.Context, rfq *RFQ) error
@@ -39,16 +42,17 @@ func NewMySQLRFQRepository(db *sql.DB) Repository {
 	return &mySQLRFQRepository{db: db}
 }
 
-func (r *mySQLRFQRepository) ListRFQs(ctx context.Context, limit, offset int) ([]*RFQ, error) {
-	const query = `
+func (r *mySQLRFQRepository) ListRFQs(ctx context.Context, page pagination.Params) (*pagination.Page[*RFQ], error) {
+	query := `
 SELECT id, buyer_id, product_id, product_name, product_image, supplier_id, quantity, unit, 
        specifications, requirements, delivery_location, preferred_delivery_date, budget, 
        currency, status, submitted_at, expires_at, created_at, updated_at
 FROM rfqs
-ORDER BY created_at DESC
+WHERE ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -67,20 +71,27 @@ LIMIT ? OFFSET ?`
 		}
 		rfqs = append(rfqs, &rfq)
 	}
-	return rfqs, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM rfqs")
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(rfqs, page, total), nil
 }
 
-func (r *mySQLRFQRepository) ListRFQsByBuyerID(ctx context.Context, buyerID string, limit, offset int) ([]*RFQ, error) {
-	const query = `
+func (r *mySQLRFQRepository) ListRFQsByBuyerID(ctx context.Context, buyerID string, page pagination.Params) (*pagination.Page[*RFQ], error) {
+	query := `
 SELECT id, buyer_id, product_id, product_name, product_image, supplier_id, quantity, unit, 
        specifications, requirements, delivery_location, preferred_delivery_date, budget, 
        currency, status, submitted_at, expires_at, created_at, updated_at
 FROM rfqs
-WHERE buyer_id = ?
-ORDER BY created_at DESC
+WHERE buyer_id = ? AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, buyerID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(buyerID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -99,20 +110,27 @@ LIMIT ? OFFSET ?`
 		}
 		rfqs = append(rfqs, &rfq)
 	}
-	return rfqs, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM rfqs WHERE buyer_id = ?", buyerID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(rfqs, page, total), nil
 }
 
-func (r *mySQLRFQRepository) ListRFQsBySupplierID(ctx context.Context, supplierID string, limit, offset int) ([]*RFQ, error) {
-	const query = `
+func (r *mySQLRFQRepository) ListRFQsBySupplierID(ctx context.Context, supplierID string, page pagination.Params) (*pagination.Page[*RFQ], error) {
+	query := `
 SELECT id, buyer_id, product_id, product_name, product_image, supplier_id, quantity, unit, 
        specifications, requirements, delivery_location, preferred_delivery_date, budget, 
        currency, status, submitted_at, expires_at, created_at, updated_at
 FROM rfqs
-WHERE supplier_id = ? OR supplier_id IS NULL
-ORDER BY created_at DESC
+WHERE (supplier_id = ? OR supplier_id IS NULL) AND ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, supplierID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args(supplierID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +149,14 @@ LIMIT ? OFFSET ?`
 		}
 		rfqs = append(rfqs, &rfq)
 	}
-	return rfqs, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM rfqs WHERE (supplier_id = ? OR supplier_id IS NULL)", supplierID)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(rfqs, page, total), nil
 }
 
 func (r *mySQLRFQRepository) GetRFQByID(ctx context.Context, id string) (*RFQ, error) {
@@ -226,7 +251,8 @@ func (r *mySQLRFQRepository) DeleteRFQ(ctx context.Context, id string) error {
 func (r *mySQLRFQRepository) ListResponsesByRFQID(ctx context.Context, rfqID string) ([]*RFQResponse, error) {
 	const query = `
 SELECT id, rfq_id, supplier_id, unit_price, total_price, currency, moq, estimated_delivery, 
-       payment_terms, specifications, message, status, submitted_at, expires_at, created_at, updated_at
+       payment_terms, specifications, message, status, submitted_at, expires_at,
+       COALESCE(responded_by, ''), created_at, updated_at
 FROM rfq_responses
 WHERE rfq_id = ?
 ORDER BY created_at DESC`
@@ -244,7 +270,7 @@ ORDER BY created_at DESC`
 			&resp.ID, &resp.RFQID, &resp.SupplierID, &resp.UnitPrice, &resp.TotalPrice,
 			&resp.Currency, &resp.MOQ, &resp.EstimatedDelivery, &resp.PaymentTerms,
 			&resp.Specifications, &resp.Message, &resp.Status, &resp.SubmittedAt,
-			&resp.ExpiresAt, &resp.CreatedAt, &resp.UpdatedAt,
+			&resp.ExpiresAt, &resp.RespondedBy, &resp.CreatedAt, &resp.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -256,7 +282,8 @@ ORDER BY created_at DESC`
 func (r *mySQLRFQRepository) GetResponseByID(ctx context.Context, id string) (*RFQResponse, error) {
 	const query = `
 SELECT id, rfq_id, supplier_id, unit_price, total_price, currency, moq, estimated_delivery, 
-       payment_terms, specifications, message, status, submitted_at, expires_at, created_at, updated_at
+       payment_terms, specifications, message, status, submitted_at, expires_at,
+       COALESCE(responded_by, ''), created_at, updated_at
 FROM rfq_responses
 WHERE id = ? LIMIT 1`
 
@@ -265,7 +292,7 @@ WHERE id = ? LIMIT 1`
 		&resp.ID, &resp.RFQID, &resp.SupplierID, &resp.UnitPrice, &resp.TotalPrice,
 		&resp.Currency, &resp.MOQ, &resp.EstimatedDelivery, &resp.PaymentTerms,
 		&resp.Specifications, &resp.Message, &resp.Status, &resp.SubmittedAt,
-		&resp.ExpiresAt, &resp.CreatedAt, &resp.UpdatedAt,
+		&resp.ExpiresAt, &resp.RespondedBy, &resp.CreatedAt, &resp.UpdatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -286,14 +313,15 @@ func (r *mySQLRFQRepository) CreateResponse(ctx context.Context, resp *RFQRespon
 	const query = `
 INSERT INTO rfq_responses (
 	id, rfq_id, supplier_id, unit_price, total_price, currency, moq, estimated_delivery, 
-	payment_terms, specifications, message, status, submitted_at, expires_at, created_at, updated_at
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	payment_terms, specifications, message, status, submitted_at, expires_at, responded_by,
+	created_at, updated_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		resp.ID, resp.RFQID, resp.SupplierID, resp.UnitPrice, resp.TotalPrice,
 		resp.Currency, resp.MOQ, resp.EstimatedDelivery, resp.PaymentTerms,
 		resp.Specifications, resp.Message, resp.Status, resp.SubmittedAt,
-		resp.ExpiresAt, resp.CreatedAt, resp.UpdatedAt,
+		resp.ExpiresAt, resp.RespondedBy, resp.CreatedAt, resp.UpdatedAt,
 	)
 	return err
 }
@@ -3,42 +3,51 @@ package rfq
 import (
 	"context"
 	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/metrics"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
-	repo Repository
+	repo    Repository
+	metrics *metrics.Metrics
 }
 
 func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// WithMetrics counts submitted RFQs and supplier responses in m.
+func (s *Service) WithMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
 // RFQ operations
-func (s *Service) ListRFQs(ctx context.Context, limit, offset int) ([]*RFQ, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListRFQs(ctx, limit, offset)
+func (s *Service) ListRFQs(ctx context.Context, page pagination.Params) (*pagination.Page[*RFQ], error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.ListRFQs")
+	defer span.End()
+
+	return s.repo.ListRFQs(ctx, page.Normalize(pagination.DefaultLimit))
 }
 
-func (s *Service) ListMyRFQs(ctx context.Context, buyerID string, limit, offset int) ([]*RFQ, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.ListRFQsByBuyerID(ctx, buyerID, limit, offset)
+func (s *Service) ListMyRFQs(ctx context.Context, buyerID string, page pagination.Params) (*pagination.Page[*RFQ], error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.ListMyRFQs")
+	defer span.End()
+
+	return s.repo.ListRFQsByBuyerID(ctx, buyerID, page.Normalize(pagination.DefaultLimit))
 }
 
 func (s *Service) GetRFQByID(ctx context.Context, id string) (*RFQ, error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.GetRFQByID")
+	defer span.End()
 	return s.repo.GetRFQByID(ctx, id)
 }
 
 func (s *Service) CreateRFQ(ctx context.Context, buyerID string, in CreateRFQInput) (*RFQ, error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.CreateRFQ")
+	defer span.End()
+
 	now := time.Now().UTC()
 	submitted := now
 	expires := now.Add(30 * 24 * time.Hour) // Default: 30 days
@@ -65,10 +74,14 @@ func (s *Service) CreateRFQ(ctx context.Context, buyerID string, in CreateRFQInp
 	if err := s.repo.CreateRFQ(ctx, rfq); err != nil {
 		return nil, err
 	}
+	s.metrics.RFQSubmitted()
 	return rfq, nil
 }
 
 func (s *Service) UpdateRFQStatus(ctx context.Context, id string, status RFQStatus) (*RFQ, error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.UpdateRFQStatus")
+	defer span.End()
+
 	rfq, err := s.repo.GetRFQByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -82,15 +95,23 @@ func (s *Service) UpdateRFQStatus(ctx context.Context, id string, status RFQStat
 }
 
 func (s *Service) DeleteRFQ(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "rfq.Service.DeleteRFQ")
+	defer span.End()
 	return s.repo.DeleteRFQ(ctx, id)
 }
 
 // RFQ Response operations
 func (s *Service) ListResponsesByRFQID(ctx context.Context, rfqID string) ([]*RFQResponse, error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.ListResponsesByRFQID")
+	defer span.End()
 	return s.repo.ListResponsesByRFQID(ctx, rfqID)
 }
 
-func (s *Service) CreateResponse(ctx context.Context, supplierID string, in CreateRFQResponseInput) (*RFQResponse, error) {
+// CreateResponse records supplierID's quote; respondedBy is the acting member.
+func (s *Service) CreateResponse(ctx context.Context, supplierID, respondedBy string, in CreateRFQResponseInput) (*RFQResponse, error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.CreateResponse")
+	defer span.End()
+
 	now := time.Now().UTC()
 	totalPrice := in.UnitPrice * float64(in.MOQ)
 
@@ -107,15 +128,20 @@ func (s *Service) CreateResponse(ctx context.Context, supplierID string, in Crea
 		Message:           in.Message,
 		Status:            ResponsePending,
 		SubmittedAt:       now,
+		RespondedBy:       respondedBy,
 	}
 
 	if err := s.repo.CreateResponse(ctx, resp); err != nil {
 		return nil, err
 	}
+	s.metrics.RFQResponded()
 	return resp, nil
 }
 
 func (s *Service) UpdateResponseStatus(ctx context.Context, id string, status ResponseStatus) (*RFQResponse, error) {
+	ctx, span := tracing.Start(ctx, "rfq.Service.UpdateResponseStatus")
+	defer span.End()
+
 	resp, err := s.repo.GetResponseByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
 )
 
@@ -55,7 +56,7 @@ func (h *Handler) Search(c *gin.Context) {
 
 	results, err := h.svc.Search(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -66,7 +67,7 @@ func (h *Handler) Search(c *gin.Context) {
 func (h *Handler) ListHistory(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -79,7 +80,7 @@ func (h *Handler) ListHistory(c *gin.Context) {
 
 	list, err := h.svc.ListSearchHistory(ctx, claims.UserID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	if list == nil {
@@ -100,14 +101,14 @@ type RecordHistoryInput struct {
 func (h *Handler) RecordHistory(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	var in RecordHistoryInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
@@ -115,7 +116,7 @@ func (h *Handler) RecordHistory(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.SaveSearchHistory(ctx, claims.UserID, in.Query, in.SearchType, in.Filters, in.ResultCount); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
@@ -16,6 +18,9 @@ func NewService(db *sql.DB) *Service {
 }
 
 func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	ctx, span := tracing.Start(ctx, "search.Service.Search")
+	defer span.End()
+
 	// Set defaults
 	if req.Limit <= 0 || req.Limit > 100 {
 		req.Limit = 20
@@ -43,6 +48,9 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 }
 
 func (s *Service) searchProducts(ctx context.Context, req SearchRequest) ([]ProductResult, error) {
+	ctx, span := tracing.Start(ctx, "search.Service.searchProducts")
+	defer span.End()
+
 	query := `
 SELECT p.id, p.name, p.description, p.price, p.currency, p.images, 
        p.supplier_id, s.company_name, p.rating, p.moq
@@ -98,6 +106,9 @@ WHERE p.status = 'active'
 }
 
 func (s *Service) searchSuppliers(ctx context.Context, req SearchRequest) ([]SupplierResult, error) {
+	ctx, span := tracing.Start(ctx, "search.Service.searchSuppliers")
+	defer span.End()
+
 	query := `
 SELECT id, company_name, country, logo, verified, rating, description
 FROM suppliers
@@ -143,6 +154,9 @@ WHERE status = 'active'
 
 // SaveSearchHistory records a search for a user.
 func (s *Service) SaveSearchHistory(ctx context.Context, userID, query string, searchType SearchType, filters string, resultCount int) error {
+	ctx, span := tracing.Start(ctx, "search.Service.SaveSearchHistory")
+	defer span.End()
+
 	if query == "" {
 		return nil
 	}
@@ -159,6 +173,9 @@ func (s *Service) SaveSearchHistory(ctx context.Context, userID, query string, s
 
 // ListSearchHistory returns recent search history for a user.
 func (s *Service) ListSearchHistory(ctx context.Context, userID string, limit, offset int) ([]*SearchHistory, error) {
+	ctx, span := tracing.Start(ctx, "search.Service.ListSearchHistory")
+	defer span.End()
+
 	if limit <= 0 || limit > 50 {
 		limit = 20
 	}
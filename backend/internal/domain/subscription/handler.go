@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 	"github.com/example/global-trade-hub/backend/internal/domain/auth"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
 )
@@ -30,7 +31,7 @@ func (h *Handler) List(c *gin.Context) {
 
 	subscriptions, err := h.svc.List(ctx, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -46,11 +47,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	subscription, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -61,14 +58,14 @@ func (h *Handler) GetByID(c *gin.Context) {
 func (h *Handler) GetMySubscription(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	// Only suppliers can have subscriptions
 	if claims.Role != string(auth.RoleSupplier) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only suppliers can have subscriptions"})
+		_ = c.Error(apperr.Forbidden("supplier_only", "only suppliers can have subscriptions"))
 		return
 	}
 
@@ -77,11 +74,7 @@ func (h *Handler) GetMySubscription(c *gin.Context) {
 
 	subscription, err := h.svc.GetBySupplierID(ctx, claims.UserID)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -92,20 +85,20 @@ func (h *Handler) GetMySubscription(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateSubscriptionInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	// Only suppliers can create subscriptions
 	if claims.Role != string(auth.RoleSupplier) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only suppliers can create subscriptions"})
+		_ = c.Error(apperr.Forbidden("supplier_only", "only suppliers can create subscriptions"))
 		return
 	}
 
@@ -114,7 +107,7 @@ func (h *Handler) Create(c *gin.Context) {
 
 	subscription, err := h.svc.Create(ctx, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -130,11 +123,7 @@ func (h *Handler) Cancel(c *gin.Context) {
 
 	subscription, err := h.svc.Cancel(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -149,11 +138,7 @@ func (h *Handler) Delete(c *gin.Context) {
 	defer cancel()
 
 	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
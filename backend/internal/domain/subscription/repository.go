@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 )
 
 var (
-	ErrNotFound = errors.New("subscription not found")
+	ErrNotFound = apperr.NotFound("subscription_not_found", "subscription not found")
 )
 
 type Repository interface {
@@ -2,7 +2,10 @@ package subscription
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
@@ -14,6 +17,9 @@ func NewService(repo Repository) *Service {
 }
 
 func (s *Service) List(ctx context.Context, limit, offset int) ([]*Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.List")
+	defer span.End()
+
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
@@ -24,18 +30,46 @@ func (s *Service) List(ctx context.Context, limit, offset int) ([]*Subscription,
 }
 
 func (s *Service) GetByID(ctx context.Context, id string) (*Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.GetByID")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
 func (s *Service) GetBySupplierID(ctx context.Context, supplierID string) (*Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.GetBySupplierID")
+	defer span.End()
 	return s.repo.GetBySupplierID(ctx, supplierID)
 }
 
 func (s *Service) GetActiveBySupplierID(ctx context.Context, supplierID string) (*Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.GetActiveBySupplierID")
+	defer span.End()
 	return s.repo.GetActiveBySupplierID(ctx, supplierID)
 }
 
+// ActivePlan returns the plan of supplierID's active, unexpired subscription,
+// or PlanFree without one.
+func (s *Service) ActivePlan(ctx context.Context, supplierID string) (SubscriptionPlan, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.ActivePlan")
+	defer span.End()
+
+	sub, err := s.repo.GetActiveBySupplierID(ctx, supplierID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return PlanFree, nil
+		}
+		return "", err
+	}
+	if sub.ExpiresAt != nil && sub.ExpiresAt.Before(time.Now()) {
+		return PlanFree, nil
+	}
+	return sub.Plan, nil
+}
+
 func (s *Service) Create(ctx context.Context, supplierID string, in CreateSubscriptionInput) (*Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.Create")
+	defer span.End()
+
 	now := time.Now().UTC()
 	expiresAt := now.Add(time.Duration(in.DurationDays) * 24 * time.Hour)
 
@@ -57,6 +91,9 @@ func (s *Service) Create(ctx context.Context, supplierID string, in CreateSubscr
 }
 
 func (s *Service) Cancel(ctx context.Context, id string) (*Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscription.Service.Cancel")
+	defer span.End()
+
 	sub, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -73,5 +110,7 @@ func (s *Service) Cancel(ctx context.Context, id string) (*Subscription, error)
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "subscription.Service.Delete")
+	defer span.End()
 	return s.repo.Delete(ctx, id)
 }
@@ -2,13 +2,17 @@ package supplier
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 type Handler struct {
@@ -21,19 +25,22 @@ func NewHandler(svc *Service) *Handler {
 
 // List returns paginated suppliers (public endpoint).
 func (h *Handler) List(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	suppliers, err := h.svc.List(ctx, limit, offset)
+	suppliers, err := h.svc.List(ctx, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": suppliers})
+	c.JSON(http.StatusOK, suppliers)
 }
 
 // GetByID returns a single supplier by ID (public).
@@ -45,22 +52,20 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	supplier, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "supplier not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	httpcache.SetLastModified(c, supplier.UpdatedAt)
 	c.JSON(http.StatusOK, supplier)
 }
 
-// GetMyProfile returns the authenticated user's supplier profile.
+// GetMyProfile returns the organization the authenticated user belongs to and
+// their team role in it.
 func (h *Handler) GetMyProfile(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -68,13 +73,13 @@ func (h *Handler) GetMyProfile(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	supplier, err := h.svc.GetByUserID(ctx, claims.UserID)
+	supplier, err := h.svc.GetMyProfile(ctx, claims.UserID)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "supplier profile not found"})
+		if err == ErrNotFound || err == ErrNotMember {
+			_ = c.Error(errProfileNotFound)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -85,13 +90,13 @@ func (h *Handler) GetMyProfile(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateSupplierInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -101,18 +106,45 @@ func (h *Handler) Create(c *gin.Context) {
 
 	supplier, err := h.svc.Create(ctx, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, supplier)
 }
 
+// authorize loads supplier id and checks that the caller's team role in it
+// grants p, or that they may manage all suppliers. It writes the error
+// response when it fails.
+func (h *Handler) authorize(ctx context.Context, c *gin.Context, id string, p authz.Permission) bool {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return false
+	}
+	claims := raw.(*middleware.Claims)
+
+	if _, err := h.svc.GetByID(ctx, id); err != nil {
+		_ = c.Error(err)
+		return false
+	}
+	allowed, err := authz.ActsForSupplier(ctx, h.svc, claims, id, p)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+	if !allowed && !authz.Can(claims.Role, authz.SuppliersManage) {
+		_ = c.Error(authz.ErrAccessDenied)
+		return false
+	}
+	return true
+}
+
 // Update updates the supplier profile.
 func (h *Handler) Update(c *gin.Context) {
 	var in UpdateSupplierInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 	id := c.Param("id")
@@ -120,34 +152,210 @@ func (h *Handler) Update(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
+	if !h.authorize(ctx, c, id, authz.SuppliersWrite) {
+		return
+	}
+
 	supplier, err := h.svc.Update(ctx, id, in)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "supplier not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, supplier)
 }
 
-// Delete removes a supplier profile.
+// Delete removes a supplier profile. Only its owners (or staff) may delete it.
 func (h *Handler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
+	if !h.authorize(ctx, c, id, authz.SuppliersDelete) {
+		return
+	}
+
 	if err := h.svc.Delete(ctx, id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "supplier not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers returns the members of the caller's organization.
+func (h *Handler) ListMembers(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	members, err := h.svc.ListMembers(ctx, claims.UserID)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": members})
+}
+
+// UpdateMember changes a member's team role.
+func (h *Handler) UpdateMember(c *gin.Context) {
+	var in UpdateMemberInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	member, err := h.svc.UpdateMemberRole(ctx, claims.UserID, c.Param("userId"), in.Role)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member from the caller's organization, or lets the
+// caller leave it.
+func (h *Handler) RemoveMember(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.RemoveMember(ctx, claims.UserID, c.Param("userId")); err != nil {
+		writeTeamError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// InviteMember emails an invitation to join the caller's organization.
+func (h *Handler) InviteMember(c *gin.Context) {
+	var in InviteMemberInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	inv, err := h.svc.InviteMember(ctx, claims.UserID, in)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, inv)
+}
+
+// ListInvitations returns the organization's pending invitations.
+func (h *Handler) ListInvitations(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	invitations, err := h.svc.ListInvitations(ctx, claims.UserID)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": invitations})
+}
+
+// RevokeInvitation invalidates a pending invitation.
+func (h *Handler) RevokeInvitation(c *gin.Context) {
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.RevokeInvitation(ctx, claims.UserID, c.Param("id")); err != nil {
+		writeTeamError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation adds the authenticated user to the inviting organization.
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	var in AcceptInvitationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(apperr.Invalid(err))
+		return
+	}
+
+	raw, ok := c.Get("claims")
+	if !ok {
+		_ = c.Error(apperr.ErrMissingClaims)
+		return
+	}
+	claims := raw.(*middleware.Claims)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	profile, err := h.svc.AcceptInvitation(ctx, claims.UserID, in.Token)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// errProfileNotFound is reported to callers who do not belong to a supplier
+// organization.
+var errProfileNotFound = apperr.NotFound("supplier_profile_not_found", "supplier profile not found")
+
+// writeTeamError reports organization membership errors.
+func writeTeamError(c *gin.Context, err error) {
+	if errors.Is(err, ErrNotMember) || errors.Is(err, ErrNotFound) {
+		err = errProfileNotFound
+	}
+	_ = c.Error(err)
+}
@@ -1,6 +1,10 @@
 package supplier
 
-import "time"
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
 
 type SubscriptionPlan string
 
@@ -48,6 +52,11 @@ type Supplier struct {
 	UpdatedAt       time.Time        `db:"updated_at" json:"updatedAt"`
 }
 
+// Cursor implements pagination.Row.
+func (s *Supplier) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: s.CreatedAt, ID: s.ID}
+}
+
 type CreateSupplierInput struct {
 	CompanyName string `json:"companyName" binding:"required"`
 	ContactName string `json:"contactName" binding:"required"`
@@ -72,3 +81,66 @@ type UpdateSupplierInput struct {
 	Status       *SupplierStatus   `json:"status,omitempty"`
 	Subscription *SubscriptionPlan `json:"subscription,omitempty"`
 }
+
+// MemberRole is a user's role within a supplier organization. It is separate
+// from the user's account role (auth.UserRole); see authz.MemberCan for what
+// each role may do.
+type MemberRole string
+
+const (
+	MemberOwner   MemberRole = "owner"
+	MemberManager MemberRole = "manager"
+	MemberSales   MemberRole = "sales"
+	MemberViewer  MemberRole = "viewer"
+)
+
+// Member links a user to a supplier organization.
+type Member struct {
+	SupplierID string     `db:"supplier_id" json:"supplierId"`
+	UserID     string     `db:"user_id" json:"userId"`
+	Role       MemberRole `db:"role" json:"role"`
+	InvitedBy  string     `db:"invited_by" json:"invitedBy,omitempty"`
+	Email      string     `db:"email" json:"email"`         // from users
+	FullName   string     `db:"full_name" json:"fullName"`  // from users
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// MemberInvitation is an emailed invitation to join an organization. Only a
+// hash of the token is stored.
+type MemberInvitation struct {
+	ID         string     `db:"id" json:"id"`
+	SupplierID string     `db:"supplier_id" json:"supplierId"`
+	Email      string     `db:"email" json:"email"`
+	Role       MemberRole `db:"role" json:"role"`
+	InvitedBy  string     `db:"invited_by" json:"invitedBy"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expiresAt"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"acceptedAt,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// Pending reports whether the invitation can still be accepted at t.
+func (i *MemberInvitation) Pending(t time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && t.Before(i.ExpiresAt)
+}
+
+// Profile is the caller's organization together with their role in it.
+type Profile struct {
+	*Supplier
+	MemberRole MemberRole `json:"memberRole"`
+}
+
+type InviteMemberInput struct {
+	Email string     `json:"email" binding:"required,email"`
+	Role  MemberRole `json:"role" binding:"required,oneof=owner manager sales viewer"`
+}
+
+type UpdateMemberInput struct {
+	Role MemberRole `json:"role" binding:"required,oneof=owner manager sales viewer"`
+}
+
+type AcceptInvitationInput struct {
+	Token string `json:"token" binding:"required"`
+}
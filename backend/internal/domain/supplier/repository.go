@@ -7,14 +7,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
 )
 
 var (
-	ErrNotFound = errors.New("supplier not found")
+	ErrNotFound             = apperr.NotFound("supplier_not_found", "supplier not found")
+	ErrMemberNotFound       = apperr.NotFound("member_not_found", "member not found")
+	ErrInvitationNotFound   = apperr.NotFound("team_invitation_not_found", "invitation not found")
+	ErrInvitationNotPending = apperr.Conflict("team_invitation_not_pending", "invitation is no longer pending")
 )
 
 type Repository interface {
-	List(ctx context.Context, limit, offset int) ([]*Supplier, error)
+	List(ctx context.Context, page pagination.Params) (*pagination.Page[*Supplier], error)
 	GetByID(ctx context.Context, id string) (*Supplier, error)
 	GetByUserID(ctx context.Context, userID string) (*Supplier, error)
 	Create(ctx context.Context, s *Supplier) error
@@ -30,17 +36,18 @@ func NewMySQLSupplierRepository(db *sql.DB) Repository {
 	return &mySQLSupplierRepository{db: db}
 }
 
-func (r *mySQLSupplierRepository) List(ctx context.Context, limit, offset int) ([]*Supplier, error) {
-	const query = `
+func (r *mySQLSupplierRepository) List(ctx context.Context, page pagination.Params) (*pagination.Page[*Supplier], error) {
+	query := `
 SELECT id, user_id, company_name, contact_name, email, phone, country, city, address, 
        logo, description, verified, status, subscription, rating, total_products, 
        total_orders, total_revenue, response_rate, response_time, established, employees, 
        created_at, updated_at
 FROM suppliers
-ORDER BY created_at DESC
+WHERE ` + page.Where("created_at", "id") + `
+ORDER BY created_at DESC, id DESC
 LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, page.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +67,14 @@ LIMIT ? OFFSET ?`
 		}
 		suppliers = append(suppliers, &s)
 	}
-	return suppliers, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	total, err := page.Count(ctx, r.db, "SELECT COUNT(*) FROM suppliers")
+	if err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(suppliers, page, total), nil
 }
 
 func (r *mySQLSupplierRepository) GetByID(ctx context.Context, id string) (*Supplier, error) {
@@ -185,3 +199,300 @@ func (r *mySQLSupplierRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// MemberRepository persists organization memberships.
+type MemberRepository interface {
+	// GetByUserID returns the membership of userID, or ErrMemberNotFound.
+	GetByUserID(ctx context.Context, userID string) (*Member, error)
+	Get(ctx context.Context, supplierID, userID string) (*Member, error)
+	// List returns the members of an organization with their name and email.
+	List(ctx context.Context, supplierID string) ([]Member, error)
+	CountByRole(ctx context.Context, supplierID string, role MemberRole) (int, error)
+	Add(ctx context.Context, m *Member) error
+	UpdateRole(ctx context.Context, supplierID, userID string, role MemberRole) error
+	Remove(ctx context.Context, supplierID, userID string) error
+	RemoveAll(ctx context.Context, supplierID string) error
+}
+
+type mySQLMemberRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLMemberRepository returns a MySQL-backed implementation.
+func NewMySQLMemberRepository(db *sql.DB) MemberRepository {
+	return &mySQLMemberRepository{db: db}
+}
+
+const memberSelect = `
+SELECT m.supplier_id, m.user_id, m.role, COALESCE(m.invited_by, ''),
+       COALESCE(u.email, ''), COALESCE(u.full_name, ''), m.created_at, m.updated_at
+FROM supplier_members m
+LEFT JOIN users u ON u.id = m.user_id`
+
+func scanMember(row rowScanner) (*Member, error) {
+	var m Member
+	if err := row.Scan(
+		&m.SupplierID,
+		&m.UserID,
+		&m.Role,
+		&m.InvitedBy,
+		&m.Email,
+		&m.FullName,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mySQLMemberRepository) GetByUserID(ctx context.Context, userID string) (*Member, error) {
+	return scanMember(r.db.QueryRowContext(ctx, memberSelect+` WHERE m.user_id = ? LIMIT 1`, userID))
+}
+
+func (r *mySQLMemberRepository) Get(ctx context.Context, supplierID, userID string) (*Member, error) {
+	return scanMember(r.db.QueryRowContext(ctx,
+		memberSelect+` WHERE m.supplier_id = ? AND m.user_id = ? LIMIT 1`, supplierID, userID,
+	))
+}
+
+func (r *mySQLMemberRepository) List(ctx context.Context, supplierID string) ([]Member, error) {
+	rows, err := r.db.QueryContext(ctx, memberSelect+` WHERE m.supplier_id = ? ORDER BY m.created_at`, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Member{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *m)
+	}
+	return out, rows.Err()
+}
+
+func (r *mySQLMemberRepository) CountByRole(ctx context.Context, supplierID string, role MemberRole) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM supplier_members WHERE supplier_id = ? AND role = ?`, supplierID, role,
+	).Scan(&n)
+	return n, err
+}
+
+func (r *mySQLMemberRepository) Add(ctx context.Context, m *Member) error {
+	now := time.Now().UTC()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	const query = `
+INSERT INTO supplier_members (supplier_id, user_id, role, invited_by, created_at, updated_at)
+VALUES (?, ?, ?, NULLIF(?, ''), ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		m.SupplierID,
+		m.UserID,
+		m.Role,
+		m.InvitedBy,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	return err
+}
+
+func (r *mySQLMemberRepository) UpdateRole(ctx context.Context, supplierID, userID string, role MemberRole) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE supplier_members SET role = ?, updated_at = ? WHERE supplier_id = ? AND user_id = ?`,
+		role, time.Now().UTC(), supplierID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return memberAffected(res)
+}
+
+func (r *mySQLMemberRepository) Remove(ctx context.Context, supplierID, userID string) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM supplier_members WHERE supplier_id = ? AND user_id = ?`, supplierID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return memberAffected(res)
+}
+
+func (r *mySQLMemberRepository) RemoveAll(ctx context.Context, supplierID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM supplier_members WHERE supplier_id = ?`, supplierID)
+	return err
+}
+
+func memberAffected(res sql.Result) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// InvitationRepository persists invitations to join an organization.
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *MemberInvitation) error
+	GetByTokenHash(ctx context.Context, hash string) (*MemberInvitation, error)
+	// ListPending returns the organization's invitations that are neither
+	// accepted, revoked nor expired at now, newest first.
+	ListPending(ctx context.Context, supplierID string, now time.Time) ([]MemberInvitation, error)
+	// RevokePendingForEmail revokes the organization's pending invitations
+	// for email.
+	RevokePendingForEmail(ctx context.Context, supplierID, email string, at time.Time) error
+	// MarkAccepted and Revoke return ErrInvitationNotPending if the
+	// invitation was already accepted or revoked.
+	MarkAccepted(ctx context.Context, id string, at time.Time) error
+	Revoke(ctx context.Context, supplierID, id string, at time.Time) error
+}
+
+type mySQLInvitationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLInvitationRepository returns a MySQL-backed implementation.
+func NewMySQLInvitationRepository(db *sql.DB) InvitationRepository {
+	return &mySQLInvitationRepository{db: db}
+}
+
+const invitationColumns = `id, supplier_id, email, role, invited_by, token_hash, expires_at, accepted_at, revoked_at, created_at`
+
+func scanInvitation(row rowScanner) (*MemberInvitation, error) {
+	var i MemberInvitation
+	if err := row.Scan(
+		&i.ID,
+		&i.SupplierID,
+		&i.Email,
+		&i.Role,
+		&i.InvitedBy,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *mySQLInvitationRepository) Create(ctx context.Context, inv *MemberInvitation) error {
+	if inv.ID == "" {
+		inv.ID = uuid.NewString()
+	}
+	inv.CreatedAt = time.Now().UTC()
+
+	const query = `
+INSERT INTO supplier_invitations (` + invitationColumns + `)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		inv.ID,
+		inv.SupplierID,
+		inv.Email,
+		inv.Role,
+		inv.InvitedBy,
+		inv.TokenHash,
+		inv.ExpiresAt,
+		inv.AcceptedAt,
+		inv.RevokedAt,
+		inv.CreatedAt,
+	)
+	return err
+}
+
+func (r *mySQLInvitationRepository) GetByTokenHash(ctx context.Context, hash string) (*MemberInvitation, error) {
+	return scanInvitation(r.db.QueryRowContext(ctx,
+		`SELECT `+invitationColumns+` FROM supplier_invitations WHERE token_hash = ? LIMIT 1`, hash,
+	))
+}
+
+func (r *mySQLInvitationRepository) ListPending(ctx context.Context, supplierID string, now time.Time) ([]MemberInvitation, error) {
+	const query = `
+SELECT ` + invitationColumns + `
+FROM supplier_invitations
+WHERE supplier_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?
+ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, supplierID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []MemberInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *inv)
+	}
+	return out, rows.Err()
+}
+
+func (r *mySQLInvitationRepository) RevokePendingForEmail(ctx context.Context, supplierID, email string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE supplier_invitations SET revoked_at = ? WHERE supplier_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL`,
+		at, supplierID, email,
+	)
+	return err
+}
+
+func (r *mySQLInvitationRepository) MarkAccepted(ctx context.Context, id string, at time.Time) error {
+	return r.close(ctx, "accepted_at", "", id, at)
+}
+
+func (r *mySQLInvitationRepository) Revoke(ctx context.Context, supplierID, id string, at time.Time) error {
+	return r.close(ctx, "revoked_at", supplierID, id, at)
+}
+
+// close sets column (accepted_at or revoked_at) on a still pending
+// invitation. A non-empty supplierID restricts it to that organization.
+func (r *mySQLInvitationRepository) close(ctx context.Context, column, supplierID, id string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE supplier_invitations SET `+column+` = ?
+WHERE id = ? AND (? = '' OR supplier_id = ?) AND accepted_at IS NULL AND revoked_at IS NULL`,
+		at, id, supplierID, supplierID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		var n int
+		if err := r.db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM supplier_invitations WHERE id = ? AND (? = '' OR supplier_id = ?)`,
+			id, supplierID, supplierID,
+		).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrInvitationNotFound
+		}
+		return ErrInvitationNotPending
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
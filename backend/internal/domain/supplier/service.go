@@ -2,35 +2,174 @@ package supplier
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/mail"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
+)
+
+const memberInviteTTL = 7 * 24 * time.Hour
+
+var (
+	ErrNotMember = apperr.NotFound("not_a_member", "not a member of a supplier organization")
+	// ErrAlreadyMember is returned when a user who already belongs to an
+	// organization creates or joins another one.
+	ErrAlreadyMember      = apperr.Conflict("already_a_member", "user already belongs to a supplier organization")
+	ErrTeamRoleForbidden  = authz.ErrTeamRoleForbidden
+	ErrOwnerRequired      = apperr.Forbidden("owner_required", "only an owner can grant, change or remove the owner role")
+	ErrLastOwner          = apperr.Conflict("last_owner", "an organization must keep at least one owner")
+	ErrInvalidInvitation  = apperr.BadRequest("invalid_team_invitation", "invalid or expired invitation")
+	ErrInvitationMismatch = apperr.Forbidden("invitation_mismatch", "invitation was sent to a different email address")
+	ErrNotSupplierAccount = apperr.Forbidden("not_a_supplier_account", "only supplier accounts can join an organization")
 )
 
 type Service struct {
-	repo Repository
+	repo    Repository
+	members MemberRepository
+	users   auth.UserRepository
+
+	invitations InvitationRepository
+	mailer      mail.Mailer
+	appBaseURL  string
+
+	cache *httpcache.Cache
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, members MemberRepository, users auth.UserRepository) *Service {
+	return &Service{repo: repo, members: members, users: users}
 }
 
-func (s *Service) List(ctx context.Context, limit, offset int) ([]*Supplier, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	return s.repo.List(ctx, limit, offset)
+// WithInvitations enables inviting users to join an organization by email.
+func (s *Service) WithInvitations(invitations InvitationRepository) {
+	s.invitations = invitations
+}
+
+// WithCache sets the response cache whose copies of a supplier profile are
+// dropped when it changes.
+func (s *Service) WithCache(cache *httpcache.Cache) {
+	s.cache = cache
+}
+
+// WithMailer sets the mailer used for invitation emails. appBaseURL is the
+// frontend origin the emailed links point to.
+func (s *Service) WithMailer(mailer mail.Mailer, appBaseURL string) {
+	s.mailer = mailer
+	s.appBaseURL = strings.TrimRight(appBaseURL, "/")
+}
+
+func (s *Service) List(ctx context.Context, page pagination.Params) (*pagination.Page[*Supplier], error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.List")
+	defer span.End()
+
+	return s.repo.List(ctx, page.Normalize(pagination.DefaultLimit))
 }
 
 func (s *Service) GetByID(ctx context.Context, id string) (*Supplier, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.GetByID")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
 func (s *Service) GetByUserID(ctx context.Context, userID string) (*Supplier, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.GetByUserID")
+	defer span.End()
 	return s.repo.GetByUserID(ctx, userID)
 }
 
+// SupplierMembership returns the organization userID belongs to and their
+// team role, or empty strings if they belong to none (see
+// authz.SupplierResolver).
+func (s *Service) SupplierMembership(ctx context.Context, userID string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.SupplierMembership")
+	defer span.End()
+
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotMember) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	return m.SupplierID, string(m.Role), nil
+}
+
+// membership returns the caller's membership. Profiles created before
+// organizations had members are adopted here: their creator becomes the owner.
+func (s *Service) membership(ctx context.Context, userID string) (*Member, error) {
+	m, err := s.members.GetByUserID(ctx, userID)
+	if err == nil {
+		return m, nil
+	}
+	if !errors.Is(err, ErrMemberNotFound) {
+		return nil, err
+	}
+
+	sup, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	m = &Member{SupplierID: sup.ID, UserID: userID, Role: MemberOwner}
+	if err := s.members.Add(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// actor returns the caller's membership if their team role grants p.
+func (s *Service) actor(ctx context.Context, userID string, p authz.Permission) (*Member, error) {
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !authz.MemberCan(string(m.Role), p) {
+		return nil, ErrTeamRoleForbidden
+	}
+	return m, nil
+}
+
+// GetMyProfile returns the organization the user belongs to.
+func (s *Service) GetMyProfile(ctx context.Context, userID string) (*Profile, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.GetMyProfile")
+	defer span.End()
+
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sup, err := s.repo.GetByID(ctx, m.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{Supplier: sup, MemberRole: m.Role}, nil
+}
+
+// Create creates an organization with userID as its owner.
 func (s *Service) Create(ctx context.Context, userID string, in CreateSupplierInput) (*Supplier, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.Create")
+	defer span.End()
+
+	if _, err := s.membership(ctx, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, ErrNotMember) {
+		return nil, err
+	}
+
 	sup := &Supplier{
 		UserID:       userID,
 		CompanyName:  in.CompanyName,
@@ -50,10 +189,16 @@ func (s *Service) Create(ctx context.Context, userID string, in CreateSupplierIn
 	if err := s.repo.Create(ctx, sup); err != nil {
 		return nil, err
 	}
+	if err := s.members.Add(ctx, &Member{SupplierID: sup.ID, UserID: userID, Role: MemberOwner}); err != nil {
+		return nil, err
+	}
 	return sup, nil
 }
 
 func (s *Service) Update(ctx context.Context, id string, in UpdateSupplierInput) (*Supplier, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.Update")
+	defer span.End()
+
 	sup, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -90,9 +235,263 @@ func (s *Service) Update(ctx context.Context, id string, in UpdateSupplierInput)
 	if err := s.repo.Update(ctx, sup); err != nil {
 		return nil, err
 	}
+	s.cache.Invalidate("supplier:" + id)
 	return sup, nil
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	ctx, span := tracing.Start(ctx, "supplier.Service.Delete")
+	defer span.End()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Invalidate("supplier:" + id)
+	return s.members.RemoveAll(ctx, id)
+}
+
+// ListMembers returns the members of the caller's organization.
+func (s *Service) ListMembers(ctx context.Context, userID string) ([]Member, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.ListMembers")
+	defer span.End()
+
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.members.List(ctx, m.SupplierID)
+}
+
+// UpdateMemberRole changes a member's team role. Only owners may grant the
+// owner role or change another owner, and the last owner cannot step down.
+func (s *Service) UpdateMemberRole(ctx context.Context, actorID, userID string, role MemberRole) (*Member, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.UpdateMemberRole")
+	defer span.End()
+
+	actor, err := s.actor(ctx, actorID, authz.SupplierMembersManage)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.members.Get(ctx, actor.SupplierID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if (target.Role == MemberOwner || role == MemberOwner) && actor.Role != MemberOwner {
+		return nil, ErrOwnerRequired
+	}
+	if target.Role == MemberOwner && role != MemberOwner {
+		if err := s.ensureOtherOwner(ctx, actor.SupplierID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.members.UpdateRole(ctx, actor.SupplierID, userID, role); err != nil {
+		return nil, err
+	}
+	return s.members.Get(ctx, actor.SupplierID, userID)
+}
+
+// RemoveMember removes a member from the caller's organization. Members may
+// always remove themselves (leave), except the last owner.
+func (s *Service) RemoveMember(ctx context.Context, actorID, userID string) error {
+	ctx, span := tracing.Start(ctx, "supplier.Service.RemoveMember")
+	defer span.End()
+
+	var actor *Member
+	var err error
+	if actorID == userID {
+		actor, err = s.membership(ctx, actorID)
+	} else {
+		actor, err = s.actor(ctx, actorID, authz.SupplierMembersManage)
+	}
+	if err != nil {
+		return err
+	}
+	target, err := s.members.Get(ctx, actor.SupplierID, userID)
+	if err != nil {
+		return err
+	}
+	if target.Role == MemberOwner {
+		if actor.Role != MemberOwner {
+			return ErrOwnerRequired
+		}
+		if err := s.ensureOtherOwner(ctx, actor.SupplierID); err != nil {
+			return err
+		}
+	}
+	return s.members.Remove(ctx, actor.SupplierID, userID)
+}
+
+func (s *Service) ensureOtherOwner(ctx context.Context, supplierID string) error {
+	n, err := s.members.CountByRole(ctx, supplierID, MemberOwner)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// InviteMember emails an expiring link to join the caller's organization with
+// the given team role. Earlier pending invitations for the same address are
+// revoked.
+func (s *Service) InviteMember(ctx context.Context, actorID string, in InviteMemberInput) (*MemberInvitation, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.InviteMember")
+	defer span.End()
+
+	actor, err := s.actor(ctx, actorID, authz.SupplierMembersManage)
+	if err != nil {
+		return nil, err
+	}
+	if in.Role == MemberOwner && actor.Role != MemberOwner {
+		return nil, ErrOwnerRequired
+	}
+	if u, err := s.users.GetByEmail(ctx, in.Email); err == nil {
+		if _, err := s.members.Get(ctx, actor.SupplierID, u.ID); err == nil {
+			return nil, ErrAlreadyMember
+		} else if !errors.Is(err, ErrMemberNotFound) {
+			return nil, err
+		}
+	} else if !errors.Is(err, auth.ErrUserNotFound) {
+		return nil, err
+	}
+	inviter, err := s.users.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	sup, err := s.repo.GetByID(ctx, actor.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.invitations.RevokePendingForEmail(ctx, actor.SupplierID, in.Email, now); err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	inv := &MemberInvitation{
+		SupplierID: actor.SupplierID,
+		Email:      in.Email,
+		Role:       in.Role,
+		InvitedBy:  actorID,
+		TokenHash:  hashToken(token),
+		ExpiresAt:  now.Add(memberInviteTTL),
+	}
+	if err := s.invitations.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	if s.mailer == nil {
+		return nil, errors.New("mailer not configured")
+	}
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("Join %s on Global Trade Hub", sup.CompanyName),
+		Body: fmt.Sprintf(
+			"Hello,\n\n%s invited you to join %s on Global Trade Hub as %s. Sign in with a supplier account using this email address and open the link below within %s:\n\n%s\n",
+			inviter.FullName, sup.CompanyName, inv.Role, memberInviteTTL,
+			s.appBaseURL+"/supplier-invitation?token="+url.QueryEscape(token),
+		),
+	}); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ListInvitations returns the pending invitations of the caller's
+// organization.
+func (s *Service) ListInvitations(ctx context.Context, actorID string) ([]MemberInvitation, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.ListInvitations")
+	defer span.End()
+
+	actor, err := s.actor(ctx, actorID, authz.SupplierMembersManage)
+	if err != nil {
+		return nil, err
+	}
+	return s.invitations.ListPending(ctx, actor.SupplierID, time.Now().UTC())
+}
+
+// RevokeInvitation invalidates a pending invitation of the caller's
+// organization.
+func (s *Service) RevokeInvitation(ctx context.Context, actorID, id string) error {
+	ctx, span := tracing.Start(ctx, "supplier.Service.RevokeInvitation")
+	defer span.End()
+
+	actor, err := s.actor(ctx, actorID, authz.SupplierMembersManage)
+	if err != nil {
+		return err
+	}
+	return s.invitations.Revoke(ctx, actor.SupplierID, id, time.Now().UTC())
+}
+
+// AcceptInvitation adds the signed-in user to the inviting organization. The
+// user must have a supplier account registered with the invited address and
+// must not belong to another organization.
+func (s *Service) AcceptInvitation(ctx context.Context, userID, token string) (*Profile, error) {
+	ctx, span := tracing.Start(ctx, "supplier.Service.AcceptInvitation")
+	defer span.End()
+
+	inv, err := s.invitations.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrInvitationNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if !inv.Pending(now) {
+		return nil, ErrInvalidInvitation
+	}
+
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(u.Email, inv.Email) {
+		return nil, ErrInvitationMismatch
+	}
+	if u.Role != auth.RoleSupplier {
+		return nil, ErrNotSupplierAccount
+	}
+	if _, err := s.membership(ctx, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, ErrNotMember) {
+		return nil, err
+	}
+
+	// Claim the invitation first so that it cannot be used twice.
+	if err := s.invitations.MarkAccepted(ctx, inv.ID, now); err != nil {
+		if errors.Is(err, ErrInvitationNotPending) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if err := s.members.Add(ctx, &Member{
+		SupplierID: inv.SupplierID,
+		UserID:     userID,
+		Role:       inv.Role,
+		InvitedBy:  inv.InvitedBy,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetMyProfile(ctx, userID)
+}
+
+// generateToken returns a random URL-safe invitation token.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 of an invitation token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
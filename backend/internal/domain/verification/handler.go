@@ -8,6 +8,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/domain/auth"
 	"github.com/example/global-trade-hub/backend/internal/http/middleware"
 )
@@ -30,7 +32,7 @@ func (h *Handler) List(c *gin.Context) {
 
 	verifications, err := h.svc.List(ctx, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -46,11 +48,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	verification, err := h.svc.GetByID(ctx, id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "verification not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -61,7 +59,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 func (h *Handler) GetMyVerification(c *gin.Context) {
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
@@ -71,11 +69,7 @@ func (h *Handler) GetMyVerification(c *gin.Context) {
 
 	verification, err := h.svc.GetBySupplierID(ctx, claims.UserID)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "verification not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -86,20 +80,20 @@ func (h *Handler) GetMyVerification(c *gin.Context) {
 func (h *Handler) Submit(c *gin.Context) {
 	var in SubmitVerificationInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
 	// Only suppliers can submit verification
 	if claims.Role != string(auth.RoleSupplier) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only suppliers can submit verification"})
+		_ = c.Error(apperr.Forbidden("supplier_only", "only suppliers can submit verification"))
 		return
 	}
 
@@ -108,7 +102,7 @@ func (h *Handler) Submit(c *gin.Context) {
 
 	verification, err := h.svc.Submit(ctx, claims.UserID, in)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -119,21 +113,20 @@ func (h *Handler) Submit(c *gin.Context) {
 func (h *Handler) Review(c *gin.Context) {
 	var in ReviewVerificationInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(apperr.Invalid(err))
 		return
 	}
 	id := c.Param("id")
 
 	raw, ok := c.Get("claims")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
+		_ = c.Error(apperr.ErrMissingClaims)
 		return
 	}
 	claims := raw.(*middleware.Claims)
 
-	// Only admin can review
-	if claims.Role != string(auth.RoleAdmin) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can review verifications"})
+	if !authz.Can(claims.Role, authz.VerificationsReview) {
+		_ = c.Error(authz.ErrAdminRequired)
 		return
 	}
 
@@ -142,11 +135,7 @@ func (h *Handler) Review(c *gin.Context) {
 
 	verification, err := h.svc.Review(ctx, id, claims.UserID, in)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "verification not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
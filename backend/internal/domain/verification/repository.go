@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
 )
 
 var (
-	ErrNotFound = errors.New("verification not found")
+	ErrNotFound = apperr.NotFound("verification_not_found", "verification not found")
 )
 
 type Repository interface {
@@ -3,6 +3,8 @@ package verification
 import (
 	"context"
 	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 )
 
 type Service struct {
@@ -14,6 +16,9 @@ func NewService(repo Repository) *Service {
 }
 
 func (s *Service) List(ctx context.Context, limit, offset int) ([]*Verification, error) {
+	ctx, span := tracing.Start(ctx, "verification.Service.List")
+	defer span.End()
+
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
@@ -24,14 +29,21 @@ func (s *Service) List(ctx context.Context, limit, offset int) ([]*Verification,
 }
 
 func (s *Service) GetByID(ctx context.Context, id string) (*Verification, error) {
+	ctx, span := tracing.Start(ctx, "verification.Service.GetByID")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
 func (s *Service) GetBySupplierID(ctx context.Context, supplierID string) (*Verification, error) {
+	ctx, span := tracing.Start(ctx, "verification.Service.GetBySupplierID")
+	defer span.End()
 	return s.repo.GetBySupplierID(ctx, supplierID)
 }
 
 func (s *Service) Submit(ctx context.Context, supplierID string, in SubmitVerificationInput) (*Verification, error) {
+	ctx, span := tracing.Start(ctx, "verification.Service.Submit")
+	defer span.End()
+
 	now := time.Now().UTC()
 
 	v := &Verification{
@@ -62,6 +74,9 @@ func (s *Service) Submit(ctx context.Context, supplierID string, in SubmitVerifi
 }
 
 func (s *Service) Review(ctx context.Context, id, reviewerID string, in ReviewVerificationInput) (*Verification, error) {
+	ctx, span := tracing.Start(ctx, "verification.Service.Review")
+	defer span.End()
+
 	v, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -81,5 +96,7 @@ func (s *Service) Review(ctx context.Context, id, reviewerID string, in ReviewVe
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "verification.Service.Delete")
+	defer span.End()
 	return s.repo.Delete(ctx, id)
 }
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Ping checks that db accepts connections.
+func Ping(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+
+// PoolSaturation warns when at least threshold (e.g. 0.9) of db's maximum
+// open connections are in use. Only a warning: failing readiness of every
+// instance at once under load would turn slowness into an outage.
+func PoolSaturation(db *sql.DB, threshold float64) Checker {
+	return CheckerFunc(func(context.Context) error {
+		s := db.Stats()
+		if s.MaxOpenConnections <= 0 {
+			return nil
+		}
+		if float64(s.InUse) >= threshold*float64(s.MaxOpenConnections) {
+			return Warn(fmt.Errorf("%d of %d connections in use, %d waits so far",
+				s.InUse, s.MaxOpenConnections, s.WaitCount))
+		}
+		return nil
+	})
+}
+
+// Heartbeat tracks a background worker, which calls Beat (or Beat with a
+// context carrying the heartbeat) on every iteration of its loop. The check
+// fails when the worker has not beaten for maxAge, e.g. because it is stuck
+// or has exited.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   atomic.Int64 // unix nanoseconds
+}
+
+// NewHeartbeat returns a heartbeat that expects a beat at least every
+// maxAge, starting now.
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{maxAge: maxAge}
+	h.Beat()
+	return h
+}
+
+// Beat records that the worker is alive.
+func (h *Heartbeat) Beat() {
+	if h != nil {
+		h.last.Store(time.Now().UnixNano())
+	}
+}
+
+func (h *Heartbeat) Check(context.Context) error {
+	if age := time.Since(time.Unix(0, h.last.Load())); age > h.maxAge {
+		return fmt.Errorf("no heartbeat for %s", age.Round(time.Millisecond))
+	}
+	return nil
+}
+
+type heartbeatKey struct{}
+
+// WithHeartbeat returns ctx carrying h, for the worker started with it.
+func WithHeartbeat(ctx context.Context, h *Heartbeat) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, h)
+}
+
+// Beat beats the heartbeat ctx carries, if any; workers call it so that they
+// need not know whether they are monitored.
+func Beat(ctx context.Context) {
+	if h, ok := ctx.Value(heartbeatKey{}).(*Heartbeat); ok {
+		h.Beat()
+	}
+}
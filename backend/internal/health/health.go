@@ -0,0 +1,225 @@
+// Package health serves the liveness and readiness probes.
+//
+// Liveness (/livez) only says the process is serving HTTP: a restart would
+// not fix a database outage, so it checks nothing else. Readiness (/readyz)
+// runs the checks registered by the subsystems the API depends on (database,
+// schema, background workers, ...) and fails while any of them does, or once
+// the server has started shutting down, so that load balancers stop sending
+// traffic. Callers with the health token get a report of every check.
+package health
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/logging"
+)
+
+// Checker checks a dependency; it returns nil when the dependency is usable.
+// An error wrapped with Warn is reported without failing readiness.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error { return f(ctx) }
+
+type warning struct{ err error }
+
+func (w warning) Error() string { return w.err.Error() }
+func (w warning) Unwrap() error { return w.err }
+
+// Warn marks err as a warning: a problem worth reporting that does not make
+// the server unable to serve, such as a busy connection pool.
+func Warn(err error) error {
+	if err == nil {
+		return nil
+	}
+	return warning{err}
+}
+
+// Statuses of checks and reports.
+const (
+	StatusOK       = "ok"
+	StatusWarn     = "warn"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"durationMs"`
+}
+
+// Report is the readiness of the server; Checks are only included for
+// callers with the health token.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Registry holds the readiness checks.
+type Registry struct {
+	timeout time.Duration
+	token   string
+
+	mu       sync.RWMutex
+	checkers []namedChecker
+
+	draining atomic.Bool
+	// ready is the last readiness reported, to log changes only.
+	ready atomic.Bool
+}
+
+// New returns an empty registry; each check gets timeout to complete.
+func New(timeout time.Duration) *Registry {
+	r := &Registry{timeout: timeout}
+	r.ready.Store(true)
+	return r
+}
+
+// WithToken sets the token that callers of /readyz send as "Authorization:
+// Bearer <token>" to get the result of every check. Without one, only the
+// overall status is served.
+func (r *Registry) WithToken(token string) {
+	r.token = token
+}
+
+// Register adds a readiness check. Names must be unique.
+func (r *Registry) Register(name string, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, nc := range r.checkers {
+		if nc.name == name {
+			panic(fmt.Sprintf("health: check %q registered twice", name))
+		}
+	}
+	r.checkers = append(r.checkers, namedChecker{name: name, checker: c})
+}
+
+// Drain makes readiness fail from now on, so that load balancers stop
+// routing new requests here before the server shuts down.
+func (r *Registry) Drain() {
+	if r != nil {
+		r.draining.Store(true)
+	}
+}
+
+// Run runs every check concurrently and returns the report.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]namedChecker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, nc := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, nc)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, res := range results {
+		if res.Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+	if r.draining.Load() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, nc namedChecker) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := nc.checker.Check(ctx)
+	res := Result{Name: nc.name, Status: StatusOK, DurationMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		res.Status, res.Error = StatusFail, err.Error()
+		if errors.As(err, new(warning)) {
+			res.Status = StatusWarn
+		}
+	}
+	return res
+}
+
+// Live serves the liveness probe.
+func (r *Registry) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Ready serves the readiness probe: 200 when every check passes, 503
+// otherwise. A nil Registry is always ready.
+func (r *Registry) Ready(c *gin.Context) {
+	if r == nil {
+		c.JSON(http.StatusOK, Report{Status: StatusOK})
+		return
+	}
+	detailed := false
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		got, ok := strings.CutPrefix(auth, "Bearer ")
+		if r.token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(r.token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="health"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		detailed = true
+	}
+
+	report := r.Run(c.Request.Context())
+	ready := report.Status == StatusOK
+	if r.ready.Swap(ready) != ready {
+		log := logging.FromContext(c.Request.Context())
+		if ready {
+			log.Info("health: ready")
+		} else {
+			log.Warn("health: not ready", "status", report.Status, "failing", failing(report))
+		}
+	}
+	if !detailed {
+		report.Checks = nil
+	}
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
+
+// failing returns the names of the failed checks with their errors.
+func failing(report Report) []string {
+	var out []string
+	for _, res := range report.Checks {
+		if res.Status == StatusFail {
+			out = append(out, res.Name+": "+res.Error)
+		}
+	}
+	return out
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+)
+
+// cachePolicy holds the public catalog reads served with validators (ETag,
+// Last-Modified) and kept in the response cache. Their handlers record the
+// version of what they render; entries tagged with an entity are dropped by
+// the services that change it, the others only expire. Only public routes
+// can be listed: responses are shared between all callers.
+var cachePolicy = httpcache.Policy{
+	// Reference data edited outside the API
+	"GET /api/v1/categories": {MaxAge: 5 * time.Minute, TTL: 10 * time.Minute},
+	"GET /api/v1/blog-posts": {MaxAge: 5 * time.Minute, TTL: 10 * time.Minute},
+	"GET /api/v1/faqs":       {MaxAge: 15 * time.Minute, TTL: 30 * time.Minute},
+
+	// Catalog entities, invalidated by product, supplier and admin services
+	"GET /api/v1/products/:id":  {MaxAge: time.Minute, TTL: 5 * time.Minute, Tags: httpcache.Tag("product:", "id")},
+	"GET /api/v1/suppliers/:id": {MaxAge: time.Minute, TTL: 5 * time.Minute, Tags: httpcache.Tag("supplier:", "id")},
+}
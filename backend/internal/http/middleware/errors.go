@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+)
+
+// ProblemContentType is the media type of error responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response (RFC 9457 problem details).
+// Code is stable and meant for clients to branch on or translate; Detail is
+// an English message for developers and logs.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	Errors    []apperr.FieldError `json:"errors,omitempty"`
+	RequestID string              `json:"requestId,omitempty"`
+}
+
+// ErrorHandler renders the last error a handler reported with c.Error as a
+// problem response, unless the handler already wrote a response. Client
+// errors (see apperr.Error) keep their status, code and message; anything
+// else becomes a generic 500 so that database and other internal messages
+// never reach the client. The full error stays in c.Errors for RequestLogger.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, apperr.From(c.Errors.Last().Err))
+	}
+}
+
+// Abort reports err and stops the chain; ErrorHandler renders the response.
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// ResponseStatus returns the status the response has or, when a handler
+// reported an error that ErrorHandler has not rendered yet, will have.
+func ResponseStatus(c *gin.Context) int {
+	if !c.Writer.Written() && len(c.Errors) > 0 {
+		return apperr.From(c.Errors.Last().Err).Status
+	}
+	return c.Writer.Status()
+}
+
+func writeProblem(c *gin.Context, e *apperr.Error) {
+	if e.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int((e.RetryAfter+time.Second-1)/time.Second)))
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(e.Status, Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      e.Code,
+		Errors:    e.Fields,
+		RequestID: c.GetString("request_id"),
+	})
+}
@@ -1,70 +1,503 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/logging"
 )
 
-// RequestLogger is a lightweight structured logger for all incoming requests.
-func RequestLogger(logger *log.Logger) gin.HandlerFunc {
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestID assigns every request an ID: the client's X-Request-ID if it is a
+// plausible ID, otherwise a new UUID. The ID is echoed in the response and a
+// logger carrying it is stored in the request context (see
+// logging.FromContext); JWTAuth later adds the caller to that logger.
+func RequestID(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := logging.WithLogger(c.Request.Context(), logger.With("request_id", id))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestLogger logs one line per request with the request context's logger
+// (see RequestID). 5xx responses are logged at error level with the errors
+// the handler reported (see ErrorHandler), which are never sent to the
+// client, and for panics the call stack (see Recovery).
+func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		method := c.Request.Method
 
 		c.Next()
 
-		latency := time.Since(start)
 		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		logger := logging.FromContext(c.Request.Context())
+		if status < http.StatusInternalServerError {
+			logger.Info("request", attrs...)
+			return
+		}
+
+		attrs = append(attrs, "error", strings.Join(c.Errors.Errors(), "; "), "handler", c.HandlerName())
+		if s, ok := c.Get(panicStackKey); ok {
+			attrs = append(attrs, "stack", s.([]string))
+		}
+		logger.Error("request failed", attrs...)
+	}
+}
+
+const panicStackKey = "panic_stack"
+
+// Recovery turns a panic in a handler into a 500 response. The panic value
+// and its stack are logged by RequestLogger.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			c.Set(panicStackKey, callerStack(3))
+			err := fmt.Errorf("panic: %v", r)
+			_ = c.Error(err)
+			writeProblem(c, apperr.From(err))
+		}()
+		c.Next()
+	}
+}
 
-		logger.Printf("%s %s -> %d (%s)", method, path, status, latency)
+// modulePrefix is the import path prefix of this module's packages; stacks
+// only keep frames under it.
+var modulePrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	if i := strings.Index(name, "/internal/"); i >= 0 {
+		return name[:i+1]
 	}
+	return ""
+}()
+
+// callerStack returns "function file:line" for this module's frames of the
+// current goroutine, skipping skip frames.
+func callerStack(skip int) []string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []string
+	for {
+		f, more := frames.Next()
+		if strings.HasPrefix(f.Function, modulePrefix) && !strings.HasPrefix(f.Function, modulePrefix+"internal/http/middleware.") {
+			out = append(out, fmt.Sprintf("%s %s:%d", strings.TrimPrefix(f.Function, modulePrefix), f.File, f.Line))
+		}
+		if !more {
+			return out
+		}
+	}
+}
+
+// Token types carried in the "typ" claim. Access and refresh tokens are signed
+// with the same key, so the type is what keeps a refresh token from being
+// accepted as an access token (and vice versa).
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeAPIKey marks claims built from an X-API-Key header rather
+	// than a JWT.
+	TokenTypeAPIKey = "api_key"
+)
+
+// ErrInvalidAPIKey is returned by an APIKeyAuthenticator for unknown,
+// revoked or expired keys.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyAuthenticator resolves an X-API-Key header into claims.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*Claims, error)
 }
 
-// Claims represents the JWT claims we expect in access tokens.
+// ErrSessionRevoked is returned by a SessionValidator when the session an
+// access token belongs to was signed out, revoked or has expired.
+var ErrSessionRevoked = errors.New("session revoked")
+
+// SessionValidator checks that the session ("sid") of an access token is
+// still active.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, claims *Claims) error
+}
+
+// Claims represents the JWT claims we expect in access and refresh tokens.
 type Claims struct {
-	UserID string `json:"uid"`
-	Role   string `json:"role"`
+	UserID    string `json:"uid"`
+	Role      string `json:"role"`
+	TokenType string `json:"typ"`
+	// SessionID identifies the login (refresh token family) the token belongs to.
+	SessionID string `json:"sid,omitempty"`
+	// EmailVerified is true once the user confirmed their email address.
+	EmailVerified bool `json:"ev,omitempty"`
+	// MFAEnrollmentRequired is set when the role must use 2FA by policy but
+	// the user has not enrolled yet. See RequireMFAEnrolled.
+	MFAEnrollmentRequired bool `json:"mfa_enroll,omitempty"`
+	// PasswordChangeRequired is set while the account still has to replace an
+	// operator-assigned password. See RequirePasswordChanged.
+	PasswordChangeRequired bool `json:"pcr,omitempty"`
+	// Actor is set on impersonation tokens: the admin acting as UserID
+	// (RFC 8693 "act" claim). SessionID is then the impersonation ID.
+	Actor *Actor `json:"act,omitempty"`
+	// APIKeyID and Scopes are only set for API key requests.
+	APIKeyID string   `json:"-"`
+	Scopes   []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
-// JWTAuth validates Bearer tokens, attaches claims to the context and rejects
-// unauthorized requests.
-func JWTAuth(secret string) gin.HandlerFunc {
+// Actor identifies who is really making an impersonated request.
+type Actor struct {
+	Subject string `json:"sub"`
+	Role    string `json:"role,omitempty"`
+}
+
+// Impersonated reports whether the token was issued to an admin acting as
+// the user.
+func (c *Claims) Impersonated() bool {
+	return c.Actor != nil && c.Actor.Subject != ""
+}
+
+// HasScope reports whether an API key request was granted scope. Token-based
+// requests are not scoped and always pass.
+func (c *Claims) HasScope(scope string) bool {
+	if c.TokenType != TokenTypeAPIKey {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestScope derives the scope an API key needs for the matched route:
+// "<first path segment under /api/v1>:<read|write>", e.g. GET
+// /api/v1/products/:id needs "products:read".
+func RequestScope(c *gin.Context) string {
+	path := strings.TrimPrefix(c.FullPath(), "/api/v1/")
+	resource, _, _ := strings.Cut(path, "/")
+
+	action := "write"
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		action = "read"
+	}
+	return resource + ":" + action
+}
+
+// JWTAuth validates Bearer tokens against the keyring (signature, "kid",
+// algorithm, issuer, audience and expiry) and, when sessions is set, rejects
+// tokens whose session was revoked. It attaches claims to the context and
+// rejects unauthorized requests. Requests may instead send an X-API-Key
+// header; such requests are limited to the key's scopes (see RequestScope).
+func JWTAuth(keys *jwtkeys.Keyring, apiKeys APIKeyAuthenticator, sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" && apiKeys != nil {
+			claims, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), apiKey, c.ClientIP())
+			if err != nil {
+				if errors.Is(err, ErrInvalidAPIKey) {
+					Abort(c, apperr.Unauthorized("invalid_api_key", "invalid API key"))
+					return
+				}
+				Abort(c, err)
+				return
+			}
+			if scope := RequestScope(c); !claims.HasScope(scope) {
+				Abort(c, apperr.Forbidden("missing_scope", "API key lacks scope "+scope))
+				return
+			}
+
+			setClaims(c, claims)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing Authorization header"})
+			Abort(c, apperr.Unauthorized("missing_token", "missing Authorization header"))
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid Authorization header"})
+			Abort(c, apperr.Unauthorized("invalid_token", "invalid Authorization header"))
 			return
 		}
 
 		tokenStr := parts[1]
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		if err := keys.Parse(tokenStr, claims); err != nil {
+			Abort(c, apperr.Unauthorized("invalid_token", "invalid or expired token"))
+			return
+		}
+		if claims.TokenType != TokenTypeAccess {
+			Abort(c, apperr.Unauthorized("invalid_token", "invalid token type"))
 			return
 		}
+		if sessions != nil {
+			if err := sessions.ValidateSession(c.Request.Context(), claims); err != nil {
+				if errors.Is(err, ErrSessionRevoked) {
+					Abort(c, apperr.Unauthorized("session_revoked", "session has been revoked"))
+					return
+				}
+				Abort(c, err)
+				return
+			}
+		}
 
 		// Attach claims to context for downstream handlers
-		c.Set("claims", claims)
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// setClaims stores the caller's claims for handlers and adds the caller to
+// the request's logger.
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set("claims", claims)
+
+	attrs := []any{"user_id", claims.UserID, "role", claims.Role}
+	if claims.APIKeyID != "" {
+		attrs = append(attrs, "api_key_id", claims.APIKeyID)
+	}
+	if claims.Impersonated() {
+		attrs = append(attrs, "actor_id", claims.Actor.Subject)
+	}
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), attrs...))
+}
+
+// RequirePasswordChanged blocks tokens that carry a pending forced password
+// change from every route except the given route templates (as returned by
+// gin's FullPath), e.g. the password change endpoint itself.
+func RequirePasswordChanged(allowedRoutes ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowedRoutes))
+	for _, r := range allowedRoutes {
+		allowed[r] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		raw, exists := c.Get("claims")
+		if !exists {
+			c.Next()
+			return
+		}
+		claims, ok := raw.(*Claims)
+		if !ok || !claims.PasswordChangeRequired {
+			c.Next()
+			return
+		}
+		if _, ok := allowed[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		Abort(c, apperr.Forbidden("password_change_required", "password change required"))
+	}
+}
+
+// ImpersonatedRequest is the audit record of one request made with an
+// impersonation token.
+type ImpersonatedRequest struct {
+	ImpersonationID string
+	ActorID         string
+	SubjectID       string
+	Method          string
+	Route           string // route template, e.g. /api/v1/orders/:id
+	Path            string // requested path with query string
+	Status          int
+	IPAddress       string
+	UserAgent       string
+	At              time.Time
+}
+
+// ImpersonationAuditor stores ImpersonatedRequest records.
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(ctx context.Context, req ImpersonatedRequest) error
+}
+
+// AuditImpersonation records every request made with an impersonation token,
+// including rejected ones, once the response status is known. It must run
+// after JWTAuth.
+func AuditImpersonation(auditor ImpersonationAuditor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, exists := c.Get("claims")
+		claims, ok := raw.(*Claims)
+		if !exists || !ok || !claims.Impersonated() {
+			c.Next()
+			return
+		}
+
 		c.Next()
+
+		req := ImpersonatedRequest{
+			ImpersonationID: claims.SessionID,
+			ActorID:         claims.Actor.Subject,
+			SubjectID:       claims.UserID,
+			Method:          c.Request.Method,
+			Route:           c.FullPath(),
+			Path:            c.Request.URL.RequestURI(),
+			Status:          ResponseStatus(c),
+			IPAddress:       c.ClientIP(),
+			UserAgent:       c.Request.UserAgent(),
+			At:              time.Now().UTC(),
+		}
+		// The request context may already be cancelled.
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := auditor.RecordImpersonatedRequest(ctx, req); err != nil {
+			logging.FromContext(c.Request.Context()).Error("impersonation audit failed",
+				"method", req.Method, "path", req.Path, "error", err)
+		}
 	}
 }
 
+// ErrImpersonationForbidden is reported for requests an impersonation token
+// may not make.
+var ErrImpersonationForbidden = apperr.Forbidden("impersonation_forbidden", "not allowed while impersonating a user")
+
+// ForbidWhileImpersonating makes impersonation tokens read-only: requests
+// other than GET, HEAD and OPTIONS are rejected unless their "METHOD
+// /route/template" key is among allowed, so routes added later are refused
+// until they are explicitly allowed.
+func ForbidWhileImpersonating(allowed ...string) gin.HandlerFunc {
+	exceptions := make(map[string]struct{}, len(allowed))
+	for _, r := range allowed {
+		exceptions[r] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		raw, exists := c.Get("claims")
+		claims, ok := raw.(*Claims)
+		if !exists || !ok || !claims.Impersonated() {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if _, ok := exceptions[c.Request.Method+" "+c.FullPath()]; !ok {
+			Abort(c, ErrImpersonationForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireMFAEnrolled blocks tokens of users who must enroll in two-factor
+// authentication from every route except the given route templates (the
+// enrollment endpoints themselves).
+func RequireMFAEnrolled(allowedRoutes ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowedRoutes))
+	for _, r := range allowedRoutes {
+		allowed[r] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		raw, exists := c.Get("claims")
+		if !exists {
+			c.Next()
+			return
+		}
+		claims, ok := raw.(*Claims)
+		if !ok || !claims.MFAEnrollmentRequired {
+			c.Next()
+			return
+		}
+		if _, ok := allowed[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		Abort(c, apperr.Forbidden("mfa_enrollment_required", "two-factor authentication enrollment required"))
+	}
+}
+
+// RequireVerifiedEmail rejects users who have not confirmed their email
+// address yet. The flag comes from the access token, so a user who just
+// verified has to refresh their tokens first.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, exists := c.Get("claims")
+		if !exists {
+			Abort(c, apperr.ErrMissingClaims)
+			return
+		}
+		claims, ok := raw.(*Claims)
+		if !ok {
+			Abort(c, apperr.Forbidden(apperr.CodeForbidden, "invalid auth claims"))
+			return
+		}
+		if !claims.EmailVerified {
+			Abort(c, apperr.Forbidden("email_not_verified", "email address not verified"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ErrInsufficientPermissions is reported when the caller's role or
+// permissions do not allow the request.
+var ErrInsufficientPermissions = apperr.Forbidden("insufficient_permissions", "insufficient permissions")
+
 // RequireRole ensures the authenticated user has one of the allowed roles.
 func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	roleSet := make(map[string]struct{}, len(allowedRoles))
@@ -75,22 +508,21 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		raw, exists := c.Get("claims")
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing auth claims"})
+			Abort(c, apperr.ErrMissingClaims)
 			return
 		}
 
 		claims, ok := raw.(*Claims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid auth claims"})
+			Abort(c, apperr.Forbidden(apperr.CodeForbidden, "invalid auth claims"))
 			return
 		}
 
 		if _, ok := roleSet[claims.Role]; !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			Abort(c, ErrInsufficientPermissions)
 			return
 		}
 
 		c.Next()
 	}
 }
-
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestForbidWhileImpersonating(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		method, path string
+		impersonated bool
+		want         int
+	}{
+		{name: "read", method: http.MethodGet, path: "/orders/1", impersonated: true, want: http.StatusOK},
+		{name: "head", method: http.MethodHead, path: "/orders/1", impersonated: true, want: http.StatusOK},
+		{name: "status change", method: http.MethodPatch, path: "/orders/1/status", impersonated: true, want: http.StatusForbidden},
+		{name: "create", method: http.MethodPost, path: "/verifications", impersonated: true, want: http.StatusForbidden},
+		{name: "replace", method: http.MethodPut, path: "/orders/1", impersonated: true, want: http.StatusForbidden},
+		{name: "delete", method: http.MethodDelete, path: "/orders/1", impersonated: true, want: http.StatusForbidden},
+		{name: "allowed exception", method: http.MethodPost, path: "/notifications/read-all", impersonated: true, want: http.StatusOK},
+		{name: "own token", method: http.MethodPatch, path: "/orders/1/status", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.Use(func(c *gin.Context) {
+				claims := &Claims{UserID: "user-1", Role: "buyer"}
+				if tt.impersonated {
+					claims.Actor = &Actor{Subject: "admin-1", Role: "admin"}
+				}
+				c.Set("claims", claims)
+			})
+			router.Use(ForbidWhileImpersonating("POST /notifications/read-all"))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/orders/:id", ok)
+			router.HEAD("/orders/:id", ok)
+			router.PUT("/orders/:id", ok)
+			router.DELETE("/orders/:id", ok)
+			router.PATCH("/orders/:id/status", ok)
+			router.POST("/verifications", ok)
+			router.POST("/notifications/read-all", ok)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package http
+
+import (
+	"github.com/example/global-trade-hub/backend/internal/domain/account"
+	"github.com/example/global-trade-hub/backend/internal/domain/admin"
+	"github.com/example/global-trade-hub/backend/internal/domain/apikey"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/domain/category"
+	"github.com/example/global-trade-hub/backend/internal/domain/cms"
+	"github.com/example/global-trade-hub/backend/internal/domain/favorite"
+	"github.com/example/global-trade-hub/backend/internal/domain/message"
+	"github.com/example/global-trade-hub/backend/internal/domain/notification"
+	"github.com/example/global-trade-hub/backend/internal/domain/order"
+	"github.com/example/global-trade-hub/backend/internal/domain/product"
+	"github.com/example/global-trade-hub/backend/internal/domain/review"
+	"github.com/example/global-trade-hub/backend/internal/domain/rfq"
+	"github.com/example/global-trade-hub/backend/internal/domain/search"
+	"github.com/example/global-trade-hub/backend/internal/domain/subscription"
+	"github.com/example/global-trade-hub/backend/internal/domain/supplier"
+	"github.com/example/global-trade-hub/backend/internal/domain/verification"
+	"github.com/example/global-trade-hub/backend/internal/health"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/openapi"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
+
+// apiInfo is the metadata of the OpenAPI document.
+var apiInfo = openapi.Info{
+	Title:   "Global Trade Hub API",
+	Version: "1.0.0",
+	Description: "B2B marketplace API. Errors are application/problem+json with a stable `code` " +
+		"(see API.md). Protected operations take a Bearer access token or an X-API-Key; " +
+		"`x-permission` names the permission they require.",
+}
+
+// Response envelopes the handlers build with gin.H.
+type (
+	items[T any] struct {
+		Items []T `json:"items"`
+	}
+	messageResponse struct {
+		Message string `json:"message"`
+	}
+	userResponse struct {
+		User *auth.User `json:"user"`
+	}
+	recoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	mfaEnabledResponse struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+		Token         string   `json:"token"`
+		RefreshToken  string   `json:"refreshToken"`
+	}
+	categoryResponse struct {
+		Category      *category.DBCategory      `json:"category"`
+		Subcategories []*category.DBSubcategory `json:"subcategories"`
+	}
+)
+
+// Query parameters.
+type (
+	pageQuery struct {
+		Limit  int `form:"limit" default:"20" doc:"Maximum number of items."`
+		Offset int `form:"offset" default:"0" doc:"Number of items to skip."`
+	}
+	longPageQuery struct {
+		Limit  int `form:"limit" default:"50" doc:"Maximum number of items."`
+		Offset int `form:"offset" default:"0" doc:"Number of items to skip."`
+	}
+	cursorQuery struct {
+		Limit  int    `form:"limit" default:"20" binding:"min=1,max=100" doc:"Maximum number of items."`
+		Cursor string `form:"cursor" doc:"nextCursor of the previous page."`
+		Total  bool   `form:"total" doc:"Count the items of the whole list."`
+		Offset int    `form:"offset" default:"0" doc:"Number of items to skip; ignored with a cursor." deprecated:"true"`
+	}
+	longCursorQuery struct {
+		Limit  int    `form:"limit" default:"50" binding:"min=1,max=100" doc:"Maximum number of items."`
+		Cursor string `form:"cursor" doc:"nextCursor of the previous page."`
+		Total  bool   `form:"total" doc:"Count the items of the whole list."`
+		Offset int    `form:"offset" default:"0" doc:"Number of items to skip; ignored with a cursor." deprecated:"true"`
+	}
+	daysQuery struct {
+		Days int `form:"days" default:"30" doc:"Number of days back from today."`
+	}
+	topQuery struct {
+		Limit int `form:"limit" default:"10" doc:"Maximum number of items."`
+	}
+	recentQuery struct {
+		Limit int `form:"limit" default:"20" doc:"Maximum number of items."`
+	}
+	exportQuery struct {
+		Format string `form:"format" default:"json" binding:"omitempty,oneof=json zip" doc:"json, or zip for one JSON file per section. Without it, Accept: application/zip selects zip."`
+	}
+	oidcCallbackQuery struct {
+		Code  string `form:"code" doc:"Authorization code from the identity provider."`
+		State string `form:"state"`
+		Error string `form:"error" doc:"Error code from the identity provider."`
+	}
+	impersonationsQuery struct {
+		UserID string `form:"userId" doc:"Only sessions impersonating this user."`
+		Limit  int    `form:"limit" default:"50"`
+		Offset int    `form:"offset" default:"0"`
+	}
+	searchQuery struct {
+		Q          string            `form:"q" doc:"Search text."`
+		Type       search.SearchType `form:"type" default:"text"`
+		CategoryID string            `form:"categoryId"`
+		Country    string            `form:"country"`
+		MinPrice   float64           `form:"minPrice"`
+		MaxPrice   float64           `form:"maxPrice"`
+		Verified   bool              `form:"verified" doc:"Only verified suppliers."`
+		Limit      int               `form:"limit" default:"20"`
+		Offset     int               `form:"offset" default:"0"`
+	}
+	adminProductsQuery struct {
+		Status   string `form:"status" default:"all"`
+		Category string `form:"category" default:"all"`
+		Limit    int    `form:"limit" default:"20"`
+		Offset   int    `form:"offset" default:"0"`
+	}
+	adminOrdersQuery struct {
+		Status        string `form:"status" default:"all"`
+		PaymentStatus string `form:"paymentStatus" default:"all"`
+		Limit         int    `form:"limit" default:"20"`
+		Offset        int    `form:"offset" default:"0"`
+	}
+	adminSuppliersQuery struct {
+		Status       string `form:"status" default:"all"`
+		Subscription string `form:"subscription" default:"all"`
+		Limit        int    `form:"limit" default:"20"`
+		Offset       int    `form:"offset" default:"0"`
+	}
+	adminVerificationsQuery struct {
+		Status string `form:"status" default:"all"`
+		Limit  int    `form:"limit" default:"20"`
+		Offset int    `form:"offset" default:"0"`
+	}
+)
+
+// Request headers.
+type idempotencyHeader struct {
+	Key string `header:"Idempotency-Key" binding:"omitempty,max=255" doc:"Unique value per operation; a retry with the same key replays the first response."`
+}
+
+// apiEnums lists the values of the enum types in the document.
+var apiEnums = []openapi.EnumType{
+	openapi.Enum(auth.RoleBuyer, auth.RoleSupplier, auth.RoleMarket, auth.RoleVisitor, auth.RoleAdmin, auth.RoleModerator, auth.RoleKYCReviewer, auth.RoleFinance),
+	openapi.Enum(auth.StatusActive, auth.StatusInactive, auth.StatusSuspended, auth.StatusDeleted),
+	openapi.Enum(supplier.PlanFree, supplier.PlanSilver, supplier.PlanGold, supplier.PlanDiamond),
+	openapi.Enum(supplier.StatusActive, supplier.StatusInactive, supplier.StatusSuspended, supplier.StatusPending),
+	openapi.Enum(supplier.MemberOwner, supplier.MemberManager, supplier.MemberSales, supplier.MemberViewer),
+	openapi.Enum(subscription.PlanFree, subscription.PlanSilver, subscription.PlanGold, subscription.PlanDiamond),
+	openapi.Enum(subscription.StatusActive, subscription.StatusCancelled, subscription.StatusExpired, subscription.StatusTrial),
+	openapi.Enum(order.StatusPending, order.StatusConfirmed, order.StatusProcessing, order.StatusShipped, order.StatusDelivered, order.StatusCancelled, order.StatusRefunded),
+	openapi.Enum(order.PaymentPending, order.PaymentPaid, order.PaymentFailed, order.PaymentRefunded),
+	openapi.Enum(rfq.StatusDraft, rfq.StatusSubmitted, rfq.StatusActive, rfq.StatusClosed, rfq.StatusCancelled),
+	openapi.Enum(rfq.ResponsePending, rfq.ResponseAccepted, rfq.ResponseRejected, rfq.ResponseCountered),
+	openapi.Enum(verification.StatusUnverified, verification.StatusPending, verification.StatusVerified, verification.StatusRejected, verification.StatusNeedsUpdate),
+	openapi.Enum(verification.IDTypePassport, verification.IDTypeNationalID),
+	openapi.Enum(notification.TypeSystem, notification.TypeBusiness, notification.TypeInteraction, notification.TypePromotional),
+	openapi.Enum(notification.PriorityLow, notification.PriorityMedium, notification.PriorityHigh, notification.PriorityCritical),
+	openapi.Enum(search.SearchTypeText, search.SearchTypeImage, search.SearchTypeVideo),
+}
+
+// apiOperations documents every route: like routePolicy, NewRouter refuses
+// to start if a registered route is missing here or an entry matches no
+// route. Request and response schemas are derived from the Go types named
+// here, so keep them in sync with what the handler binds and renders.
+var apiOperations = openapi.Operations{
+	// Service endpoints
+	"GET /livez": {ID: "livez", Tag: "system", Summary: "Liveness probe",
+		Description: "Succeeds while the process serves HTTP; checks no dependency.",
+		Responses:   map[int]any{200: health.Report{}}},
+	"GET /readyz": {ID: "readyz", Tag: "system", Summary: "Readiness probe",
+		Description: "Fails with 503 while a dependency check fails or the server is shutting down. With the health token as a Bearer token, the result of every check is included.",
+		Responses:   map[int]any{200: health.Report{}, 503: health.Report{}}},
+	"GET /metrics": {ID: "metrics", Tag: "system", Summary: "Prometheus metrics", Optional: true,
+		Description: "Requires the metrics token as a Bearer token.",
+		Responses:   map[int]any{200: openapi.Content{"text/plain": openapi.Binary{}}}},
+	"GET /.well-known/jwks.json": {ID: "jwks", Tag: "system", Summary: "Public keys that verify access tokens",
+		Responses: map[int]any{200: jwtkeys.JWKSet{}}},
+	"GET /api/v1/openapi.json": {ID: "openapi", Tag: "system", Summary: "This OpenAPI document",
+		Responses: map[int]any{200: map[string]any{}}},
+	"GET /api/v1/docs": {ID: "docs", Tag: "system", Summary: "Interactive API documentation",
+		Responses: map[int]any{200: openapi.Content{"text/html": openapi.Binary{}}}},
+
+	// Public content
+	"POST /api/v1/contact": {Tag: "content", Summary: "Send a message to the team",
+		Body: cms.CreateContactMessageInput{}, Responses: map[int]any{201: cms.ContactMessage{}}},
+	"GET /api/v1/blog-posts": {Tag: "content", Summary: "List blog posts",
+		Responses: map[int]any{200: items[*cms.BlogPost]{}, 304: nil}},
+	"GET /api/v1/blog-posts/:id": {Tag: "content", Summary: "Get a blog post",
+		Responses: map[int]any{200: cms.BlogPost{}}},
+	"GET /api/v1/faqs": {Tag: "content", Summary: "List FAQs",
+		Responses: map[int]any{200: items[*cms.FAQ]{}, 304: nil}},
+	"GET /api/v1/jobs": {Tag: "content", Summary: "List job openings",
+		Responses: map[int]any{200: items[*cms.Job]{}}},
+	"GET /api/v1/press-releases": {Tag: "content", Summary: "List press releases",
+		Responses: map[int]any{200: items[*cms.PressRelease]{}}},
+
+	// Auth
+	"POST /api/v1/auth/register": {Summary: "Register an account",
+		Body: auth.RegisterInput{}, Responses: map[int]any{201: auth.AuthResponse{}}},
+	"POST /api/v1/auth/login": {Summary: "Sign in with email and password",
+		Description: "Returns an MFA challenge instead of tokens when the account uses two-factor authentication.",
+		Body:        auth.LoginInput{}, Responses: map[int]any{200: openapi.OneOf(auth.AuthResponse{}, auth.MFAChallenge{})}},
+	"POST /api/v1/auth/login/2fa": {Summary: "Complete a sign-in with a TOTP or recovery code",
+		Body: auth.LoginMFAInput{}, Responses: map[int]any{200: auth.AuthResponse{}}},
+	"POST /api/v1/auth/refresh": {Summary: "Exchange a refresh token for a new token pair",
+		Body: auth.RefreshTokenInput{}, Responses: map[int]any{200: auth.AuthResponse{}}},
+	"POST /api/v1/auth/logout": {Summary: "Revoke a refresh token",
+		Body: auth.RefreshTokenInput{}, Responses: map[int]any{204: nil}},
+	"POST /api/v1/auth/logout-all": {Summary: "Sign out of every session",
+		Responses: map[int]any{204: nil}},
+	"POST /api/v1/auth/verify-email": {Summary: "Verify an email address",
+		Body: auth.VerifyEmailInput{}, Responses: map[int]any{200: userResponse{}}},
+	"POST /api/v1/auth/verify-email/resend": {Summary: "Send the verification email again",
+		Responses: map[int]any{202: nil}},
+	"POST /api/v1/auth/forgot-password": {Summary: "Email a password reset link",
+		Body: auth.ForgotPasswordInput{}, Responses: map[int]any{202: nil}},
+	"POST /api/v1/auth/reset-password": {Summary: "Set a new password with a reset token",
+		Body: auth.ResetPasswordInput{}, Responses: map[int]any{204: nil}},
+	"POST /api/v1/auth/accept-invitation": {Summary: "Accept a staff invitation",
+		Body: auth.AcceptInvitationInput{}, Responses: map[int]any{201: auth.AuthResponse{}}},
+	"GET /api/v1/auth/oidc/providers": {Summary: "List external identity providers",
+		Responses: map[int]any{200: items[auth.OIDCProvider]{}}},
+	"GET /api/v1/auth/oidc/:provider/authorize": {Summary: "Start a sign-in with an identity provider",
+		Responses: map[int]any{302: nil}},
+	"GET /api/v1/auth/oidc/:provider/callback": {Summary: "Identity provider callback",
+		Description: "Redirects to the frontend with a one-time code for /auth/oidc/exchange, or an error code.",
+		Query:       oidcCallbackQuery{}, Responses: map[int]any{302: nil}},
+	"POST /api/v1/auth/oidc/exchange": {Summary: "Exchange the one-time code of an OIDC sign-in",
+		Body: auth.OIDCExchangeInput{}, Responses: map[int]any{200: openapi.OneOf(auth.AuthResponse{}, auth.MFAChallenge{})}},
+
+	// Own account
+	"GET /api/v1/me": {Tag: "account", Summary: "Get the signed-in user",
+		Responses: map[int]any{200: auth.MeResponse{}}},
+	"PATCH /api/v1/me": {Tag: "account", Summary: "Update the profile",
+		Body: auth.UpdateProfileInput{}, Responses: map[int]any{200: auth.User{}}},
+	"DELETE /api/v1/me": {Tag: "account", Summary: "Schedule the account for deletion",
+		Body: auth.DeleteAccountInput{}, Responses: map[int]any{202: account.DeleteResult{}}},
+	"POST /api/v1/me/password": {Tag: "account", Summary: "Change the password",
+		Body: auth.ChangePasswordInput{}, Responses: map[int]any{200: auth.AuthResponse{}}},
+	"GET /api/v1/me/export": {Tag: "account", Summary: "Export the account's data",
+		Query:     exportQuery{},
+		Responses: map[int]any{200: openapi.Content{"application/json": account.Export{}, "application/zip": openapi.Binary{}}}},
+	"GET /api/v1/me/sessions": {Tag: "account", Summary: "List signed-in sessions",
+		Responses: map[int]any{200: items[auth.Session]{}}},
+	"DELETE /api/v1/me/sessions/:id": {Tag: "account", Summary: "Sign out a session",
+		Responses: map[int]any{204: nil}},
+	"POST /api/v1/me/2fa/totp/setup": {Tag: "account", Summary: "Start TOTP enrollment",
+		Responses: map[int]any{200: auth.MFAEnrollment{}}},
+	"POST /api/v1/me/2fa/totp/enable": {Tag: "account", Summary: "Confirm TOTP enrollment",
+		Body: auth.MFACodeInput{}, Responses: map[int]any{200: mfaEnabledResponse{}}},
+	"POST /api/v1/me/2fa/disable": {Tag: "account", Summary: "Turn two-factor authentication off",
+		Body: auth.DisableMFAInput{}, Responses: map[int]any{204: nil}},
+	"POST /api/v1/me/2fa/recovery-codes": {Tag: "account", Summary: "Replace the recovery codes",
+		Body: auth.MFACodeInput{}, Responses: map[int]any{200: recoveryCodesResponse{}}},
+	"GET /api/v1/me/api-keys": {Tag: "api-keys", Summary: "List API keys",
+		Responses: map[int]any{200: items[*apikey.APIKey]{}}},
+	"POST /api/v1/me/api-keys": {Tag: "api-keys", Summary: "Create an API key",
+		Description: "The key itself is only returned here.",
+		Body:        apikey.CreateInput{}, Responses: map[int]any{201: apikey.CreatedKey{}}},
+	"GET /api/v1/me/api-keys/:id": {Tag: "api-keys", Summary: "Get an API key",
+		Responses: map[int]any{200: apikey.APIKey{}}},
+	"PATCH /api/v1/me/api-keys/:id": {Tag: "api-keys", Summary: "Update an API key",
+		Body: apikey.UpdateInput{}, Responses: map[int]any{200: apikey.APIKey{}}},
+	"DELETE /api/v1/me/api-keys/:id": {Tag: "api-keys", Summary: "Revoke an API key",
+		Responses: map[int]any{204: nil}},
+
+	// Products
+	"GET /api/v1/products": {Summary: "List products",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*product.Product]{}}},
+	"GET /api/v1/products/:id": {Summary: "Get a product",
+		Responses: map[int]any{200: product.Product{}, 304: nil}},
+	"POST /api/v1/products": {Summary: "Create a product",
+		Body: product.CreateInput{}, Responses: map[int]any{201: product.Product{}}},
+	"PUT /api/v1/products/:id": {Summary: "Update a product",
+		Body: product.UpdateInput{}, Responses: map[int]any{200: product.Product{}}},
+	"DELETE /api/v1/products/:id": {Summary: "Delete a product",
+		Responses: map[int]any{204: nil}},
+	"GET /api/v1/products/:id/reviews": {Tag: "reviews", Summary: "List reviews of a product",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*review.Review]{}}},
+
+	// Suppliers
+	"GET /api/v1/suppliers": {Summary: "List suppliers",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*supplier.Supplier]{}}},
+	"GET /api/v1/suppliers/:id": {Summary: "Get a supplier",
+		Responses: map[int]any{200: supplier.Supplier{}, 304: nil}},
+	"GET /api/v1/suppliers/me": {Summary: "Get the caller's supplier profile",
+		Responses: map[int]any{200: supplier.Profile{}}},
+	"POST /api/v1/suppliers": {Summary: "Create a supplier profile",
+		Body: supplier.CreateSupplierInput{}, Responses: map[int]any{201: supplier.Supplier{}}},
+	"PUT /api/v1/suppliers/:id": {Summary: "Update a supplier profile",
+		Body: supplier.UpdateSupplierInput{}, Responses: map[int]any{200: supplier.Supplier{}}},
+	"DELETE /api/v1/suppliers/:id": {Summary: "Delete a supplier profile",
+		Responses: map[int]any{204: nil}},
+	"GET /api/v1/suppliers/:id/reviews": {Tag: "reviews", Summary: "List reviews of a supplier",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*review.Review]{}}},
+	"GET /api/v1/suppliers/me/members": {Tag: "supplier-team", Summary: "List team members",
+		Responses: map[int]any{200: items[supplier.Member]{}}},
+	"PATCH /api/v1/suppliers/me/members/:userId": {Tag: "supplier-team", Summary: "Change a member's role",
+		Body: supplier.UpdateMemberInput{}, Responses: map[int]any{200: supplier.Member{}}},
+	"DELETE /api/v1/suppliers/me/members/:userId": {Tag: "supplier-team", Summary: "Remove a member",
+		Responses: map[int]any{204: nil}},
+	"GET /api/v1/suppliers/me/invitations": {Tag: "supplier-team", Summary: "List team invitations",
+		Responses: map[int]any{200: items[supplier.MemberInvitation]{}}},
+	"POST /api/v1/suppliers/me/invitations": {Tag: "supplier-team", Summary: "Invite a member",
+		Body: supplier.InviteMemberInput{}, Responses: map[int]any{201: supplier.MemberInvitation{}}},
+	"DELETE /api/v1/suppliers/me/invitations/:id": {Tag: "supplier-team", Summary: "Revoke an invitation",
+		Responses: map[int]any{204: nil}},
+	"POST /api/v1/suppliers/invitations/accept": {Tag: "supplier-team", Summary: "Join a team",
+		Body: supplier.AcceptInvitationInput{}, Responses: map[int]any{200: supplier.Profile{}}},
+
+	// Orders
+	"GET /api/v1/orders": {Summary: "List the caller's orders",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*order.Order]{}}},
+	"GET /api/v1/orders/:id": {Summary: "Get an order",
+		Responses: map[int]any{200: order.Order{}}},
+	"POST /api/v1/orders": {Summary: "Place an order",
+		Description: "Requires a verified email address.",
+		Header:      idempotencyHeader{}, Body: order.CreateOrderInput{}, Responses: map[int]any{201: order.Order{}}},
+	"PATCH /api/v1/orders/:id/status": {Summary: "Update an order's status",
+		Body: order.UpdateOrderStatusInput{}, Responses: map[int]any{200: order.Order{}}},
+	"GET /api/v1/orders/supplier/:supplierId": {Summary: "List a supplier's orders",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*order.Order]{}}},
+
+	// RFQs
+	"GET /api/v1/rfqs": {Summary: "List the caller's requests for quotation",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*rfq.RFQ]{}}},
+	"POST /api/v1/rfqs": {Summary: "Create a request for quotation",
+		Description: "Requires a verified email address.",
+		Header:      idempotencyHeader{}, Body: rfq.CreateRFQInput{}, Responses: map[int]any{201: rfq.RFQ{}}},
+	"GET /api/v1/rfqs/:id": {Summary: "Get a request for quotation",
+		Responses: map[int]any{200: rfq.RFQ{}}},
+	"GET /api/v1/rfqs/:id/responses": {Summary: "List the quotes for a request",
+		Responses: map[int]any{200: items[*rfq.RFQResponse]{}}},
+	"POST /api/v1/rfqs/responses": {Summary: "Quote on a request",
+		Header: idempotencyHeader{}, Body: rfq.CreateRFQResponseInput{}, Responses: map[int]any{201: rfq.RFQResponse{}}},
+
+	// Notifications
+	"GET /api/v1/notifications": {Summary: "List notifications",
+		Query: longCursorQuery{}, Responses: map[int]any{200: pagination.Page[*notification.Notification]{}}},
+	"PATCH /api/v1/notifications/:id/read": {Summary: "Mark a notification as read",
+		Responses: map[int]any{204: nil}},
+	"POST /api/v1/notifications/read-all": {Summary: "Mark all notifications as read",
+		Responses: map[int]any{204: nil}},
+	"DELETE /api/v1/notifications/:id": {Summary: "Delete a notification",
+		Responses: map[int]any{204: nil}},
+
+	// Verifications
+	"GET /api/v1/verifications/me": {Summary: "Get the caller's verification",
+		Responses: map[int]any{200: verification.Verification{}}},
+	"POST /api/v1/verifications": {Summary: "Submit documents for verification",
+		Body: verification.SubmitVerificationInput{}, Responses: map[int]any{201: verification.Verification{}}},
+
+	// Categories
+	"GET /api/v1/categories": {Summary: "List categories",
+		Responses: map[int]any{200: items[*category.DBCategory]{}, 304: nil}},
+	"GET /api/v1/categories/:id": {Summary: "Get a category and its subcategories",
+		Responses: map[int]any{200: categoryResponse{}}},
+
+	// Reviews, favorites, search
+	"POST /api/v1/reviews": {Summary: "Review a product",
+		Body: review.CreateInput{}, Responses: map[int]any{201: review.Review{}}},
+	"GET /api/v1/favorites": {Summary: "List favorite products",
+		Query: longCursorQuery{}, Responses: map[int]any{200: pagination.Page[*favorite.Favorite]{}}},
+	"POST /api/v1/favorites/:productId": {Summary: "Add a product to favorites",
+		Responses: map[int]any{201: favorite.Favorite{}}},
+	"DELETE /api/v1/favorites/:productId": {Summary: "Remove a product from favorites",
+		Responses: map[int]any{204: nil}},
+	"GET /api/v1/search": {Summary: "Search products and suppliers",
+		Query: searchQuery{}, Responses: map[int]any{200: search.SearchResponse{}}},
+	"GET /api/v1/search/history": {Summary: "List recent searches",
+		Query: pageQuery{}, Responses: map[int]any{200: items[*search.SearchHistory]{}}},
+	"POST /api/v1/search/history": {Summary: "Record a search",
+		Body: search.RecordHistoryInput{}, Responses: map[int]any{204: nil}},
+
+	// Subscriptions
+	"GET /api/v1/subscriptions/me": {Summary: "Get the caller's subscription",
+		Responses: map[int]any{200: subscription.Subscription{}}},
+	"POST /api/v1/subscriptions": {Summary: "Subscribe to a plan",
+		Header: idempotencyHeader{}, Body: subscription.CreateSubscriptionInput{}, Responses: map[int]any{201: subscription.Subscription{}}},
+	"PATCH /api/v1/subscriptions/:id/cancel": {Summary: "Cancel a subscription",
+		Responses: map[int]any{200: subscription.Subscription{}}},
+
+	// Messages
+	"GET /api/v1/messages/conversations": {Summary: "List conversations",
+		Responses: map[int]any{200: items[*message.ConversationPreview]{}}},
+	"GET /api/v1/messages/conversations/:conversationId": {Summary: "List the messages of a conversation",
+		Query: longCursorQuery{}, Responses: map[int]any{200: pagination.Page[*message.Message]{}}},
+	"GET /api/v1/messages/:id": {Summary: "Get a message",
+		Responses: map[int]any{200: message.Message{}}},
+	"POST /api/v1/messages": {Summary: "Send a message",
+		Body: message.CreateMessageInput{}, Responses: map[int]any{201: message.Message{}}},
+	"PATCH /api/v1/messages/:id/read": {Summary: "Mark a message as read",
+		Responses: map[int]any{204: nil}},
+	"DELETE /api/v1/messages/:id": {Summary: "Delete a message",
+		Responses: map[int]any{204: nil}},
+
+	// Admin
+	"GET /api/v1/admin/dashboard/stats": {Summary: "Dashboard totals",
+		Responses: map[int]any{200: admin.DashboardStats{}}},
+	"GET /api/v1/admin/dashboard/sales": {Summary: "Daily sales",
+		Query: daysQuery{}, Responses: map[int]any{200: items[*admin.SalesData]{}}},
+	"GET /api/v1/admin/dashboard/categories": {Summary: "Sales by category",
+		Responses: map[int]any{200: items[*admin.CategoryStats]{}}},
+	"GET /api/v1/admin/dashboard/top-products": {Summary: "Best-selling products",
+		Query: topQuery{}, Responses: map[int]any{200: items[*admin.TopProduct]{}}},
+	"GET /api/v1/admin/dashboard/user-stats": {Summary: "Daily sign-ups",
+		Query: daysQuery{}, Responses: map[int]any{200: items[*admin.UserStats]{}}},
+	"GET /api/v1/admin/dashboard/activities": {Summary: "Recent activity",
+		Query: recentQuery{}, Responses: map[int]any{200: items[*admin.RecentActivity]{}}},
+
+	"GET /api/v1/admin/buyers": {Summary: "List buyers",
+		Query: pageQuery{}, Responses: map[int]any{200: items[*admin.BuyerListItem]{}}},
+	"PATCH /api/v1/admin/users/:userId/status": {Summary: "Change a user's status",
+		Body: admin.UpdateUserStatusInput{}, Responses: map[int]any{200: messageResponse{}}},
+	"POST /api/v1/admin/users/:userId/unlock": {Summary: "Clear a user's failed sign-in lockout",
+		Responses: map[int]any{204: nil}},
+	"GET /api/v1/admin/users/:userId/sessions": {Summary: "List a user's sessions",
+		Responses: map[int]any{200: items[auth.Session]{}}},
+	"DELETE /api/v1/admin/users/:userId/sessions": {Summary: "Sign a user out everywhere",
+		Responses: map[int]any{204: nil}},
+	"DELETE /api/v1/admin/users/:userId/sessions/:sessionId": {Summary: "Sign out one of a user's sessions",
+		Responses: map[int]any{204: nil}},
+	"POST /api/v1/admin/users/:userId/impersonate": {Summary: "Sign in as a user",
+		Body: auth.ImpersonateInput{}, Responses: map[int]any{201: auth.ImpersonationGrant{}}},
+	"GET /api/v1/admin/impersonations": {Summary: "List impersonation sessions",
+		Query: impersonationsQuery{}, Responses: map[int]any{200: items[auth.Impersonation]{}}},
+	"GET /api/v1/admin/impersonations/:id/requests": {Summary: "List the requests of an impersonation session",
+		Responses: map[int]any{200: items[auth.ImpersonatedRequest]{}}},
+	"DELETE /api/v1/admin/impersonations/:id": {Summary: "End an impersonation session",
+		Responses: map[int]any{204: nil}},
+
+	"GET /api/v1/admin/staff": {Summary: "List staff",
+		Responses: map[int]any{200: items[auth.User]{}}},
+	"PATCH /api/v1/admin/staff/:userId/role": {Summary: "Change a staff member's role",
+		Body: auth.UpdateStaffRoleInput{}, Responses: map[int]any{200: auth.User{}}},
+	"PATCH /api/v1/admin/staff/:userId/status": {Summary: "Change a staff member's status",
+		Body: auth.UpdateStaffStatusInput{}, Responses: map[int]any{200: auth.User{}}},
+	"GET /api/v1/admin/staff/invitations": {Summary: "List staff invitations",
+		Responses: map[int]any{200: items[auth.StaffInvitation]{}}},
+	"POST /api/v1/admin/staff/invitations": {Summary: "Invite a staff member",
+		Body: auth.InviteStaffInput{}, Responses: map[int]any{201: auth.StaffInvitation{}}},
+	"DELETE /api/v1/admin/staff/invitations/:id": {Summary: "Revoke a staff invitation",
+		Responses: map[int]any{204: nil}},
+
+	"GET /api/v1/admin/products": {Summary: "List products",
+		Query: adminProductsQuery{}, Responses: map[int]any{200: items[*admin.AdminProduct]{}}},
+	"PATCH /api/v1/admin/products/:productId/status": {Summary: "Change a product's status",
+		Body: admin.UpdateProductStatusInput{}, Responses: map[int]any{200: messageResponse{}}},
+	"DELETE /api/v1/admin/products/:productId": {Summary: "Delete a product",
+		Responses: map[int]any{200: messageResponse{}}},
+
+	"GET /api/v1/admin/orders": {Summary: "List orders",
+		Query: adminOrdersQuery{}, Responses: map[int]any{200: items[*admin.AdminOrder]{}}},
+	"PATCH /api/v1/admin/orders/:orderId/status": {Summary: "Change an order's status",
+		Body: admin.UpdateOrderStatusInput{}, Responses: map[int]any{200: messageResponse{}}},
+	"DELETE /api/v1/admin/orders/:id": {Summary: "Delete an order",
+		Responses: map[int]any{204: nil}},
+
+	"GET /api/v1/admin/suppliers": {Summary: "List suppliers",
+		Query: adminSuppliersQuery{}, Responses: map[int]any{200: items[*admin.AdminSupplier]{}}},
+	"PATCH /api/v1/admin/suppliers/:supplierId/status": {Summary: "Change a supplier's status",
+		Body: admin.UpdateSupplierStatusInput{}, Responses: map[int]any{200: messageResponse{}}},
+
+	"GET /api/v1/admin/verifications": {Summary: "List verifications",
+		Query: adminVerificationsQuery{}, Responses: map[int]any{200: items[*admin.AdminVerification]{}}},
+	"GET /api/v1/admin/verifications/:id": {Summary: "Get a verification",
+		Responses: map[int]any{200: verification.Verification{}}},
+	"POST /api/v1/admin/verifications/:verificationId/review": {Summary: "Approve or reject a verification",
+		Body: admin.ReviewVerificationInput{}, Responses: map[int]any{200: messageResponse{}}},
+	"PATCH /api/v1/admin/verifications/:id/review": {Summary: "Review a verification",
+		Body: verification.ReviewVerificationInput{}, Responses: map[int]any{200: verification.Verification{}}},
+
+	"GET /api/v1/admin/rfqs": {Summary: "List all requests for quotation",
+		Query: cursorQuery{}, Responses: map[int]any{200: pagination.Page[*rfq.RFQ]{}}},
+
+	"GET /api/v1/admin/subscriptions": {Summary: "List subscriptions",
+		Query: pageQuery{}, Responses: map[int]any{200: items[*subscription.Subscription]{}}},
+	"GET /api/v1/admin/subscriptions/:id": {Summary: "Get a subscription",
+		Responses: map[int]any{200: subscription.Subscription{}}},
+	"DELETE /api/v1/admin/subscriptions/:id": {Summary: "Delete a subscription",
+		Responses: map[int]any{204: nil}},
+}
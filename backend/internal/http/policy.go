@@ -0,0 +1,179 @@
+package http
+
+import "github.com/example/global-trade-hub/backend/internal/authz"
+
+// apiPrefix is the path prefix whose routes must all appear in routePolicy.
+const apiPrefix = "/api/v1/"
+
+// routePolicy is the route/permission matrix: what every API route requires.
+// NewRouter refuses to start if a registered route is missing here (or an
+// entry matches no route), so new endpoints must be classified explicitly.
+// Ownership of individual resources is checked by the handlers on top.
+var routePolicy = authz.Policy{
+	// Public content
+	"POST /api/v1/contact":              authz.Public,
+	"GET /api/v1/blog-posts":            authz.Public,
+	"GET /api/v1/blog-posts/:id":        authz.Public,
+	"GET /api/v1/faqs":                  authz.Public,
+	"GET /api/v1/jobs":                  authz.Public,
+	"GET /api/v1/press-releases":        authz.Public,
+	"GET /api/v1/products":              authz.Public,
+	"GET /api/v1/products/:id":          authz.Public,
+	"GET /api/v1/suppliers":             authz.Public,
+	"GET /api/v1/suppliers/:id":         authz.Public,
+	"GET /api/v1/categories":            authz.Public,
+	"GET /api/v1/categories/:id":        authz.Public,
+	"GET /api/v1/products/:id/reviews":  authz.Public,
+	"GET /api/v1/suppliers/:id/reviews": authz.Public,
+	"GET /api/v1/search":                authz.Public,
+	"GET /api/v1/openapi.json":          authz.Public,
+	"GET /api/v1/docs":                  authz.Public,
+
+	// Auth
+	"POST /api/v1/auth/register":                authz.Public,
+	"POST /api/v1/auth/login":                   authz.Public,
+	"POST /api/v1/auth/login/2fa":               authz.Public,
+	"POST /api/v1/auth/refresh":                 authz.Public,
+	"POST /api/v1/auth/logout":                  authz.Public,
+	"POST /api/v1/auth/verify-email":            authz.Public,
+	"POST /api/v1/auth/forgot-password":         authz.Public,
+	"POST /api/v1/auth/reset-password":          authz.Public,
+	"GET /api/v1/auth/oidc/providers":           authz.Public,
+	"GET /api/v1/auth/oidc/:provider/authorize": authz.Public,
+	"GET /api/v1/auth/oidc/:provider/callback":  authz.Public,
+	"POST /api/v1/auth/oidc/exchange":           authz.Public,
+	"POST /api/v1/auth/accept-invitation":       authz.Public,
+	"POST /api/v1/auth/logout-all":              authz.Authenticated,
+	"POST /api/v1/auth/verify-email/resend":     authz.Authenticated,
+
+	// Own account
+	"GET /api/v1/me":                     authz.Authenticated,
+	"PATCH /api/v1/me":                   authz.Authenticated,
+	"DELETE /api/v1/me":                  authz.Authenticated,
+	"POST /api/v1/me/password":           authz.Authenticated,
+	"GET /api/v1/me/export":              authz.Authenticated,
+	"GET /api/v1/me/sessions":            authz.Authenticated,
+	"DELETE /api/v1/me/sessions/:id":     authz.Authenticated,
+	"POST /api/v1/me/2fa/totp/setup":     authz.Authenticated,
+	"POST /api/v1/me/2fa/totp/enable":    authz.Authenticated,
+	"POST /api/v1/me/2fa/disable":        authz.Authenticated,
+	"POST /api/v1/me/2fa/recovery-codes": authz.Authenticated,
+	"GET /api/v1/me/api-keys":            authz.Authenticated,
+	"POST /api/v1/me/api-keys":           authz.Authenticated,
+	"GET /api/v1/me/api-keys/:id":        authz.Authenticated,
+	"PATCH /api/v1/me/api-keys/:id":      authz.Authenticated,
+	"DELETE /api/v1/me/api-keys/:id":     authz.Authenticated,
+
+	// Products
+	"POST /api/v1/products":       authz.ProductsWrite,
+	"PUT /api/v1/products/:id":    authz.ProductsWrite,
+	"DELETE /api/v1/products/:id": authz.ProductsWrite,
+
+	// Suppliers
+	"GET /api/v1/suppliers/me":     authz.Authenticated,
+	"POST /api/v1/suppliers":       authz.SuppliersWrite,
+	"PUT /api/v1/suppliers/:id":    authz.SuppliersWrite,
+	"DELETE /api/v1/suppliers/:id": authz.SuppliersWrite,
+
+	// Supplier team; team roles are checked by the supplier service
+	"GET /api/v1/suppliers/me/members":            authz.SuppliersWrite,
+	"PATCH /api/v1/suppliers/me/members/:userId":  authz.SuppliersWrite,
+	"DELETE /api/v1/suppliers/me/members/:userId": authz.SuppliersWrite,
+	"GET /api/v1/suppliers/me/invitations":        authz.SuppliersWrite,
+	"POST /api/v1/suppliers/me/invitations":       authz.SuppliersWrite,
+	"DELETE /api/v1/suppliers/me/invitations/:id": authz.SuppliersWrite,
+	"POST /api/v1/suppliers/invitations/accept":   authz.Authenticated,
+
+	// Orders
+	"GET /api/v1/orders":                      authz.Authenticated,
+	"GET /api/v1/orders/:id":                  authz.Authenticated,
+	"POST /api/v1/orders":                     authz.OrdersCreate,
+	"PATCH /api/v1/orders/:id/status":         authz.OrdersUpdateStatus,
+	"GET /api/v1/orders/supplier/:supplierId": authz.OrdersUpdateStatus,
+
+	// RFQs
+	"GET /api/v1/rfqs":               authz.Authenticated,
+	"POST /api/v1/rfqs":              authz.RFQsCreate,
+	"GET /api/v1/rfqs/:id":           authz.Authenticated,
+	"GET /api/v1/rfqs/:id/responses": authz.Authenticated,
+	"POST /api/v1/rfqs/responses":    authz.RFQsRespond,
+
+	// Notifications
+	"GET /api/v1/notifications":            authz.Authenticated,
+	"PATCH /api/v1/notifications/:id/read": authz.Authenticated,
+	"POST /api/v1/notifications/read-all":  authz.Authenticated,
+	"DELETE /api/v1/notifications/:id":     authz.Authenticated,
+
+	// Verifications
+	"GET /api/v1/verifications/me": authz.VerificationsSubmit,
+	"POST /api/v1/verifications":   authz.VerificationsSubmit,
+
+	// Reviews, favorites, search history
+	"POST /api/v1/reviews":                authz.ReviewsCreate,
+	"GET /api/v1/favorites":               authz.Authenticated,
+	"POST /api/v1/favorites/:productId":   authz.Authenticated,
+	"DELETE /api/v1/favorites/:productId": authz.Authenticated,
+	"GET /api/v1/search/history":          authz.Authenticated,
+	"POST /api/v1/search/history":         authz.Authenticated,
+
+	// Subscriptions
+	"GET /api/v1/subscriptions/me":           authz.SubscriptionsPurchase,
+	"POST /api/v1/subscriptions":             authz.SubscriptionsPurchase,
+	"PATCH /api/v1/subscriptions/:id/cancel": authz.SubscriptionsPurchase,
+
+	// Messages
+	"GET /api/v1/messages/conversations":                 authz.Authenticated,
+	"GET /api/v1/messages/conversations/:conversationId": authz.Authenticated,
+	"GET /api/v1/messages/:id":                           authz.Authenticated,
+	"POST /api/v1/messages":                              authz.Authenticated,
+	"PATCH /api/v1/messages/:id/read":                    authz.Authenticated,
+	"DELETE /api/v1/messages/:id":                        authz.Authenticated,
+
+	// Admin
+	"GET /api/v1/admin/dashboard/stats":        authz.DashboardView,
+	"GET /api/v1/admin/dashboard/sales":        authz.DashboardView,
+	"GET /api/v1/admin/dashboard/categories":   authz.DashboardView,
+	"GET /api/v1/admin/dashboard/top-products": authz.DashboardView,
+	"GET /api/v1/admin/dashboard/user-stats":   authz.DashboardView,
+	"GET /api/v1/admin/dashboard/activities":   authz.DashboardView,
+
+	"GET /api/v1/admin/buyers":                               authz.UsersManage,
+	"PATCH /api/v1/admin/users/:userId/status":               authz.UsersManage,
+	"POST /api/v1/admin/users/:userId/unlock":                authz.UsersManage,
+	"GET /api/v1/admin/users/:userId/sessions":               authz.UsersManage,
+	"DELETE /api/v1/admin/users/:userId/sessions":            authz.UsersManage,
+	"DELETE /api/v1/admin/users/:userId/sessions/:sessionId": authz.UsersManage,
+	"POST /api/v1/admin/users/:userId/impersonate":           authz.UsersImpersonate,
+	"GET /api/v1/admin/impersonations":                       authz.UsersImpersonate,
+	"GET /api/v1/admin/impersonations/:id/requests":          authz.UsersImpersonate,
+	"DELETE /api/v1/admin/impersonations/:id":                authz.UsersImpersonate,
+
+	"GET /api/v1/admin/staff":                    authz.StaffManage,
+	"PATCH /api/v1/admin/staff/:userId/role":     authz.StaffManage,
+	"PATCH /api/v1/admin/staff/:userId/status":   authz.StaffManage,
+	"GET /api/v1/admin/staff/invitations":        authz.StaffManage,
+	"POST /api/v1/admin/staff/invitations":       authz.StaffManage,
+	"DELETE /api/v1/admin/staff/invitations/:id": authz.StaffManage,
+
+	"GET /api/v1/admin/products":                     authz.ProductsManage,
+	"PATCH /api/v1/admin/products/:productId/status": authz.ProductsManage,
+	"DELETE /api/v1/admin/products/:productId":       authz.ProductsManage,
+
+	"GET /api/v1/admin/orders":                   authz.OrdersManage,
+	"PATCH /api/v1/admin/orders/:orderId/status": authz.OrdersManage,
+	"DELETE /api/v1/admin/orders/:id":            authz.OrdersManage,
+
+	"GET /api/v1/admin/suppliers":                      authz.SuppliersManage,
+	"PATCH /api/v1/admin/suppliers/:supplierId/status": authz.SuppliersManage,
+
+	"GET /api/v1/admin/verifications":                         authz.VerificationsReview,
+	"GET /api/v1/admin/verifications/:id":                     authz.VerificationsReview,
+	"POST /api/v1/admin/verifications/:verificationId/review": authz.VerificationsReview,
+	"PATCH /api/v1/admin/verifications/:id/review":            authz.VerificationsReview,
+
+	"GET /api/v1/admin/rfqs": authz.RFQsManage,
+
+	"GET /api/v1/admin/subscriptions":        authz.SubscriptionsManage,
+	"GET /api/v1/admin/subscriptions/:id":    authz.SubscriptionsManage,
+	"DELETE /api/v1/admin/subscriptions/:id": authz.SubscriptionsManage,
+}
@@ -0,0 +1,375 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/example/global-trade-hub/backend/internal/authz"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	mw "github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+)
+
+// policyRoles are the roles the matrix is checked for.
+var policyRoles = []auth.UserRole{
+	auth.RoleAdmin,
+	auth.RoleModerator,
+	auth.RoleKYCReviewer,
+	auth.RoleFinance,
+	auth.RoleSupplier,
+	auth.RoleBuyer,
+	auth.RoleMarket,
+	auth.RoleVisitor,
+}
+
+// access is who may call a route: anyone, or the signed-in users of roles.
+type access struct {
+	public bool
+	roles  []auth.UserRole
+}
+
+func (a access) allows(role auth.UserRole) bool {
+	return a.public || slices.Contains(a.roles, role)
+}
+
+var (
+	anyone            = access{public: true}
+	signedIn          = access{roles: policyRoles}
+	staff             = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleModerator, auth.RoleKYCReviewer, auth.RoleFinance}}
+	admins            = access{roles: []auth.UserRole{auth.RoleAdmin}}
+	moderators        = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleModerator}}
+	kycReviewers      = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleKYCReviewer}}
+	supplierReviewers = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleModerator, auth.RoleKYCReviewer}}
+	financeStaff      = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleFinance}}
+	suppliers         = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleSupplier}}
+	buyers            = access{roles: []auth.UserRole{auth.RoleAdmin, auth.RoleBuyer, auth.RoleMarket}}
+)
+
+// wantAccess is the expected route/role matrix, written out independently of
+// routePolicy and authz: a change to either that lets a role through, or
+// stops it, has to be made here as well.
+var wantAccess = map[string]access{
+	"DELETE /api/v1/admin/impersonations/:id":                 admins,
+	"DELETE /api/v1/admin/orders/:id":                         financeStaff,
+	"DELETE /api/v1/admin/products/:productId":                moderators,
+	"DELETE /api/v1/admin/staff/invitations/:id":              admins,
+	"DELETE /api/v1/admin/subscriptions/:id":                  financeStaff,
+	"DELETE /api/v1/admin/users/:userId/sessions":             admins,
+	"DELETE /api/v1/admin/users/:userId/sessions/:sessionId":  admins,
+	"DELETE /api/v1/favorites/:productId":                     signedIn,
+	"DELETE /api/v1/me":                                       signedIn,
+	"DELETE /api/v1/me/api-keys/:id":                          signedIn,
+	"DELETE /api/v1/me/sessions/:id":                          signedIn,
+	"DELETE /api/v1/messages/:id":                             signedIn,
+	"DELETE /api/v1/notifications/:id":                        signedIn,
+	"DELETE /api/v1/products/:id":                             suppliers,
+	"DELETE /api/v1/suppliers/:id":                            suppliers,
+	"DELETE /api/v1/suppliers/me/invitations/:id":             suppliers,
+	"DELETE /api/v1/suppliers/me/members/:userId":             suppliers,
+	"GET /api/v1/admin/buyers":                                admins,
+	"GET /api/v1/admin/dashboard/activities":                  staff,
+	"GET /api/v1/admin/dashboard/categories":                  staff,
+	"GET /api/v1/admin/dashboard/sales":                       staff,
+	"GET /api/v1/admin/dashboard/stats":                       staff,
+	"GET /api/v1/admin/dashboard/top-products":                staff,
+	"GET /api/v1/admin/dashboard/user-stats":                  staff,
+	"GET /api/v1/admin/impersonations":                        admins,
+	"GET /api/v1/admin/impersonations/:id/requests":           admins,
+	"GET /api/v1/admin/orders":                                financeStaff,
+	"GET /api/v1/admin/products":                              moderators,
+	"GET /api/v1/admin/rfqs":                                  moderators,
+	"GET /api/v1/admin/staff":                                 admins,
+	"GET /api/v1/admin/staff/invitations":                     admins,
+	"GET /api/v1/admin/subscriptions":                         financeStaff,
+	"GET /api/v1/admin/subscriptions/:id":                     financeStaff,
+	"GET /api/v1/admin/suppliers":                             supplierReviewers,
+	"GET /api/v1/admin/users/:userId/sessions":                admins,
+	"GET /api/v1/admin/verifications":                         kycReviewers,
+	"GET /api/v1/admin/verifications/:id":                     kycReviewers,
+	"GET /api/v1/auth/oidc/:provider/authorize":               anyone,
+	"GET /api/v1/auth/oidc/:provider/callback":                anyone,
+	"GET /api/v1/auth/oidc/providers":                         anyone,
+	"GET /api/v1/blog-posts":                                  anyone,
+	"GET /api/v1/blog-posts/:id":                              anyone,
+	"GET /api/v1/categories":                                  anyone,
+	"GET /api/v1/categories/:id":                              anyone,
+	"GET /api/v1/docs":                                        anyone,
+	"GET /api/v1/faqs":                                        anyone,
+	"GET /api/v1/favorites":                                   signedIn,
+	"GET /api/v1/jobs":                                        anyone,
+	"GET /api/v1/me":                                          signedIn,
+	"GET /api/v1/me/api-keys":                                 signedIn,
+	"GET /api/v1/me/api-keys/:id":                             signedIn,
+	"GET /api/v1/me/export":                                   signedIn,
+	"GET /api/v1/me/sessions":                                 signedIn,
+	"GET /api/v1/messages/:id":                                signedIn,
+	"GET /api/v1/messages/conversations":                      signedIn,
+	"GET /api/v1/messages/conversations/:conversationId":      signedIn,
+	"GET /api/v1/notifications":                               signedIn,
+	"GET /api/v1/openapi.json":                                anyone,
+	"GET /api/v1/orders":                                      signedIn,
+	"GET /api/v1/orders/:id":                                  signedIn,
+	"GET /api/v1/orders/supplier/:supplierId":                 suppliers,
+	"GET /api/v1/press-releases":                              anyone,
+	"GET /api/v1/products":                                    anyone,
+	"GET /api/v1/products/:id":                                anyone,
+	"GET /api/v1/products/:id/reviews":                        anyone,
+	"GET /api/v1/rfqs":                                        signedIn,
+	"GET /api/v1/rfqs/:id":                                    signedIn,
+	"GET /api/v1/rfqs/:id/responses":                          signedIn,
+	"GET /api/v1/search":                                      anyone,
+	"GET /api/v1/search/history":                              signedIn,
+	"GET /api/v1/subscriptions/me":                            suppliers,
+	"GET /api/v1/suppliers":                                   anyone,
+	"GET /api/v1/suppliers/:id":                               anyone,
+	"GET /api/v1/suppliers/:id/reviews":                       anyone,
+	"GET /api/v1/suppliers/me":                                signedIn,
+	"GET /api/v1/suppliers/me/invitations":                    suppliers,
+	"GET /api/v1/suppliers/me/members":                        suppliers,
+	"GET /api/v1/verifications/me":                            suppliers,
+	"PATCH /api/v1/admin/orders/:orderId/status":              financeStaff,
+	"PATCH /api/v1/admin/products/:productId/status":          moderators,
+	"PATCH /api/v1/admin/staff/:userId/role":                  admins,
+	"PATCH /api/v1/admin/staff/:userId/status":                admins,
+	"PATCH /api/v1/admin/suppliers/:supplierId/status":        supplierReviewers,
+	"PATCH /api/v1/admin/users/:userId/status":                admins,
+	"PATCH /api/v1/admin/verifications/:id/review":            kycReviewers,
+	"PATCH /api/v1/me":                                        signedIn,
+	"PATCH /api/v1/me/api-keys/:id":                           signedIn,
+	"PATCH /api/v1/messages/:id/read":                         signedIn,
+	"PATCH /api/v1/notifications/:id/read":                    signedIn,
+	"PATCH /api/v1/orders/:id/status":                         suppliers,
+	"PATCH /api/v1/subscriptions/:id/cancel":                  suppliers,
+	"PATCH /api/v1/suppliers/me/members/:userId":              suppliers,
+	"POST /api/v1/admin/staff/invitations":                    admins,
+	"POST /api/v1/admin/users/:userId/impersonate":            admins,
+	"POST /api/v1/admin/users/:userId/unlock":                 admins,
+	"POST /api/v1/admin/verifications/:verificationId/review": kycReviewers,
+	"POST /api/v1/auth/accept-invitation":                     anyone,
+	"POST /api/v1/auth/forgot-password":                       anyone,
+	"POST /api/v1/auth/login":                                 anyone,
+	"POST /api/v1/auth/login/2fa":                             anyone,
+	"POST /api/v1/auth/logout":                                anyone,
+	"POST /api/v1/auth/logout-all":                            signedIn,
+	"POST /api/v1/auth/oidc/exchange":                         anyone,
+	"POST /api/v1/auth/refresh":                               anyone,
+	"POST /api/v1/auth/register":                              anyone,
+	"POST /api/v1/auth/reset-password":                        anyone,
+	"POST /api/v1/auth/verify-email":                          anyone,
+	"POST /api/v1/auth/verify-email/resend":                   signedIn,
+	"POST /api/v1/contact":                                    anyone,
+	"POST /api/v1/favorites/:productId":                       signedIn,
+	"POST /api/v1/me/2fa/disable":                             signedIn,
+	"POST /api/v1/me/2fa/recovery-codes":                      signedIn,
+	"POST /api/v1/me/2fa/totp/enable":                         signedIn,
+	"POST /api/v1/me/2fa/totp/setup":                          signedIn,
+	"POST /api/v1/me/api-keys":                                signedIn,
+	"POST /api/v1/me/password":                                signedIn,
+	"POST /api/v1/messages":                                   signedIn,
+	"POST /api/v1/notifications/read-all":                     signedIn,
+	"POST /api/v1/orders":                                     buyers,
+	"POST /api/v1/products":                                   suppliers,
+	"POST /api/v1/reviews":                                    buyers,
+	"POST /api/v1/rfqs":                                       buyers,
+	"POST /api/v1/rfqs/responses":                             suppliers,
+	"POST /api/v1/search/history":                             signedIn,
+	"POST /api/v1/subscriptions":                              suppliers,
+	"POST /api/v1/suppliers":                                  suppliers,
+	"POST /api/v1/suppliers/invitations/accept":               signedIn,
+	"POST /api/v1/suppliers/me/invitations":                   suppliers,
+	"POST /api/v1/verifications":                              suppliers,
+	"PUT /api/v1/products/:id":                                suppliers,
+	"PUT /api/v1/suppliers/:id":                               suppliers,
+}
+
+// routeParam fills in path parameters. It matches no static segment, so a
+// request is routed to the template it was built from.
+const routeParam = "00000000-0000-4000-8000-000000000001"
+
+// accessToken returns a token of an active, verified account with role.
+func accessToken(t *testing.T, keys *jwtkeys.Keyring, role auth.UserRole) string {
+	t.Helper()
+	now := time.Now()
+	token, err := keys.Sign(&mw.Claims{
+		UserID:        string(role),
+		Role:          string(role),
+		TokenType:     mw.TokenTypeAccess,
+		SessionID:     "session-" + string(role),
+		EmailVerified: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    keys.Issuer(),
+			Audience:  jwt.ClaimStrings{keys.Audience()},
+			Subject:   string(role),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// roleTokens returns a token for every role in policyRoles.
+func roleTokens(t *testing.T, keys *jwtkeys.Keyring) map[auth.UserRole]string {
+	t.Helper()
+	tokens := make(map[auth.UserRole]string, len(policyRoles))
+	for _, role := range policyRoles {
+		tokens[role] = accessToken(t, keys, role)
+	}
+	return tokens
+}
+
+// routeURL replaces the parameters of a route template with routeParam.
+func routeURL(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = routeParam
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// serve sends route a request with token (none if empty) and returns the
+// response status and problem code.
+func serve(router http.Handler, route string, token string) (int, string) {
+	method, path, _ := strings.Cut(route, " ")
+	var body io.Reader
+	if method != http.MethodGet && method != http.MethodHead {
+		body = strings.NewReader("{}")
+	}
+	req := httptest.NewRequest(method, routeURL(path), body)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+	return rec.Code, problem.Code
+}
+
+// newStubRouter registers every route of wantAccess behind the router's
+// authentication and authz.Enforce(routePolicy), with a handler that only
+// answers 204, so a request that gets past the policy is told apart from
+// one refused without running a real handler.
+func newStubRouter(t *testing.T) (*gin.Engine, *jwtkeys.Keyring) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	keys, err := jwtkeys.New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	authService := auth.NewService(testUsers{}, testSessions{}, nil, nil, keys)
+
+	router := gin.New()
+	router.Use(mw.ErrorHandler())
+	stub := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	for route, want := range wantAccess {
+		method, path, _ := strings.Cut(route, " ")
+		handlers := []gin.HandlerFunc{authz.Enforce(routePolicy), stub}
+		if !want.public {
+			handlers = append([]gin.HandlerFunc{mw.JWTAuth(keys, nil, authService)}, handlers...)
+		}
+		router.Handle(method, path, handlers...)
+	}
+	return router, keys
+}
+
+// TestRoutePolicyCoversEveryRoute checks that wantAccess lists exactly the
+// API routes the router registers.
+func TestRoutePolicyCoversEveryRoute(t *testing.T) {
+	router, _ := newTestRouter(t, testConfig(), nil)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, apiPrefix) {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := wantAccess[key]; !ok {
+			t.Errorf("%s: not in wantAccess", key)
+		}
+	}
+	for key := range wantAccess {
+		if !registered[key] {
+			t.Errorf("%s: in wantAccess but not registered", key)
+		}
+	}
+}
+
+// TestRoutePolicyMatrix sends every route a request without a token and with
+// a token of each role, through stub handlers, and checks that exactly the
+// callers wantAccess lists get past the policy.
+func TestRoutePolicyMatrix(t *testing.T) {
+	router, keys := newStubRouter(t)
+	tokens := roleTokens(t, keys)
+
+	for route, want := range wantAccess {
+		t.Run(route, func(t *testing.T) {
+			status, code := serve(router, route, "")
+			switch {
+			case want.public && status != http.StatusNoContent:
+				t.Errorf("anonymous: status %d (%s), want 204", status, code)
+			case !want.public && status != http.StatusUnauthorized:
+				t.Errorf("anonymous: status %d (%s), want 401", status, code)
+			}
+
+			for _, role := range policyRoles {
+				status, code := serve(router, route, tokens[role])
+				switch {
+				case want.allows(role) && status != http.StatusNoContent:
+					t.Errorf("%s: status %d (%s), want 204", role, status, code)
+				case !want.allows(role) && (status != http.StatusForbidden || code != mw.ErrInsufficientPermissions.Code):
+					t.Errorf("%s: status %d (%s), want 403 %s", role, status, code, mw.ErrInsufficientPermissions.Code)
+				}
+			}
+		})
+	}
+}
+
+// TestRouterRefusesOutsideMatrix sends the real router the requests that
+// wantAccess refuses, and checks that they are refused before any handler
+// runs: 401 without a token, 403 for a role that is not allowed.
+func TestRouterRefusesOutsideMatrix(t *testing.T) {
+	router, keys := newTestRouter(t, testConfig(), nil)
+	tokens := roleTokens(t, keys)
+
+	for route, want := range wantAccess {
+		if want.public {
+			continue
+		}
+		t.Run(route, func(t *testing.T) {
+			if status, code := serve(router, route, ""); status != http.StatusUnauthorized {
+				t.Errorf("anonymous: status %d (%s), want 401", status, code)
+			}
+			for _, role := range policyRoles {
+				if want.allows(role) {
+					continue
+				}
+				status, code := serve(router, route, tokens[role])
+				if status != http.StatusForbidden || code != mw.ErrInsufficientPermissions.Code {
+					t.Errorf("%s: status %d (%s), want 403 %s", role, status, code, mw.ErrInsufficientPermissions.Code)
+				}
+			}
+		})
+	}
+}
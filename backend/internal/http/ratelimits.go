@@ -0,0 +1,67 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/authz"
+	"github.com/example/global-trade-hub/backend/internal/domain/subscription"
+	"github.com/example/global-trade-hub/backend/internal/ratelimit"
+)
+
+// defaultRateLimit applies to every API route without an entry in
+// rateLimitPolicy; those routes share one bucket per caller.
+var defaultRateLimit = ratelimit.Rule{
+	Anonymous:     ratelimit.PerMinute(300),
+	Authenticated: ratelimit.PerMinute(600),
+}
+
+// rateLimitPolicy holds the routes with limits of their own. Public routes
+// are limited per client IP (only Anonymous applies, even when a token is
+// sent); protected routes per user. Tiered routes are the API-heavy ones
+// integrations poll: their limit is multiplied by planRateMultipliers.
+var rateLimitPolicy = ratelimit.Policy{
+	// Abuse-prone public forms
+	"POST /api/v1/contact":                  {Anonymous: ratelimit.PerHour(5)},
+	"POST /api/v1/auth/register":            {Anonymous: ratelimit.PerHour(10)},
+	"POST /api/v1/auth/login":               {Anonymous: ratelimit.PerMinute(30)},
+	"POST /api/v1/auth/login/2fa":           {Anonymous: ratelimit.PerMinute(30)},
+	"POST /api/v1/auth/forgot-password":     {Anonymous: ratelimit.PerHour(10)},
+	"POST /api/v1/auth/reset-password":      {Anonymous: ratelimit.PerHour(20)},
+	"POST /api/v1/auth/verify-email/resend": {Authenticated: ratelimit.PerHour(5)},
+
+	// Expensive public reads
+	"GET /api/v1/search": {Anonymous: ratelimit.PerMinute(60)},
+
+	// API-heavy operations, tiered by subscription plan
+	"GET /api/v1/search/history":                         {Authenticated: ratelimit.PerMinute(60), Tiered: true},
+	"POST /api/v1/products":                              {Authenticated: ratelimit.PerMinute(30), Tiered: true},
+	"PUT /api/v1/products/:id":                           {Authenticated: ratelimit.PerMinute(60), Tiered: true},
+	"GET /api/v1/orders":                                 {Authenticated: ratelimit.PerMinute(60), Tiered: true},
+	"GET /api/v1/orders/supplier/:supplierId":            {Authenticated: ratelimit.PerMinute(60), Tiered: true},
+	"GET /api/v1/rfqs":                                   {Authenticated: ratelimit.PerMinute(60), Tiered: true},
+	"POST /api/v1/rfqs/responses":                        {Authenticated: ratelimit.PerMinute(30), Tiered: true},
+	"GET /api/v1/messages/conversations":                 {Authenticated: ratelimit.PerMinute(60), Tiered: true},
+	"GET /api/v1/messages/conversations/:conversationId": {Authenticated: ratelimit.PerMinute(120), Tiered: true},
+	"POST /api/v1/messages":                              {Authenticated: ratelimit.PerMinute(30), Tiered: true},
+}
+
+// planRateMultipliers scales tiered limits by the supplier's subscription.
+var planRateMultipliers = map[string]float64{
+	string(subscription.PlanFree):    1,
+	string(subscription.PlanSilver):  2,
+	string(subscription.PlanGold):    5,
+	string(subscription.PlanDiamond): 10,
+}
+
+// publicOnly runs h on routes routePolicy marks public and skips it on the
+// others, which are rate limited per user once JWTAuth has identified the
+// caller.
+func publicOnly(h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if perm, _ := routePolicy.Require(c.Request.Method, c.FullPath()); perm == authz.Public {
+			h(c)
+			return
+		}
+		c.Next()
+	}
+}
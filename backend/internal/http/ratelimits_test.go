@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/example/global-trade-hub/backend/internal/ratelimit"
+)
+
+// contactLimit is the anonymous limit of POST /api/v1/contact per hour.
+const contactLimit = 5
+
+// postContact sends an (invalid) contact form from the peer remoteAddr with
+// the given X-Forwarded-For and returns the status.
+func postContact(router http.Handler, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodPost, apiPrefix+"contact", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		wantLimited    bool
+	}{
+		{name: "no trusted proxies", remoteAddr: "203.0.113.7:40000", wantLimited: true},
+		{name: "peer is not a trusted proxy", trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:40000", wantLimited: true},
+		{name: "peer is a trusted proxy", trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:40000", wantLimited: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.TrustedProxies = tt.trustedProxies
+			router, _ := newTestRouter(t, cfg, ratelimit.NewMemoryStore())
+
+			// Every request claims to come from a different client.
+			for i := 0; i < contactLimit; i++ {
+				if code := postContact(router, tt.remoteAddr, "198.51.100."+strconv.Itoa(i)); code == http.StatusTooManyRequests {
+					t.Fatalf("request %d: limited before the limit was used up", i+1)
+				}
+			}
+			code := postContact(router, tt.remoteAddr, "198.51.100.99")
+			if limited := code == http.StatusTooManyRequests; limited != tt.wantLimited {
+				t.Fatalf("request %d: status %d, want limited %v", contactLimit+1, code, tt.wantLimited)
+			}
+		})
+	}
+}
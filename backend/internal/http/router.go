@@ -1,14 +1,21 @@
 package http
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
 	"github.com/example/global-trade-hub/backend/internal/config"
+	"github.com/example/global-trade-hub/backend/internal/domain/account"
 	"github.com/example/global-trade-hub/backend/internal/domain/admin"
+	"github.com/example/global-trade-hub/backend/internal/domain/apikey"
 	"github.com/example/global-trade-hub/backend/internal/domain/auth"
 	"github.com/example/global-trade-hub/backend/internal/domain/category"
 	"github.com/example/global-trade-hub/backend/internal/domain/cms"
@@ -23,6 +30,14 @@ import (
 	"github.com/example/global-trade-hub/backend/internal/domain/subscription"
 	"github.com/example/global-trade-hub/backend/internal/domain/supplier"
 	"github.com/example/global-trade-hub/backend/internal/domain/verification"
+	"github.com/example/global-trade-hub/backend/internal/health"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/idempotency"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/metrics"
+	"github.com/example/global-trade-hub/backend/internal/openapi"
+	"github.com/example/global-trade-hub/backend/internal/ratelimit"
+	"github.com/example/global-trade-hub/backend/internal/tracing"
 	mw "github.com/example/global-trade-hub/backend/internal/http/middleware"
 )
 
@@ -30,8 +45,15 @@ import (
 // logging, JWT auth) and wires all HTTP handlers for the domain modules.
 func NewRouter(
 	cfg *config.Config,
-	logger *log.Logger,
+	logger *slog.Logger,
+	m *metrics.Metrics,
+	probes *health.Registry,
+	rateLimits ratelimit.Store,
+	idempotencyKeys idempotency.Store,
+	keys *jwtkeys.Keyring,
+	cache *httpcache.Cache,
 	authService *auth.Service,
+	apiKeyService *apikey.Service,
 	productService *product.Service,
 	supplierService *supplier.Service,
 	orderService *order.Service,
@@ -46,16 +68,28 @@ func NewRouter(
 	favoriteService *favorite.Service,
 	adminService *admin.Service,
 	cmsService *cms.Service,
+	accountService *account.Service,
 ) *gin.Engine {
 	if cfg.AppEnv == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
+	// Without trusted proxies X-Forwarded-For is ignored: gin would otherwise
+	// believe it from any peer, letting clients pick the IP that rate limits
+	// and the login throttle are keyed on.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
 
 	// Global middlewares
-	router.Use(gin.Recovery())
-	router.Use(mw.RequestLogger(logger))
+	router.Use(mw.RequestID(logger))
+	router.Use(tracing.Middleware())
+	router.Use(mw.RequestLogger())
+	router.Use(mw.Recovery())
+	if m != nil {
+		router.Use(m.Middleware())
+	}
 
 	// CORS
 	corsCfg := cors.Config{
@@ -65,32 +99,95 @@ func NewRouter(
 		AllowCredentials: cfg.CORSAllowCredentials,
 	}
 	corsCfg.AllowWildcard = true
+	corsCfg.ExposeHeaders = []string{
+		mw.RequestIDHeader,
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After",
+		idempotency.ReplayedHeader,
+	}
 	router.Use(cors.New(corsCfg))
 
-	// Health check
-	router.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	// Errors reported with c.Error are rendered as problem+json; validation
+	// errors name fields by their JSON names.
+	router.Use(mw.ErrorHandler())
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		apperr.RegisterJSONFieldNames(v)
+	}
+
+	// Liveness and readiness probes (see cmd/api for the checks)
+	router.GET("/livez", probes.Live)
+	router.GET("/readyz", probes.Ready)
+
+	// Prometheus metrics; on this listener only for callers with the token,
+	// otherwise they are served on METRICS_ADDR (see cmd/api).
+	if m != nil && cfg.MetricsToken != "" {
+		router.GET("/metrics", gin.WrapH(m.Handler(cfg.MetricsToken)))
+	}
+
+	// Public token verification keys for other services
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKS())
 	})
 
 	// Domain handlers
 	authHandler := auth.NewHandler(authService)
-	productHandler := product.NewHandler(productService)
+	apiKeyHandler := apikey.NewHandler(apiKeyService)
+	productHandler := product.NewHandler(productService, supplierService)
 	supplierHandler := supplier.NewHandler(supplierService)
-	orderHandler := order.NewHandler(orderService)
-	rfqHandler := rfq.NewHandler(rfqService)
+	orderHandler := order.NewHandler(orderService, supplierService)
+	rfqHandler := rfq.NewHandler(rfqService, supplierService)
 	notificationHandler := notification.NewHandler(notificationService)
 	verificationHandler := verification.NewHandler(verificationService)
 	subscriptionHandler := subscription.NewHandler(subscriptionService)
-	messageHandler := message.NewHandler(messageService)
+	messageHandler := message.NewHandler(messageService, supplierService)
 	searchHandler := search.NewHandler(searchService)
 	categoryHandler := category.NewHandler(categoryService)
 	reviewHandler := review.NewHandler(reviewService)
 	favoriteHandler := favorite.NewHandler(favoriteService)
 	adminHandler := admin.NewHandler(adminService)
 	cmsHandler := cms.NewHandler(cmsService)
+	accountHandler := account.NewHandler(accountService)
+
+	// OpenAPI document of every route (see apiOperations), built once they
+	// are all registered.
+	spec := openapi.New(apiInfo, apiOperations)
+	spec.WithEnums(apiEnums...)
+	spec.WithAccess(func(method, path string) (string, bool) {
+		perm, ok := routePolicy.Require(method, path)
+		return string(perm), !ok || perm == authz.Public
+	})
+	var validate gin.HandlerFunc
+	if cfg.OpenAPIValidate {
+		validate = spec.Validator(cfg.AppEnv == "development")
+	}
 
 	api := router.Group("/api/v1")
 
+	// Rate limits (see rateLimitPolicy): public routes per client IP here,
+	// the others per user right after JWTAuth. Without a store nothing is
+	// limited.
+	var limiter *ratelimit.Limiter
+	if rateLimits != nil {
+		limiter = ratelimit.New(rateLimits, defaultRateLimit, rateLimitPolicy)
+		limiter.WithPlans(supplierService, ratelimit.PlanFunc(func(ctx context.Context, supplierID string) (string, error) {
+			plan, err := subscriptionService.ActivePlan(ctx, supplierID)
+			return string(plan), err
+		}), planRateMultipliers)
+	}
+	api.Use(publicOnly(limiter.Middleware()))
+	// Requests are checked against the document once authorized, public
+	// ones here and the others at the end of the protected chain.
+	if validate != nil {
+		api.Use(publicOnly(validate))
+	}
+	// Conditional requests and cached responses (see cachePolicy). Without
+	// a cache the validators are still sent and checked.
+	api.Use(publicOnly(cache.Middleware(cachePolicy)))
+
+	// API documentation
+	api.GET("/openapi.json", spec.ServeJSON)
+	api.GET("/docs", spec.ServeUI)
+
 	// Public CMS endpoints (no auth)
 	api.POST("/contact", cmsHandler.SubmitContact)
 	api.GET("/blog-posts", cmsHandler.ListBlogPosts)
@@ -104,15 +201,86 @@ func NewRouter(
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/login/2fa", authHandler.LoginMFA)
 		authGroup.POST("/refresh", authHandler.RefreshToken)
+		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+		authGroup.POST("/reset-password", authHandler.ResetPassword)
+		authGroup.POST("/accept-invitation", authHandler.AcceptInvitation)
+
+		// OpenID Connect sign-in (authorization code + PKCE)
+		authGroup.GET("/oidc/providers", authHandler.OIDCProviders)
+		authGroup.GET("/oidc/:provider/authorize", authHandler.OIDCAuthorize)
+		authGroup.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
+		authGroup.POST("/oidc/exchange", authHandler.OIDCExchange)
 	}
 
 	// Protected routes (JWT)
 	protected := api.Group("/")
-	protected.Use(mw.JWTAuth(cfg.JWTSecret))
+	protected.Use(mw.JWTAuth(keys, apiKeyService, authService))
+	protected.Use(limiter.Middleware())
+	// Requests made by an admin impersonating a user are recorded, and only
+	// reads are allowed: no route that changes data is excepted.
+	protected.Use(mw.AuditImpersonation(authService))
+	protected.Use(mw.ForbidWhileImpersonating())
+	// Accounts with an operator-assigned password may only change it.
+	protected.Use(mw.RequirePasswordChanged(
+		"/api/v1/me",
+		"/api/v1/me/password",
+		"/api/v1/auth/logout-all",
+	))
+	// Roles that must use 2FA may only enroll until they have done so.
+	protected.Use(mw.RequireMFAEnrolled(
+		"/api/v1/me",
+		"/api/v1/me/password",
+		"/api/v1/me/2fa/totp/setup",
+		"/api/v1/me/2fa/totp/enable",
+		"/api/v1/auth/logout-all",
+	))
+	// Role permissions for every protected route (see routePolicy).
+	protected.Use(authz.Enforce(routePolicy))
+	if validate != nil {
+		protected.Use(validate)
+	}
+	// Creation requests retried with the same Idempotency-Key replay the
+	// first response instead of creating a duplicate.
+	var idempotent *idempotency.Guard
+	if idempotencyKeys != nil {
+		idempotent = idempotency.New(idempotencyKeys, cfg.IdempotencyTTL)
+	}
+	protected.Use(idempotent.Middleware(
+		"POST /api/v1/orders",
+		"POST /api/v1/rfqs",
+		"POST /api/v1/rfqs/responses",
+		"POST /api/v1/subscriptions",
+	))
 
 	{
 		protected.GET("/me", authHandler.Me)
+		protected.PATCH("/me", authHandler.UpdateProfile)
+		protected.POST("/me/password", authHandler.ChangePassword)
+		protected.GET("/me/export", accountHandler.Export)
+		protected.DELETE("/me", accountHandler.Delete)
+		protected.POST("/auth/logout-all", authHandler.LogoutAll)
+		protected.POST("/auth/verify-email/resend", authHandler.ResendVerificationEmail)
+
+		// Signed-in devices
+		protected.GET("/me/sessions", authHandler.ListSessions)
+		protected.DELETE("/me/sessions/:id", authHandler.RevokeSession)
+
+		// Two-factor authentication (TOTP)
+		protected.POST("/me/2fa/totp/setup", authHandler.BeginMFAEnrollment)
+		protected.POST("/me/2fa/totp/enable", authHandler.EnableMFA)
+		protected.POST("/me/2fa/disable", authHandler.DisableMFA)
+		protected.POST("/me/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+
+		// API keys for integrations (not usable with an API key itself)
+		protected.GET("/me/api-keys", apiKeyHandler.List)
+		protected.POST("/me/api-keys", apiKeyHandler.Create)
+		protected.GET("/me/api-keys/:id", apiKeyHandler.Get)
+		protected.PATCH("/me/api-keys/:id", apiKeyHandler.Update)
+		protected.DELETE("/me/api-keys/:id", apiKeyHandler.Revoke)
 	}
 
 	// Products (public read, protected write)
@@ -138,6 +306,15 @@ func NewRouter(
 		protectedSuppliers.POST("", supplierHandler.Create)
 		protectedSuppliers.PUT("/:id", supplierHandler.Update)
 		protectedSuppliers.DELETE("/:id", supplierHandler.Delete)
+
+		// Team of the caller's organization
+		protectedSuppliers.GET("/me/members", supplierHandler.ListMembers)
+		protectedSuppliers.PATCH("/me/members/:userId", supplierHandler.UpdateMember)
+		protectedSuppliers.DELETE("/me/members/:userId", supplierHandler.RemoveMember)
+		protectedSuppliers.GET("/me/invitations", supplierHandler.ListInvitations)
+		protectedSuppliers.POST("/me/invitations", supplierHandler.InviteMember)
+		protectedSuppliers.DELETE("/me/invitations/:id", supplierHandler.RevokeInvitation)
+		protectedSuppliers.POST("/invitations/accept", supplierHandler.AcceptInvitation)
 	}
 
 	// Orders (protected)
@@ -145,7 +322,7 @@ func NewRouter(
 	{
 		protectedOrders.GET("", orderHandler.GetMyOrders)
 		protectedOrders.GET("/:id", orderHandler.GetByID)
-		protectedOrders.POST("", orderHandler.Create)
+		protectedOrders.POST("", mw.RequireVerifiedEmail(), orderHandler.Create)
 		protectedOrders.PATCH("/:id/status", orderHandler.UpdateStatus)
 		protectedOrders.GET("/supplier/:supplierId", orderHandler.GetSupplierOrders)
 	}
@@ -154,7 +331,7 @@ func NewRouter(
 	protectedRFQs := protected.Group("/rfqs")
 	{
 		protectedRFQs.GET("", rfqHandler.GetMyRFQs)
-		protectedRFQs.POST("", rfqHandler.Create)
+		protectedRFQs.POST("", mw.RequireVerifiedEmail(), rfqHandler.Create)
 		// Specific routes must come before generic :id route
 		protectedRFQs.GET("/:id/responses", rfqHandler.ListResponses)
 		protectedRFQs.POST("/responses", rfqHandler.CreateResponse)
@@ -248,6 +425,24 @@ func NewRouter(
 		// User management endpoints
 		adminDashboard.GET("/buyers", adminHandler.ListBuyers)
 		adminDashboard.PATCH("/users/:userId/status", adminHandler.UpdateUserStatus)
+		adminDashboard.POST("/users/:userId/unlock", authHandler.UnlockAccount)
+		adminDashboard.GET("/users/:userId/sessions", authHandler.ListUserSessions)
+		adminDashboard.DELETE("/users/:userId/sessions", authHandler.RevokeUserSessions)
+		adminDashboard.DELETE("/users/:userId/sessions/:sessionId", authHandler.RevokeUserSession)
+
+		// Impersonation ("login as user") and its audit trail
+		adminDashboard.POST("/users/:userId/impersonate", authHandler.Impersonate)
+		adminDashboard.GET("/impersonations", authHandler.ListImpersonations)
+		adminDashboard.GET("/impersonations/:id/requests", authHandler.ListImpersonatedRequests)
+		adminDashboard.DELETE("/impersonations/:id", authHandler.EndImpersonation)
+
+		// Staff (admin team) management endpoints
+		adminDashboard.GET("/staff", authHandler.ListStaff)
+		adminDashboard.PATCH("/staff/:userId/role", authHandler.UpdateStaffRole)
+		adminDashboard.PATCH("/staff/:userId/status", authHandler.UpdateStaffStatus)
+		adminDashboard.GET("/staff/invitations", authHandler.ListInvitations)
+		adminDashboard.POST("/staff/invitations", authHandler.InviteStaff)
+		adminDashboard.DELETE("/staff/invitations/:id", authHandler.RevokeInvitation)
 
 		// Product management endpoints
 		adminDashboard.GET("/products", adminHandler.ListProducts)
@@ -270,5 +465,20 @@ func NewRouter(
 		adminDashboard.PATCH("/verifications/:id/review", verificationHandler.Review)
 	}
 
+	// Like gin's own route conflicts, an unclassified route is a programming
+	// error: fail at startup rather than serve it with the wrong policy.
+	if err := routePolicy.Verify(router.Routes(), apiPrefix); err != nil {
+		panic(err)
+	}
+	if err := rateLimitPolicy.Verify(router.Routes()); err != nil {
+		panic(err)
+	}
+	if err := cachePolicy.Verify(router.Routes()); err != nil {
+		panic(err)
+	}
+	if err := spec.Build(router.Routes()); err != nil {
+		panic(err)
+	}
+
 	return router
 }
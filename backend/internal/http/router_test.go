@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+	"github.com/example/global-trade-hub/backend/internal/domain/account"
+	"github.com/example/global-trade-hub/backend/internal/domain/admin"
+	"github.com/example/global-trade-hub/backend/internal/domain/apikey"
+	"github.com/example/global-trade-hub/backend/internal/domain/auth"
+	"github.com/example/global-trade-hub/backend/internal/domain/category"
+	"github.com/example/global-trade-hub/backend/internal/domain/cms"
+	"github.com/example/global-trade-hub/backend/internal/domain/favorite"
+	"github.com/example/global-trade-hub/backend/internal/domain/message"
+	"github.com/example/global-trade-hub/backend/internal/domain/notification"
+	"github.com/example/global-trade-hub/backend/internal/domain/order"
+	"github.com/example/global-trade-hub/backend/internal/domain/product"
+	"github.com/example/global-trade-hub/backend/internal/domain/review"
+	"github.com/example/global-trade-hub/backend/internal/domain/rfq"
+	"github.com/example/global-trade-hub/backend/internal/domain/search"
+	"github.com/example/global-trade-hub/backend/internal/domain/subscription"
+	"github.com/example/global-trade-hub/backend/internal/domain/supplier"
+	"github.com/example/global-trade-hub/backend/internal/domain/verification"
+	"github.com/example/global-trade-hub/backend/internal/health"
+	"github.com/example/global-trade-hub/backend/internal/httpcache"
+	"github.com/example/global-trade-hub/backend/internal/jwtkeys"
+	"github.com/example/global-trade-hub/backend/internal/ratelimit"
+)
+
+// testUsers knows one active account per role; its ID is the role name.
+// Methods the router's middleware does not use are left to the embedded nil
+// interface.
+type testUsers struct {
+	auth.UserRepository
+}
+
+func (testUsers) GetByID(ctx context.Context, id string) (*auth.User, error) {
+	return &auth.User{ID: id, Email: id + "@example.com", Role: auth.UserRole(id), Status: auth.StatusActive}, nil
+}
+
+// testSessions reports every session as active.
+type testSessions struct {
+	auth.RefreshTokenRepository
+}
+
+func (testSessions) GetSession(ctx context.Context, id string) (*auth.Session, error) {
+	now := time.Now()
+	return &auth.Session{ID: id, UserID: strings.TrimPrefix(id, "session-"), LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+}
+
+func (testSessions) TouchSession(ctx context.Context, id string, at time.Time) error { return nil }
+
+// testConfig returns the smallest configuration the router accepts.
+func testConfig() *config.Config {
+	return &config.Config{
+		AppEnv:             "test",
+		JWTSecret:          "router-test-secret-at-least-32-characters",
+		JWTIssuer:          "global-trade-hub",
+		JWTAudience:        "global-trade-hub-api",
+		CORSAllowedOrigins: []string{"http://localhost:5173"},
+	}
+}
+
+// newTestRouter builds the router with services that have no storage, so only
+// middleware and handlers that fail before reaching a repository can be
+// exercised. rateLimits may be nil to disable rate limiting.
+func newTestRouter(t *testing.T, cfg *config.Config, rateLimits ratelimit.Store) (*gin.Engine, *jwtkeys.Keyring) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	keys, err := jwtkeys.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := testUsers{}
+	authService := auth.NewService(users, testSessions{}, nil, nil, keys)
+	supplierService := supplier.NewService(nil, nil, users)
+	router := NewRouter(
+		cfg,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		nil,
+		health.New(time.Second),
+		rateLimits,
+		nil,
+		keys,
+		httpcache.New(0),
+		authService,
+		apikey.NewService(nil, users, supplierService),
+		product.NewService(nil),
+		supplierService,
+		order.NewService(nil),
+		rfq.NewService(nil),
+		notification.NewService(nil),
+		verification.NewService(nil),
+		subscription.NewService(nil),
+		message.NewService(nil),
+		search.NewService(nil),
+		category.NewService(nil),
+		review.NewService(nil),
+		favorite.NewService(nil),
+		admin.NewService(nil, authService),
+		cms.NewService(nil),
+		account.NewService(nil, authService, time.Hour),
+	)
+	return router, keys
+}
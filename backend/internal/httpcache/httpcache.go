@@ -0,0 +1,385 @@
+// Package httpcache serves public read routes with HTTP validators and keeps
+// their responses in process memory.
+//
+// Handlers of cached routes record when what they render last changed (see
+// SetLastModified and SetListVersion); the middleware turns that into a weak
+// ETag and Last-Modified header, answers If-None-Match / If-Modified-Since
+// with 304 Not Modified, and sets the route's Cache-Control. Responses are
+// also kept in a Cache for the route's TTL, so repeated requests run no SQL
+// until the entry expires or a service invalidates one of its tags, e.g.
+// "product:<id>" when the product changes.
+//
+// The Cache is per process: with several instances, a change made through
+// one of them is seen by the others once their entries expire, so TTLs
+// should stay short.
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/metrics"
+)
+
+// Rule is the caching policy of a route.
+type Rule struct {
+	// MaxAge is how long clients and shared caches may reuse a response
+	// without asking again; zero makes them revalidate every time.
+	MaxAge time.Duration
+	// TTL is how long a response is kept in the Cache; zero keeps none, but
+	// conditional requests are still answered.
+	TTL time.Duration
+	// Tags name what a response depends on, for Cache.Invalidate.
+	Tags func(c *gin.Context) []string
+}
+
+// cacheControl returns the Cache-Control header of the rule.
+func (r Rule) cacheControl() string {
+	if r.MaxAge <= 0 {
+		return "public, no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(r.MaxAge.Seconds()))
+}
+
+// Policy maps "METHOD /path" (Gin route templates) to the route's rule.
+// Routes without a rule are not touched.
+type Policy map[string]Rule
+
+// Verify reports policy entries that match no route in routes.
+func (p Policy) Verify(routes gin.RoutesInfo) error {
+	registered := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		registered[r.Method+" "+r.Path] = true
+	}
+	for key := range p {
+		if !registered[key] {
+			return fmt.Errorf("httpcache: policy for unregistered route %s", key)
+		}
+		if !strings.HasPrefix(key, http.MethodGet+" ") {
+			return fmt.Errorf("httpcache: policy for %s: only GET routes can be cached", key)
+		}
+	}
+	return nil
+}
+
+// Tag returns a Rule.Tags function tagging responses with prefix followed by
+// the route parameter param, e.g. Tag("product:", "id").
+func Tag(prefix, param string) func(c *gin.Context) []string {
+	return func(c *gin.Context) []string { return []string{prefix + c.Param(param)} }
+}
+
+// entry is a cached response.
+type entry struct {
+	key         string
+	contentType string
+	body        []byte
+	version     version
+	expires     time.Time
+	tags        []string
+}
+
+// Cache holds responses of cached routes, evicting the least recently used
+// beyond its capacity. A nil *Cache caches nothing; its methods may still be
+// called.
+type Cache struct {
+	mu      sync.Mutex
+	max     int
+	lru     *list.List // of *entry, most recently used first
+	entries map[string]*list.Element
+	tags    map[string]map[string]bool // tag -> keys
+	// gen counts invalidations, so that a response rendered while its data
+	// changed is not stored.
+	gen     uint64
+	metrics *metrics.Metrics
+	now     func() time.Time
+}
+
+// New returns a cache of at most maxEntries responses, or nil if maxEntries
+// is not positive.
+func New(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &Cache{
+		max:     maxEntries,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+		tags:    map[string]map[string]bool{},
+		now:     time.Now,
+	}
+}
+
+// WithMetrics counts lookups (hit, miss, not_modified) per route.
+func (c *Cache) WithMetrics(m *metrics.Metrics) {
+	if c != nil {
+		c.metrics = m
+	}
+}
+
+// Invalidate drops the responses tagged with any of tags.
+func (c *Cache) Invalidate(tags ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if el, ok := c.entries[key]; ok {
+				c.remove(el)
+			}
+		}
+	}
+}
+
+// get returns the fresh entry stored under key, if any, and the current
+// generation.
+func (c *Cache) get(key string) (*entry, uint64) {
+	if c == nil {
+		return nil, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, c.gen
+	}
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		return nil, c.gen
+	}
+	c.lru.MoveToFront(el)
+	return e, c.gen
+}
+
+// put stores e unless an invalidation happened since generation gen.
+func (c *Cache) put(e *entry, gen uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el)
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	for _, tag := range e.tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]bool{}
+		}
+		c.tags[tag][e.key] = true
+	}
+	for c.lru.Len() > c.max {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes el; c.mu must be held.
+func (c *Cache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry)
+	delete(c.entries, e.key)
+	for _, tag := range e.tags {
+		delete(c.tags[tag], e.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// Middleware applies policy to the routes it lists: it serves their
+// responses from the cache, answers conditional requests and sets their
+// Cache-Control, ETag and Last-Modified headers. Responses whose handler
+// recorded no version, and errors, pass through unchanged.
+func (c *Cache) Middleware(policy Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		rule, ok := policy[ctx.Request.Method+" "+route]
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		key := ctx.Request.URL.RequestURI()
+		e, gen := c.get(key)
+		if e != nil {
+			c.count(route, serve(ctx, rule, e.version, e.contentType, e.body, "hit"))
+			ctx.Abort()
+			return
+		}
+
+		w := &bufferWriter{ResponseWriter: ctx.Writer, status: http.StatusOK}
+		ctx.Writer = w
+		ctx.Next()
+		ctx.Writer = w.ResponseWriter
+		if !w.written {
+			// Nothing rendered: an error that ErrorHandler renders.
+			return
+		}
+		v, ok := ctx.Get(versionKey)
+		if !ok || w.status != http.StatusOK {
+			w.flush()
+			return
+		}
+
+		e = &entry{
+			key:         key,
+			contentType: w.Header().Get("Content-Type"),
+			body:        w.body.Bytes(),
+			version:     v.(version),
+		}
+		if rule.TTL > 0 && c != nil {
+			e.expires = c.now().Add(rule.TTL)
+			if rule.Tags != nil {
+				e.tags = rule.Tags(ctx)
+			}
+			c.put(e, gen)
+		}
+		c.count(route, serve(ctx, rule, e.version, e.contentType, e.body, "miss"))
+	}
+}
+
+// count records a lookup.
+func (c *Cache) count(route, result string) {
+	if c != nil {
+		c.metrics.CacheLookup(route, result)
+	}
+}
+
+// serve writes a 200 response with body, or 304 if the request's
+// validators match v. It returns result, or "not_modified".
+func serve(c *gin.Context, rule Rule, v version, contentType string, body []byte, result string) string {
+	h := c.Writer.Header()
+	h.Set("Cache-Control", rule.cacheControl())
+	h.Set("ETag", v.etag())
+	h.Set("Last-Modified", v.modified.UTC().Format(http.TimeFormat))
+	if notModified(c.Request, v) {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return "not_modified"
+	}
+	c.Data(http.StatusOK, contentType, body)
+	return result
+}
+
+// notModified evaluates If-None-Match, or failing that If-Modified-Since
+// (RFC 9110, section 13.2.2).
+func notModified(r *http.Request, v version) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(v.etag(), "W/")
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !v.modified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// bufferWriter holds back the response so that it can be stored and
+// replaced by a 304.
+type bufferWriter struct {
+	gin.ResponseWriter
+	status  int
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *bufferWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferWriter) WriteHeaderNow() { w.written = true }
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferWriter) Status() int { return w.status }
+
+func (w *bufferWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferWriter) Written() bool { return w.written }
+
+// flush writes the held response to the underlying writer.
+func (w *bufferWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
+
+const versionKey = "httpcache.version"
+
+// version identifies a state of a resource or list.
+type version struct {
+	modified time.Time
+	// items is the length of a list, or -1 for a single resource.
+	items int
+}
+
+// etag returns the weak ETag of v: weak because it follows the data rather
+// than the exact bytes rendered.
+func (v version) etag() string {
+	tag := strconv.FormatInt(v.modified.UnixNano(), 36)
+	if v.items >= 0 {
+		tag += "-" + strconv.Itoa(v.items)
+	}
+	return `W/"` + tag + `"`
+}
+
+// SetLastModified records when the resource a handler renders last changed,
+// usually its updated_at.
+func SetLastModified(c *gin.Context, modified time.Time) {
+	c.Set(versionKey, version{modified: modified, items: -1})
+}
+
+// SetListVersion records the state of a list a handler renders: the latest
+// change of its items and their number, which also changes when an item is
+// removed.
+func SetListVersion(c *gin.Context, latest time.Time, items int) {
+	c.Set(versionKey, version{modified: latest, items: items})
+}
+
+// Latest returns the latest of the times modified returns for items.
+func Latest[T any](items []T, modified func(T) time.Time) time.Time {
+	var latest time.Time
+	for _, it := range items {
+		if t := modified(it); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
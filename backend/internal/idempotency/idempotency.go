@@ -0,0 +1,198 @@
+// Package idempotency makes retried creation requests safe. A client sends an
+// Idempotency-Key header with a unique value per operation; the first request
+// with a key is handled and its response stored, and retries with the same
+// key and payload get that response back instead of creating a duplicate.
+// Keys are scoped to the user, and stored responses expire after a TTL.
+// Records live in a Store, in memory or shared through MySQL between
+// instances.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+)
+
+const (
+	// Header is the request header carrying the key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set to "true" on replayed responses.
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength is the longest key accepted.
+	MaxKeyLength = 255
+)
+
+var (
+	ErrInvalidKey = apperr.BadRequest("invalid_idempotency_key",
+		"Idempotency-Key must be 1 to 255 printable ASCII characters")
+	// ErrKeyReused is returned when a key is sent again with a different
+	// request (route or body).
+	ErrKeyReused = apperr.New(http.StatusUnprocessableEntity, "idempotency_key_reused",
+		"Idempotency-Key was already used for a different request")
+	// ErrInProgress is returned while the first request with a key is still
+	// being handled.
+	ErrInProgress = apperr.Conflict("idempotency_key_in_progress",
+		"a request with this Idempotency-Key is still being processed")
+)
+
+// lease is how long a request holds its key before a response is stored. It
+// outlasts the handlers' own timeouts; a key left by a crashed instance is
+// free again after it.
+const lease = time.Minute
+
+// Guard stores and replays the responses of idempotent requests.
+type Guard struct {
+	store Store
+	ttl   time.Duration
+}
+
+// New returns a guard keeping responses in store for ttl.
+func New(store Store, ttl time.Duration) *Guard {
+	return &Guard{store: store, ttl: ttl}
+}
+
+// Middleware handles Idempotency-Key on the given route templates ("METHOD
+// /full/path") of authenticated callers; it must run after JWTAuth. Requests
+// without the header are handled as usual.
+//
+// Only successful (2xx) responses are stored: after an error the key is
+// released, so the client can retry with it once the cause is fixed. A
+// retry with a different body or route is rejected with 422, and one that
+// arrives while the first request is still being handled with 409. If the
+// store fails the request is handled without the guarantee. A nil Guard does
+// nothing.
+func (g *Guard) Middleware(routes ...string) gin.HandlerFunc {
+	if g == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	guarded := make(map[string]struct{}, len(routes))
+	for _, r := range routes {
+		guarded[r] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if _, ok := guarded[c.Request.Method+" "+c.FullPath()]; !ok || key == "" {
+			c.Next()
+			return
+		}
+		if !validKey(key) {
+			middleware.Abort(c, ErrInvalidKey)
+			return
+		}
+		raw, ok := c.Get("claims")
+		if !ok {
+			middleware.Abort(c, apperr.ErrMissingClaims)
+			return
+		}
+		claims := raw.(*middleware.Claims)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			middleware.Abort(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		log := logging.FromContext(ctx)
+		recordKey := hash(claims.UserID, key)
+		fingerprint := hash(c.Request.Method, c.Request.URL.RequestURI(), string(body))
+
+		rec, err := g.store.Reserve(ctx, recordKey, fingerprint, time.Now(), lease)
+		if err != nil {
+			log.Warn("idempotency: store failed, handling request without key", "error", err)
+			c.Next()
+			return
+		}
+		if rec != nil {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				middleware.Abort(c, ErrKeyReused)
+			case rec.Status == 0:
+				middleware.Abort(c, ErrInProgress)
+			default:
+				c.Header(ReplayedHeader, "true")
+				c.Data(rec.Status, rec.ContentType, rec.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		// The handler is done, whatever happened to the client: store or
+		// release with a context of our own.
+		ctx = context.WithoutCancel(ctx)
+		status := middleware.ResponseStatus(c)
+		if status < 200 || status >= 300 || len(c.Errors) > 0 {
+			if err := g.store.Release(ctx, recordKey); err != nil {
+				log.Warn("idempotency: failed to release key", "error", err)
+			}
+			return
+		}
+		rec = &Record{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		}
+		if err := g.store.Save(ctx, recordKey, rec, time.Now().Add(g.ttl)); err != nil {
+			log.Warn("idempotency: failed to store response", "error", err)
+		}
+	}
+}
+
+// validKey reports whether key is 1 to MaxKeyLength printable ASCII
+// characters.
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// hash returns the hex SHA-256 of parts, separated so that they cannot run
+// into each other.
+func hash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder copies the response body as it is written.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
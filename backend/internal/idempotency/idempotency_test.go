@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+// newGuardedRouter serves POST /orders, creating a numbered order per handled
+// request, and POST /rfqs, behind the guard. Requests are made as the user in
+// the X-User header. hold, when not nil, runs at the start of the order
+// handler.
+func newGuardedRouter(g *Guard, hold func()) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	created := 0
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &middleware.Claims{UserID: c.GetHeader("X-User")})
+	})
+	router.Use(g.Middleware("POST /orders", "POST /rfqs"))
+	router.POST("/orders", func(c *gin.Context) {
+		if hold != nil {
+			hold()
+		}
+		if c.Query("fail") != "" {
+			middleware.Abort(c, apperr.Conflict("out_of_stock", "out of stock"))
+			return
+		}
+		created++
+		c.JSON(http.StatusCreated, gin.H{"orderNumber": "ORD-" + strconv.Itoa(created)})
+	})
+	router.POST("/rfqs", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return router, &created
+}
+
+func post(router http.Handler, user, key, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareReplay(t *testing.T) {
+	const first = `{"productId":"p1","quantity":10}`
+
+	tests := []struct {
+		name string
+		// user, key, path and body describe the retry of the first request.
+		user, key, path, body string
+		wantStatus            int
+		wantCode              string
+		wantReplayed          bool
+		wantCreated           int
+	}{
+		{name: "same body replayed", user: "u1", key: "k1", path: "/orders", body: first, wantStatus: http.StatusCreated, wantReplayed: true, wantCreated: 1},
+		{name: "different body", user: "u1", key: "k1", path: "/orders", body: `{"productId":"p1","quantity":11}`, wantStatus: http.StatusUnprocessableEntity, wantCode: "idempotency_key_reused", wantCreated: 1},
+		{name: "different route", user: "u1", key: "k1", path: "/rfqs", body: first, wantStatus: http.StatusUnprocessableEntity, wantCode: "idempotency_key_reused", wantCreated: 1},
+		{name: "different query", user: "u1", key: "k1", path: "/orders?draft=1", body: first, wantStatus: http.StatusUnprocessableEntity, wantCode: "idempotency_key_reused", wantCreated: 1},
+		{name: "other user", user: "u2", key: "k1", path: "/orders", body: first, wantStatus: http.StatusCreated, wantCreated: 2},
+		{name: "other key", user: "u1", key: "k2", path: "/orders", body: first, wantStatus: http.StatusCreated, wantCreated: 2},
+		{name: "no key", user: "u1", path: "/orders", body: first, wantStatus: http.StatusCreated, wantCreated: 2},
+		{name: "invalid key", user: "u1", key: "k\x01", path: "/orders", body: first, wantStatus: http.StatusBadRequest, wantCode: "invalid_idempotency_key", wantCreated: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, created := newGuardedRouter(New(NewMemoryStore(), time.Hour), nil)
+			original := post(router, "u1", "k1", "/orders", first)
+			if original.Code != http.StatusCreated {
+				t.Fatalf("first request: %d %s", original.Code, original.Body)
+			}
+
+			rec := post(router, tt.user, tt.key, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), `"code":"`+tt.wantCode+`"`) {
+				t.Errorf("body %s, want code %s", rec.Body, tt.wantCode)
+			}
+			if replayed := rec.Header().Get(ReplayedHeader) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed %v, want %v", replayed, tt.wantReplayed)
+			}
+			if tt.wantReplayed {
+				if rec.Body.String() != original.Body.String() || rec.Header().Get("Content-Type") != original.Header().Get("Content-Type") {
+					t.Errorf("replayed %q (%s), want %q (%s)", rec.Body, rec.Header().Get("Content-Type"), original.Body, original.Header().Get("Content-Type"))
+				}
+			}
+			if *created != tt.wantCreated {
+				t.Errorf("%d orders created, want %d", *created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestMiddlewareReleasesKeyAfterError(t *testing.T) {
+	router, created := newGuardedRouter(New(NewMemoryStore(), time.Hour), nil)
+
+	if rec := post(router, "u1", "k1", "/orders?fail=1", `{}`); rec.Code != http.StatusConflict {
+		t.Fatalf("failing request: %d %s", rec.Code, rec.Body)
+	}
+	if rec := post(router, "u1", "k1", "/orders", `{}`); rec.Code != http.StatusCreated || rec.Header().Get(ReplayedHeader) != "" {
+		t.Fatalf("corrected retry: %d replayed %q", rec.Code, rec.Header().Get(ReplayedHeader))
+	}
+	if *created != 1 {
+		t.Errorf("%d orders created, want 1", *created)
+	}
+}
+
+func TestMiddlewareRejectsRetryInProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	router, _ := newGuardedRouter(New(NewMemoryStore(), time.Hour), func() {
+		close(started)
+		<-release
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(router, "u1", "k1", "/orders", `{}`) }()
+	<-started
+
+	if rec := post(router, "u1", "k1", "/orders", `{}`); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "idempotency_key_in_progress") {
+		t.Errorf("retry in progress: %d %s", rec.Code, rec.Body)
+	}
+	close(release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Errorf("first request: %d %s", rec.Code, rec.Body)
+	}
+}
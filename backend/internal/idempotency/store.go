@@ -0,0 +1,182 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/logging"
+)
+
+// Record is what is known about a key: the fingerprint of the request that
+// first used it and, once handled, its response.
+type Record struct {
+	Fingerprint string
+	// Status is 0 while the request is being handled.
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Store keeps records by key. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Reserve returns the unexpired record of key or, if there is none,
+	// records key as being handled for a request with fingerprint until
+	// now+lease and returns nil. Of concurrent calls for a key, only one
+	// reserves it.
+	Reserve(ctx context.Context, key, fingerprint string, now time.Time, lease time.Duration) (*Record, error)
+	// Save stores the response of a reserved key until expires.
+	Save(ctx context.Context, key string, rec *Record, expires time.Time) error
+	// Release forgets a reserved key.
+	Release(ctx context.Context, key string) error
+}
+
+type entry struct {
+	rec     Record
+	expires time.Time
+}
+
+// memoryStore keeps records in process memory. Suitable for a single API
+// instance; with several, a retry reaching another instance is not
+// recognized.
+type memoryStore struct {
+	mu       sync.Mutex
+	entries  map[string]*entry
+	reserves int
+}
+
+// NewMemoryStore returns an in-memory store.
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]*entry)}
+}
+
+func (m *memoryStore) Reserve(_ context.Context, key, fingerprint string, now time.Time, lease time.Duration) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Expired records are swept now and then so that keys used once do not
+	// accumulate.
+	m.reserves++
+	if m.reserves%1000 == 0 {
+		for k, e := range m.entries {
+			if !e.expires.After(now) {
+				delete(m.entries, k)
+			}
+		}
+	}
+
+	if e, ok := m.entries[key]; ok && e.expires.After(now) {
+		rec := e.rec
+		return &rec, nil
+	}
+	m.entries[key] = &entry{rec: Record{Fingerprint: fingerprint}, expires: now.Add(lease)}
+	return nil, nil
+}
+
+func (m *memoryStore) Save(_ context.Context, key string, rec *Record, expires time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = &entry{rec: *rec, expires: expires}
+	return nil
+}
+
+func (m *memoryStore) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && e.rec.Status == 0 {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// mySQLStore shares records between API instances through the
+// idempotency_keys table.
+type mySQLStore struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	reserves int
+}
+
+// NewMySQLStore returns a MySQL-backed store.
+func NewMySQLStore(db *sql.DB) Store {
+	return &mySQLStore{db: db}
+}
+
+func (r *mySQLStore) Reserve(ctx context.Context, key, fingerprint string, now time.Time, lease time.Duration) (*Record, error) {
+	r.sweep(ctx, now)
+
+	until := now.Add(lease).UTC()
+	res, err := r.db.ExecContext(ctx,
+		`INSERT IGNORE INTO idempotency_keys (record_key, fingerprint, status_code, expires_at) VALUES (?, ?, 0, ?)`,
+		key, fingerprint, until,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return nil, err
+	}
+
+	// The key exists; take it over if its record has expired.
+	res, err = r.db.ExecContext(ctx, `
+UPDATE idempotency_keys
+SET fingerprint = ?, status_code = 0, content_type = '', body = NULL, expires_at = ?
+WHERE record_key = ? AND expires_at <= ?`,
+		fingerprint, until, key, now.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return nil, err
+	}
+
+	var rec Record
+	err = r.db.QueryRowContext(ctx,
+		`SELECT fingerprint, status_code, content_type, body FROM idempotency_keys WHERE record_key = ?`,
+		key,
+	).Scan(&rec.Fingerprint, &rec.Status, &rec.ContentType, &rec.Body)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errors.New("idempotency: record vanished")
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *mySQLStore) Save(ctx context.Context, key string, rec *Record, expires time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE idempotency_keys
+SET status_code = ?, content_type = ?, body = ?, expires_at = ?
+WHERE record_key = ? AND fingerprint = ?`,
+		rec.Status, rec.ContentType, rec.Body, expires.UTC(), key, rec.Fingerprint,
+	)
+	return err
+}
+
+func (r *mySQLStore) Release(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE record_key = ? AND status_code = 0`, key)
+	return err
+}
+
+// sweep deletes expired records every 1000 reservations.
+func (r *mySQLStore) sweep(ctx context.Context, now time.Time) {
+	r.mu.Lock()
+	r.reserves++
+	due := r.reserves%1000 == 0
+	r.mu.Unlock()
+	if !due {
+		return
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE expires_at < ?`, now.UTC(),
+	); err != nil {
+		logging.FromContext(ctx).Warn("idempotency: failed to delete expired keys", "error", err)
+	}
+}
@@ -0,0 +1,155 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// loadDir reads every *.pem file in dir. The file name without extension is
+// the key ID. Files may hold a private key (PKCS#8 or PKCS#1 RSA), which can
+// sign and verify, or a public key (PKIX), which only verifies — useful for
+// keeping a retired key around until the tokens it signed have expired.
+//
+// A private key becomes active activationDelay after the file's modification
+// time, giving verifiers time to fetch it from the JWKS first.
+func loadDir(dir string, activationDelay time.Duration) (map[string]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*Key)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".pem" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := parseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		k.ID = strings.TrimSuffix(e.Name(), ".pem")
+		k.ActiveAt = info.ModTime().Add(activationDelay)
+		keys[k.ID] = k
+	}
+	return keys, nil
+}
+
+func parseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey(priv)
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey(priv)
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return publicKey(pub)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+func privateKey(priv interface{}) (*Key, error) {
+	switch p := priv.(type) {
+	case *rsa.PrivateKey:
+		if p.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key must be at least 2048 bits")
+		}
+		return &Key{Algorithm: AlgRS256, signingKey: p, verifyKey: &p.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &Key{Algorithm: AlgEdDSA, signingKey: p, verifyKey: p.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+func publicKey(pub interface{}) (*Key, error) {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		return &Key{Algorithm: AlgRS256, verifyKey: p}, nil
+	case ed25519.PublicKey:
+		return &Key{Algorithm: AlgEdDSA, verifyKey: p}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// GenerateKeyFile creates a new private key for alg (RS256 or EdDSA) in dir
+// and returns its key ID. IDs start with the UTC date so files sort by age.
+func GenerateKeyFile(dir, alg string) (string, error) {
+	var priv interface{}
+	switch alg {
+	case AlgEdDSA:
+		_, p, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", err
+		}
+		priv = p
+	case AlgRS256:
+		p, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return "", err
+		}
+		priv = p
+	default:
+		return "", fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	kid := time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(suffix)
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, kid+".pem"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		f.Close()
+		return "", err
+	}
+	return kid, f.Close()
+}
@@ -0,0 +1,257 @@
+// Package jwtkeys holds the keys used to sign and verify platform JWTs.
+//
+// In production tokens are signed with asymmetric keys (RS256 or EdDSA) loaded
+// from PEM files in a directory, so other services can verify them using the
+// public keys published as a JWKS. Every token carries the "kid" of the key
+// that signed it. Without a key directory the keyring falls back to HS256
+// with the shared JWT secret, which is meant for local development only.
+package jwtkeys
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+	"github.com/example/global-trade-hub/backend/internal/health"
+)
+
+// Supported signing algorithms.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no signing key available")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// Key is one entry of the keyring.
+type Key struct {
+	ID        string
+	Algorithm string
+	// ActiveAt is when the key may start signing. Keys are published in the
+	// JWKS before that so verifiers can pick them up ahead of use.
+	ActiveAt time.Time
+
+	signingKey interface{} // nil for verification-only keys
+	verifyKey  interface{}
+}
+
+// CanSign reports whether the key has private material.
+func (k *Key) CanSign() bool {
+	return k.signingKey != nil
+}
+
+// Keyring signs tokens with the current key and verifies tokens signed by any
+// key it holds. It is safe for concurrent use.
+type Keyring struct {
+	issuer          string
+	audience        string
+	dir             string
+	activationDelay time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]*Key
+}
+
+// New builds the keyring described by cfg: keys from cfg.JWTKeysDir, or the
+// HS256 development fallback when no directory is configured.
+func New(cfg *config.Config) (*Keyring, error) {
+	r := &Keyring{
+		issuer:          cfg.JWTIssuer,
+		audience:        cfg.JWTAudience,
+		dir:             cfg.JWTKeysDir,
+		activationDelay: cfg.JWTKeyActivationDelay,
+	}
+	if r.dir == "" {
+		if cfg.JWTSecret == "" {
+			return nil, errors.New("either JWT_KEYS_DIR or JWT_SECRET must be set")
+		}
+		k := NewHMACKey(cfg.JWTSecret)
+		r.keys = map[string]*Key{k.ID: k}
+		return r, nil
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewHMACKey returns an HS256 key whose ID is derived from the secret, so all
+// instances sharing the secret agree on it.
+func NewHMACKey(secret string) *Key {
+	sum := sha256.Sum256([]byte(secret))
+	return &Key{
+		ID:         "hs256-" + hex.EncodeToString(sum[:4]),
+		Algorithm:  AlgHS256,
+		signingKey: []byte(secret),
+		verifyKey:  []byte(secret),
+	}
+}
+
+// Issuer is the "iss" value of issued tokens.
+func (r *Keyring) Issuer() string { return r.issuer }
+
+// Audience is the "aud" value of issued tokens.
+func (r *Keyring) Audience() string { return r.audience }
+
+// Symmetric reports whether the keyring runs on the HS256 fallback.
+func (r *Keyring) Symmetric() bool { return r.dir == "" }
+
+// Reload re-reads the key directory. On error the current keys are kept.
+func (r *Keyring) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+	keys, err := loadDir(r.dir, r.activationDelay)
+	if err != nil {
+		return err
+	}
+	hasSigner := false
+	for _, k := range keys {
+		if k.CanSign() {
+			hasSigner = true
+			break
+		}
+	}
+	if !hasSigner {
+		return fmt.Errorf("%s: no private key found", r.dir)
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the key directory every interval until ctx is done, so keys
+// can be rotated by adding and removing files without a restart. It beats
+// the heartbeat ctx carries (see health.WithHeartbeat) on every reload.
+func (r *Keyring) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	if r.dir == "" || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		health.Beat(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				logger.Error("jwt keys: reload failed, keeping current keys", "error", err)
+			}
+		}
+	}
+}
+
+// Keys returns all keys ordered by ID.
+func (r *Keyring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*Key, 0, len(r.keys))
+	for _, k := range r.keys {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// SigningKey returns the key new tokens are signed with: the most recently
+// activated private key. If no key is active yet (e.g. all files were just
+// created), the one that becomes active first is used.
+func (r *Keyring) SigningKey() (*Key, error) {
+	now := time.Now()
+	var active, pending *Key
+	for _, k := range r.Keys() {
+		if !k.CanSign() {
+			continue
+		}
+		if !k.ActiveAt.After(now) {
+			if active == nil || !k.ActiveAt.Before(active.ActiveAt) {
+				active = k
+			}
+		} else if pending == nil || k.ActiveAt.Before(pending.ActiveAt) {
+			pending = k
+		}
+	}
+	if active != nil {
+		return active, nil
+	}
+	if pending != nil {
+		return pending, nil
+	}
+	return nil, ErrNoSigningKey
+}
+
+// Sign signs claims with the current signing key and sets the "kid" header.
+func (r *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k, err := r.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.signingKey)
+}
+
+// Parse verifies the token's signature, algorithm, issuer, audience and
+// expiry, and decodes it into claims.
+func (r *Keyring) Parse(tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, r.keyFunc,
+		jwt.WithValidMethods(r.algorithms()),
+		jwt.WithIssuer(r.issuer),
+		jwt.WithAudience(r.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return jwt.ErrTokenSignatureInvalid
+	}
+	return nil
+}
+
+// keyFunc resolves the verification key by "kid" and insists the token uses
+// that key's algorithm, so e.g. an RSA public key is never used as an HMAC
+// secret.
+func (r *Keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	r.mu.RLock()
+	k, ok := r.keys[kid]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != k.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), kid)
+	}
+	return k.verifyKey, nil
+}
+
+func (r *Keyring) algorithms() []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, k := range r.Keys() {
+		if !seen[k.Algorithm] {
+			seen[k.Algorithm] = true
+			algs = append(algs, k.Algorithm)
+		}
+	}
+	return algs
+}
@@ -0,0 +1,160 @@
+package jwtkeys
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+)
+
+// backdate moves a key file's modification time into the past so the key is
+// already active under the keyring's activation delay.
+func backdate(t *testing.T, dir, kid string, age time.Duration) {
+	t.Helper()
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(filepath.Join(dir, kid+".pem"), at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// retire replaces a private key file with its public key, as operators do
+// once a key no longer signs.
+func retire(t *testing.T, dir, kid string) {
+	t.Helper()
+	path := filepath.Join(dir, kid+".pem")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := parseKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(k.verifyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sign(t *testing.T, r *Keyring) (token, kid string) {
+	t.Helper()
+	token, err := r.Sign(jwt.RegisteredClaims{
+		Subject:   "user-1",
+		Issuer:    r.Issuer(),
+		Audience:  jwt.ClaimStrings{r.Audience()},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token, parsed.Header["kid"].(string)
+}
+
+func TestVerifyAfterKeyRotation(t *testing.T) {
+	for _, alg := range []string{AlgEdDSA, AlgRS256} {
+		t.Run(alg, func(t *testing.T) {
+			dir := t.TempDir()
+			oldKID, err := GenerateKeyFile(dir, alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			backdate(t, dir, oldKID, 2*time.Hour)
+			r, err := New(&config.Config{
+				JWTIssuer:             "global-trade-hub",
+				JWTAudience:           "global-trade-hub-api",
+				JWTKeysDir:            dir,
+				JWTKeyActivationDelay: time.Hour,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, kid := sign(t, r)
+			if kid != oldKID {
+				t.Fatalf("signed with %q, want %q", kid, oldKID)
+			}
+
+			newKID, err := GenerateKeyFile(dir, alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			steps := []struct {
+				name string
+				// prepare changes the key directory before the reload.
+				prepare    func()
+				wantSigner string
+				// wantBefore is the error verifying the token signed before
+				// the rotation.
+				wantBefore error
+			}{
+				{name: "new key pending", prepare: func() {}, wantSigner: oldKID},
+				{name: "new key active", prepare: func() { backdate(t, dir, newKID, 2*time.Hour) }, wantSigner: newKID},
+				{name: "old key retired", prepare: func() { retire(t, dir, oldKID) }, wantSigner: newKID},
+				{
+					name: "old key removed",
+					prepare: func() {
+						if err := os.Remove(filepath.Join(dir, oldKID+".pem")); err != nil {
+							t.Fatal(err)
+						}
+					},
+					wantSigner: newKID,
+					wantBefore: ErrUnknownKey,
+				},
+			}
+			for _, step := range steps {
+				step.prepare()
+				if err := r.Reload(); err != nil {
+					t.Fatalf("%s: reload: %v", step.name, err)
+				}
+				token, kid := sign(t, r)
+				if kid != step.wantSigner {
+					t.Errorf("%s: signed with %q, want %q", step.name, kid, step.wantSigner)
+				}
+				if err := r.Parse(token, &jwt.RegisteredClaims{}); err != nil {
+					t.Errorf("%s: new token: %v", step.name, err)
+				}
+				if err := r.Parse(before, &jwt.RegisteredClaims{}); !errors.Is(err, step.wantBefore) {
+					t.Errorf("%s: token signed before rotation: %v, want %v", step.name, err, step.wantBefore)
+				}
+			}
+		})
+	}
+}
+
+func TestReloadKeepsKeysWithoutSigner(t *testing.T) {
+	dir := t.TempDir()
+	kid, err := GenerateKeyFile(dir, AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(&config.Config{JWTIssuer: "global-trade-hub", JWTAudience: "global-trade-hub-api", JWTKeysDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := sign(t, r)
+
+	retire(t, dir, kid)
+	if err := r.Reload(); err == nil {
+		t.Fatal("reload without a private key succeeded")
+	}
+	if err := r.Parse(token, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("token after failed reload: %v", err)
+	}
+	if _, signer := sign(t, r); signer != kid {
+		t.Errorf("signed with %q after failed reload, want %q", signer, kid)
+	}
+}
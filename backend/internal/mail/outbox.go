@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMailer writes every message as an .eml file into a directory instead
+// of sending it. It is meant for local development and tests, where the
+// verification and reset links can be read straight from disk.
+type OutboxMailer struct {
+	dir  string
+	from string
+}
+
+// NewOutboxMailer returns a Mailer that writes messages into dir.
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	return &OutboxMailer{dir: dir, from: from}
+}
+
+// Send writes msg to <dir>/<timestamp>-<id>.eml.
+func (m *OutboxMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+	return os.WriteFile(filepath.Join(m.dir, name), render(m.from, msg), 0o600)
+}
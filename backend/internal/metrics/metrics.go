@@ -0,0 +1,188 @@
+// Package metrics exposes Prometheus metrics: HTTP request counts and
+// latencies per route, database pool statistics, and business counters that
+// domain services increment (see the services' WithMetrics setters).
+//
+// All recording methods are safe to call on a nil *Metrics, so services work
+// unchanged when metrics are not configured.
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gth"
+
+// Metrics owns the registry and every collector of the API.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	ordersCreated prometheus.Counter
+	rfqsSubmitted prometheus.Counter
+	rfqResponses  prometheus.Counter
+	registrations *prometheus.CounterVec
+	loginFailures *prometheus.CounterVec
+	cacheLookups  *prometheus.CounterVec
+}
+
+// New registers the API's collectors, plus Go runtime, process and, when db
+// is not nil, connection pool statistics.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"method", "route", "status"}),
+		ordersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_created_total",
+			Help:      "Orders placed by buyers.",
+		}),
+		rfqsSubmitted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rfqs_submitted_total",
+			Help:      "Requests for quotation submitted by buyers.",
+		}),
+		rfqResponses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rfq_responses_total",
+			Help:      "Quotes sent by suppliers in response to RFQs.",
+		}),
+		registrations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "registrations_total",
+			Help:      "New accounts by role and method (password, oidc, invitation).",
+		}, []string{"role", "method"}),
+		loginFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "login_failures_total",
+			Help:      "Rejected login attempts by reason.",
+		}, []string{"reason"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "cache_requests_total",
+			Help:      "Requests to cached routes by route template and result (hit, miss, not_modified).",
+		}, []string{"route", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.ordersCreated,
+		m.rfqsSubmitted,
+		m.rfqResponses,
+		m.registrations,
+		m.loginFailures,
+		m.cacheLookups,
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "mysql"))
+	}
+	return m
+}
+
+// Middleware records the count and latency of every request under its route
+// template (e.g. /api/v1/orders/:id), so that IDs do not create new series.
+// Requests that match no route are recorded as "unmatched".
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics in the Prometheus exposition format. When token
+// is set, requests must send it as "Authorization: Bearer <token>".
+func (m *Metrics) Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// OrderCreated counts a new order.
+func (m *Metrics) OrderCreated() {
+	if m != nil {
+		m.ordersCreated.Inc()
+	}
+}
+
+// RFQSubmitted counts a new RFQ.
+func (m *Metrics) RFQSubmitted() {
+	if m != nil {
+		m.rfqsSubmitted.Inc()
+	}
+}
+
+// RFQResponded counts a supplier's quote.
+func (m *Metrics) RFQResponded() {
+	if m != nil {
+		m.rfqResponses.Inc()
+	}
+}
+
+// UserRegistered counts a new account created with method (password, oidc
+// or invitation).
+func (m *Metrics) UserRegistered(role, method string) {
+	if m != nil {
+		m.registrations.WithLabelValues(role, method).Inc()
+	}
+}
+
+// LoginFailed counts a rejected login; reason is a short fixed string such as
+// "invalid_password", "invalid_mfa_code" or "throttled".
+func (m *Metrics) LoginFailed(reason string) {
+	if m != nil {
+		m.loginFailures.WithLabelValues(reason).Inc()
+	}
+}
+
+// CacheLookup counts a request to a cached route; result is "hit", "miss" or
+// "not_modified".
+func (m *Metrics) CacheLookup(route, result string) {
+	if m != nil {
+		m.cacheLookups.WithLabelValues(route, result).Inc()
+	}
+}
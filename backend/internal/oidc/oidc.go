@@ -0,0 +1,234 @@
+// Package oidc implements the relying-party side of OpenID Connect login
+// (authorization code flow with PKCE) for the identity providers configured
+// in config.Config.OIDCProviders.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/example/global-trade-hub/backend/internal/config"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidIDToken  = errors.New("invalid id token")
+)
+
+// publicRoles are the roles accounts created on first OIDC login may get;
+// admin and staff accounts require an invitation.
+var publicRoles = map[string]bool{
+	"buyer":    true,
+	"supplier": true,
+	"market":   true,
+	"visitor":  true,
+}
+
+// Identity is the verified subset of ID token claims we rely on.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is one configured identity provider. Discovery runs on first use,
+// so the API can start while an IdP is unreachable.
+type Provider struct {
+	Name        string
+	DisplayName string
+	DefaultRole string
+
+	issuer       string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	redirectURL  string
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]*Provider
+	secure    bool
+}
+
+// New builds the registry from cfg. Callback URLs are
+// <APIBaseURL>/api/v1/auth/oidc/<name>/callback.
+func New(cfg *config.Config) (*Registry, error) {
+	r := &Registry{
+		providers: make(map[string]*Provider, len(cfg.OIDCProviders)),
+		secure:    strings.HasPrefix(cfg.APIBaseURL, "https://"),
+	}
+	base := strings.TrimRight(cfg.APIBaseURL, "/")
+	for _, pc := range cfg.OIDCProviders {
+		if pc.Name == "" || pc.Issuer == "" || pc.ClientID == "" {
+			return nil, fmt.Errorf("oidc provider %q: name, issuer and client_id are required", pc.Name)
+		}
+		if _, dup := r.providers[pc.Name]; dup {
+			return nil, fmt.Errorf("oidc provider %q configured twice", pc.Name)
+		}
+
+		scopes := pc.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+		}
+		display := pc.DisplayName
+		if display == "" {
+			display = pc.Name
+		}
+		role := pc.DefaultRole
+		if role == "" {
+			role = cfg.OIDCDefaultRole
+		}
+		if !publicRoles[role] {
+			return nil, fmt.Errorf("oidc provider %q: default role %q is not allowed", pc.Name, role)
+		}
+
+		r.providers[pc.Name] = &Provider{
+			Name:         pc.Name,
+			DisplayName:  display,
+			DefaultRole:  role,
+			issuer:       pc.Issuer,
+			clientID:     pc.ClientID,
+			clientSecret: pc.ClientSecret,
+			scopes:       scopes,
+			redirectURL:  base + "/api/v1/auth/oidc/" + pc.Name + "/callback",
+		}
+	}
+	return r, nil
+}
+
+// Get returns the provider called name.
+func (r *Registry) Get(name string) (*Provider, error) {
+	if r == nil {
+		return nil, ErrUnknownProvider
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Providers returns all providers ordered by name.
+func (r *Registry) Providers() []*Provider {
+	if r == nil {
+		return nil
+	}
+	out := make([]*Provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// SecureCookies reports whether the API is served over HTTPS, so flow
+// cookies can be marked Secure.
+func (r *Registry) SecureCookies() bool {
+	return r != nil && r.secure
+}
+
+// AuthCodeURL returns the IdP authorization URL for state, nonce and the
+// PKCE verifier (sent as its S256 challenge).
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state,
+		gooidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(verifier),
+	), nil
+}
+
+// Exchange redeems an authorization code and verifies the returned ID token
+// (signature, issuer, audience, expiry and nonce).
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	oauth, idVerifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc %s: code exchange failed: %w", p.Name, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	var claims struct {
+		Email         string      `json:"email"`
+		EmailVerified interface{} `json:"email_verified"`
+		Name          string      `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	return &Identity{
+		Provider:      p.Name,
+		Subject:       idToken.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: truthy(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover fetches the provider's metadata once; failures are retried on the
+// next call.
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+	provider, err := gooidc.NewProvider(ctx, p.issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc %s: discovery failed: %w", p.Name, err)
+	}
+	p.oauth = &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.scopes,
+	}
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.clientID})
+	return p.oauth, p.verifier, nil
+}
+
+// truthy accepts email_verified as a boolean or the string "true", which
+// some providers (e.g. older Azure AD / Cognito setups) send.
+func truthy(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return strings.EqualFold(b, "true")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,380 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// direction tells whether a type is read from requests or written to
+// responses. Request fields are required when their binding tag says so;
+// response fields are always present unless tagged omitempty.
+type direction int
+
+const (
+	request direction = iota + 1
+	response
+)
+
+func (d direction) String() string {
+	if d == request {
+		return "request"
+	}
+	return "response"
+}
+
+// EnumType lists the values of a named string type, see Enum.
+type EnumType struct {
+	typ    reflect.Type
+	values []any
+}
+
+// Enum returns the values of the string type T, e.g.
+// Enum(auth.RoleBuyer, auth.RoleSupplier). Fields of type T are then
+// documented with a reference to a schema listing the values.
+func Enum[T ~string](values ...T) EnumType {
+	e := EnumType{typ: reflect.TypeFor[T]()}
+	for _, v := range values {
+		e.values = append(e.values, string(v))
+	}
+	return e
+}
+
+// Schemas derives JSON schemas from Go types. Named struct and enum types
+// become components, named "<package>.<Type>" (e.g. "product.Product"), and
+// are referenced; anonymous and generic types are inlined.
+//
+// Pointers, slices and maps are nullable, as encoding/json writes nil ones as
+// null. Constraints are read from `binding` tags (required, min, max, len,
+// gt, gte, lt, lte, oneof, email, url, uuid).
+type Schemas struct {
+	components map[string]*Schema
+	directions map[reflect.Type]direction
+	enums      map[reflect.Type][]any
+	errs       []string
+}
+
+// NewSchemas returns an empty set of schemas.
+func NewSchemas(enums ...EnumType) *Schemas {
+	s := &Schemas{
+		components: map[string]*Schema{},
+		directions: map[reflect.Type]direction{},
+		enums:      map[reflect.Type][]any{},
+	}
+	for _, e := range enums {
+		s.enums[e.typ] = e.values
+	}
+	return s
+}
+
+// Components returns the named schemas collected so far.
+func (s *Schemas) Components() map[string]*Schema { return s.components }
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// value returns the schema of a value of type t, nullable for pointers,
+// slices and maps.
+func (s *Schemas) value(t reflect.Type, dir direction) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(s.value(t.Elem(), dir))
+	case reflect.Slice, reflect.Map:
+		if t != rawMessageType {
+			return nullable(s.schema(t, dir))
+		}
+	}
+	return s.schema(t, dir)
+}
+
+// schema returns the schema of t, which is not a pointer.
+func (s *Schemas) schema(t reflect.Type, dir direction) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	if values, ok := s.enums[t]; ok {
+		name := componentName(t)
+		if _, done := s.components[name]; !done {
+			s.components[name] = &Schema{Type: "string", Enum: values}
+		}
+		return Ref(name)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.value(t.Elem(), dir)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.value(t.Elem(), dir)}
+	case reflect.Pointer:
+		return s.schema(t.Elem(), dir)
+	case reflect.Struct:
+		name := componentName(t)
+		if name == "" {
+			return s.object(t, dir)
+		}
+		if prev, ok := s.directions[t]; ok {
+			if prev != dir {
+				s.errs = append(s.errs, fmt.Sprintf("%s is used in both a %s and a %s", name, prev, dir))
+			}
+			return Ref(name)
+		}
+		if _, ok := s.components[name]; ok {
+			s.errs = append(s.errs, fmt.Sprintf("two types are named %s", name))
+			return Ref(name)
+		}
+		s.directions[t] = dir // before recursing, for recursive types
+		s.components[name] = s.object(t, dir)
+		return Ref(name)
+	}
+	s.errs = append(s.errs, fmt.Sprintf("unsupported type %s", t))
+	return &Schema{}
+}
+
+// object returns the schema of struct type t.
+func (s *Schemas) object(t reflect.Type, dir direction) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(obj, t, dir)
+	return obj
+}
+
+// fields adds the JSON fields of struct type t to obj, flattening embedded
+// structs the way encoding/json does.
+func (s *Schemas) fields(obj *Schema, t reflect.Type, dir direction) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				s.fields(obj, et, dir)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs, required := s.field(f.Type, f.Tag.Get("binding"), dir)
+		if dir == response {
+			required = !omitempty
+		}
+		obj.Properties[name] = fs
+		if required {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
+
+// field returns the schema of a field of type t with the given binding tag,
+// and whether the tag makes the field required.
+func (s *Schemas) field(t reflect.Type, binding string, dir direction) (*Schema, bool) {
+	wrap := false
+	if t.Kind() == reflect.Pointer {
+		t, wrap = t.Elem(), true
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Map) && t != rawMessageType {
+		wrap = true
+	}
+	fs, required := constrain(s.schema(t, dir), t, binding)
+	if wrap {
+		fs = nullable(fs)
+	}
+	return fs, required
+}
+
+// jsonName returns the name encoding/json uses for f, whether it is
+// omitempty, and whether the field is skipped.
+func jsonName(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// componentName returns "<package>.<Type>" for named, non-generic types and
+// "" for the others.
+func componentName(t reflect.Type) string {
+	if t.Name() == "" || strings.Contains(t.Name(), "[") {
+		return ""
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "" {
+		return t.Name()
+	}
+	return pkg + "." + t.Name()
+}
+
+// constrain applies the validator rules of a binding tag to sch, the schema
+// of a value of type t, and reports whether the rules require the field.
+// Rules after "dive" apply to elements and are ignored.
+func constrain(sch *Schema, t reflect.Type, binding string) (*Schema, bool) {
+	if binding == "" {
+		return sch, false
+	}
+	rules := strings.Split(binding, ",")
+	omitempty, required, sized := false, false, false
+	for _, r := range rules {
+		switch {
+		case r == "dive":
+			return sch, required
+		case r == "omitempty":
+			omitempty = true
+		case r == "required":
+			required = true
+		case strings.HasPrefix(r, "min="), strings.HasPrefix(r, "len="):
+			sized = true
+		}
+	}
+	if sch.Ref != "" {
+		// A named enum: only oneof narrows it further.
+		for _, r := range rules {
+			if v, ok := strings.CutPrefix(r, "oneof="); ok {
+				return oneOf(t, v, omitempty), required
+			}
+		}
+		return sch, required
+	}
+
+	for _, r := range rules {
+		if r == "dive" {
+			break
+		}
+		key, param, _ := strings.Cut(r, "=")
+		n, numErr := strconv.ParseFloat(param, 64)
+		switch key {
+		case "required":
+			if t.Kind() == reflect.String && !sized {
+				sch.MinLength = intPtr(1)
+			}
+		case "min", "max", "len":
+			if numErr != nil {
+				continue
+			}
+			setSize(sch, t, key, n, omitempty)
+		case "gt":
+			sch.ExclusiveMinimum = &n
+		case "gte":
+			sch.Minimum = &n
+		case "lt":
+			sch.ExclusiveMaximum = &n
+		case "lte":
+			sch.Maximum = &n
+		case "oneof":
+			sch = oneOf(t, param, omitempty)
+		case "email":
+			sch.Format = "email"
+		case "url":
+			sch.Format = "uri"
+		case "uuid":
+			sch.Format = "uuid"
+		}
+	}
+	return sch, required
+}
+
+// setSize applies a min, max or len rule, which bounds the length of strings,
+// slices and maps and the value of numbers. With omitempty the zero value
+// passes validation, so lower bounds of lengths are dropped.
+func setSize(sch *Schema, t reflect.Type, rule string, n float64, omitempty bool) {
+	lower, upper := rule == "min" || rule == "len", rule == "max" || rule == "len"
+	if omitempty {
+		lower = false
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			sch.MinLength = intPtr(int(n))
+		}
+		if upper {
+			sch.MaxLength = intPtr(int(n))
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			sch.MinItems = intPtr(int(n))
+		}
+		if upper {
+			sch.MaxItems = intPtr(int(n))
+		}
+	default:
+		if rule == "min" || rule == "len" {
+			sch.Minimum = &n
+		}
+		if upper {
+			sch.Maximum = &n
+		}
+	}
+}
+
+// oneOf returns the enum schema of a oneof rule; the empty value is allowed
+// with omitempty.
+func oneOf(t reflect.Type, param string, omitempty bool) *Schema {
+	sch := &Schema{Type: "string"}
+	if t.Kind() != reflect.String {
+		sch.Type = "integer"
+	}
+	for _, v := range strings.Fields(param) {
+		if sch.Type == "integer" {
+			if n, err := strconv.Atoi(v); err == nil {
+				sch.Enum = append(sch.Enum, n)
+			}
+			continue
+		}
+		sch.Enum = append(sch.Enum, v)
+	}
+	if omitempty {
+		if sch.Type == "string" {
+			sch.Enum = append(sch.Enum, "")
+		} else {
+			sch.Enum = append(sch.Enum, 0)
+		}
+	}
+	return sch
+}
+
+func intPtr(n int) *int { return &n }
@@ -0,0 +1,390 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+// Operation describes a route for the document: what it does and which Go
+// types it reads and writes. Query, Body and the values of Responses are
+// zero values of those types, e.g. product.CreateInput{}.
+type Operation struct {
+	// ID is the operationId. By default it is derived from the handler,
+	// e.g. "productCreate" for product.(*Handler).Create.
+	ID          string
+	Summary     string
+	Description string
+	// Tag groups the operation in the docs; by default it is the first
+	// path segment after /api/vN.
+	Tag string
+	// Query is a struct whose `form` fields are the query parameters. Their
+	// `default` and `doc` tags give the default value and description, and
+	// `deprecated:"true"` marks parameters kept for old clients.
+	Query any
+	// Header is a struct whose `header` fields are request headers,
+	// described like Query's fields.
+	Header any
+	// Body is the JSON request body.
+	Body any
+	// Responses maps status codes to bodies; nil means no body. A body is
+	// JSON unless it is a Content. Error responses (problem+json) are
+	// documented for every operation and need not be listed.
+	Responses  map[int]any
+	Deprecated bool
+	// Optional operations describe routes that are only registered in some
+	// configurations; Build does not complain when they are missing.
+	Optional bool
+}
+
+// Operations holds the operation of every route, keyed by "METHOD /path"
+// with Gin path syntax, like authz.Policy.
+type Operations map[string]Operation
+
+// Content is a response body in several content types, e.g.
+// Content{"application/json": Export{}, "application/zip": Binary{}}.
+type Content map[string]any
+
+// Binary is a body that is not JSON.
+type Binary struct{}
+
+// Alternatives is a JSON body of one of several types, see OneOf.
+type Alternatives []any
+
+// OneOf returns a body that is one of the given types.
+func OneOf(bodies ...any) Alternatives { return Alternatives(bodies) }
+
+// Access returns the permission a route requires and whether it is public,
+// i.e. needs no credentials.
+type Access func(method, path string) (permission string, public bool)
+
+// Spec is the OpenAPI document of the API, built once the routes are
+// registered. It serves the document and a docs UI, and validates traffic
+// against it (see Validator).
+type Spec struct {
+	info   Info
+	ops    Operations
+	enums  []EnumType
+	access Access
+
+	doc        *Document
+	json       []byte
+	operations map[string]*compiledOperation
+}
+
+// New returns a spec for the given operations. Call Build once all routes are
+// registered.
+func New(info Info, ops Operations) *Spec {
+	return &Spec{info: info, ops: ops}
+}
+
+// WithEnums documents the values of named string types, see Enum.
+func (s *Spec) WithEnums(enums ...EnumType) {
+	s.enums = append(s.enums, enums...)
+}
+
+// WithAccess documents which routes need credentials and what permission
+// they require. Without it every route is documented as public.
+func (s *Spec) WithAccess(access Access) {
+	s.access = access
+}
+
+// Document returns the built document, or nil before Build.
+func (s *Spec) Document() *Document { return s.doc }
+
+// Build builds the document from the registered routes. Like authz.Policy,
+// it fails when a route has no operation or an operation matches no route,
+// so that the document always covers the API.
+func (s *Spec) Build(routes gin.RoutesInfo) error {
+	routes = append(gin.RoutesInfo(nil), routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return methodOrder(routes[i].Method) < methodOrder(routes[j].Method)
+	})
+
+	schemas := NewSchemas(s.enums...)
+	problem := schemas.schema(reflect.TypeFor[middleware.Problem](), response)
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Access token from /api/v1/auth/login."},
+				"apiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "API key from /api/v1/me/api-keys."},
+			},
+		},
+	}
+
+	var problems []string
+	registered := make(map[string]bool, len(routes))
+	ids := map[string]string{}
+	tags := map[string]bool{}
+	ginPaths := map[string]string{}
+	for _, r := range routes {
+		key := r.Method + " " + r.Path
+		registered[key] = true
+		op, ok := s.ops[key]
+		if !ok {
+			problems = append(problems, "no operation for "+key)
+			continue
+		}
+
+		obj := s.operation(schemas, r, op, problem)
+		if prev, dup := ids[obj.OperationID]; dup {
+			problems = append(problems, fmt.Sprintf("operation ID %q of %s is also used by %s", obj.OperationID, key, prev))
+		}
+		ids[obj.OperationID] = key
+
+		path := openAPIPath(r.Path)
+		ginPaths[r.Path] = path
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(r.Method)] = obj
+
+		for _, t := range obj.Tags {
+			if !tags[t] {
+				tags[t] = true
+				doc.Tags = append(doc.Tags, Tag{Name: t})
+			}
+		}
+	}
+	for key, op := range s.ops {
+		if !registered[key] && !op.Optional {
+			problems = append(problems, "operation for unregistered route "+key)
+		}
+	}
+	for _, e := range schemas.errs {
+		problems = append(problems, e)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi: document does not match the routes:\n  %s", strings.Join(problems, "\n  "))
+	}
+	doc.Components.Schemas = schemas.Components()
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("openapi: marshal document: %w", err)
+	}
+	compiled, err := compile(raw, doc, ginPaths)
+	if err != nil {
+		return err
+	}
+	s.doc, s.json, s.operations = doc, raw, compiled
+	return nil
+}
+
+// operation builds the document's operation for route r.
+func (s *Spec) operation(schemas *Schemas, r gin.RouteInfo, op Operation, problem *Schema) *OperationObject {
+	obj := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Responses:   map[string]*Response{},
+		Deprecated:  op.Deprecated,
+	}
+	if obj.OperationID == "" {
+		obj.OperationID = operationID(r)
+	}
+	tag := op.Tag
+	if tag == "" {
+		tag = defaultTag(r.Path)
+	}
+	obj.Tags = []string{tag}
+
+	if s.access != nil {
+		perm, public := s.access(r.Method, r.Path)
+		if !public {
+			obj.Security = []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+			obj.Permission = perm
+		}
+	}
+
+	for _, seg := range strings.Split(r.Path, "/") {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			obj.Parameters = append(obj.Parameters, &Parameter{
+				Name: seg[1:], In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+	}
+	if op.Query != nil {
+		obj.Parameters = append(obj.Parameters, parameters(schemas, reflect.TypeOf(op.Query), "form", "query")...)
+	}
+	if op.Header != nil {
+		obj.Parameters = append(obj.Parameters, parameters(schemas, reflect.TypeOf(op.Header), "header", "header")...)
+	}
+	if op.Body != nil {
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: schemas.value(reflect.TypeOf(op.Body), request)}},
+		}
+	}
+
+	for status, body := range op.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		switch b := body.(type) {
+		case nil:
+		case Content:
+			resp.Content = map[string]*MediaType{}
+			for ct, v := range b {
+				resp.Content[ct] = &MediaType{Schema: bodySchema(schemas, v)}
+			}
+		default:
+			resp.Content = map[string]*MediaType{"application/json": {Schema: bodySchema(schemas, b)}}
+		}
+		if status == http.StatusFound || status == http.StatusSeeOther {
+			resp.Headers = map[string]*Header{"Location": {Schema: &Schema{Type: "string", Format: "uri"}}}
+		}
+		obj.Responses[strconv.Itoa(status)] = resp
+	}
+	obj.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]*MediaType{middleware.ProblemContentType: {Schema: problem}},
+	}
+	return obj
+}
+
+// bodySchema returns the schema of a response body.
+func bodySchema(schemas *Schemas, body any) *Schema {
+	switch b := body.(type) {
+	case Binary:
+		return &Schema{Type: "string", Format: "binary"}
+	case *Schema:
+		return b
+	case Alternatives:
+		sch := &Schema{}
+		for _, v := range b {
+			sch.OneOf = append(sch.OneOf, bodySchema(schemas, v))
+		}
+		return sch
+	}
+	return schemas.value(reflect.TypeOf(body), response)
+}
+
+// parameters returns the parameters, located in in, of the fields of struct
+// t named by their tag (e.g. `form` for query parameters).
+func parameters(schemas *Schemas, t reflect.Type, tag, in string) []*Parameter {
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		sch, required := constrain(schemas.schema(ft, request), ft, f.Tag.Get("binding"))
+		if def, ok := f.Tag.Lookup("default"); ok {
+			sch.Default = parseDefault(ft, def)
+		}
+		deprecated, _ := strconv.ParseBool(f.Tag.Get("deprecated"))
+		params = append(params, &Parameter{
+			Name: name, In: in, Description: f.Tag.Get("doc"), Required: required,
+			Deprecated: deprecated, Schema: sch,
+		})
+	}
+	return params
+}
+
+func parseDefault(t reflect.Type, s string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.Atoi(s); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+// openAPIPath turns Gin's ":id" and "*path" segments into "{id}" and "{path}".
+func openAPIPath(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			segs[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// operationID derives an ID from the handler, e.g. "productCreate" from
+// ".../domain/product.(*Handler).Create-fm", falling back to the method and
+// path for closures.
+func operationID(r gin.RouteInfo) string {
+	h := r.Handler[strings.LastIndex(r.Handler, "/")+1:]
+	pkg, rest, _ := strings.Cut(h, ".")
+	method := strings.TrimSuffix(rest[strings.LastIndex(rest, ".")+1:], "-fm")
+	if strings.HasPrefix(rest, "(") && method != "" && !strings.HasPrefix(method, "func") {
+		return pkg + method
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.ToLower(r.Method))
+	for _, seg := range strings.FieldsFunc(r.Path, func(c rune) bool { return c == '/' || c == '-' || c == '.' || c == '_' }) {
+		seg = strings.TrimLeft(seg, ":*")
+		b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	return b.String()
+}
+
+// defaultTag returns the first path segment after "/api/vN", or the first
+// segment for other paths.
+func defaultTag(path string) string {
+	segs := strings.FieldsFunc(path, func(c rune) bool { return c == '/' })
+	if len(segs) > 2 && segs[0] == "api" && strings.HasPrefix(segs[1], "v") {
+		return segs[2]
+	}
+	if len(segs) > 0 {
+		return segs[0]
+	}
+	return "default"
+}
+
+func methodOrder(m string) int {
+	for i, o := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		if m == o {
+			return i
+		}
+	}
+	return 99
+}
+
+//go:embed docs.html
+var docsHTML []byte
+
+// ServeJSON serves the document.
+func (s *Spec) ServeJSON(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/json", s.json)
+}
+
+// ServeUI serves an interactive docs page for the document, which it expects
+// at "openapi.json" next to the page.
+func (s *Spec) ServeUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
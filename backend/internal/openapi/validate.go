@@ -0,0 +1,341 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+)
+
+// documentURL is the URL the document is compiled under; it is never fetched.
+const documentURL = "https://api.invalid/openapi.json"
+
+// compiledOperation holds the compiled schemas of one operation.
+type compiledOperation struct {
+	query map[string]*queryParam
+	body  *jsonschema.Schema
+	// responses maps status ("200", ..., "default") and media type to the
+	// body schema; a nil schema means the response has no body.
+	responses map[string]map[string]*jsonschema.Schema
+}
+
+type queryParam struct {
+	schema   *jsonschema.Schema
+	typ      string
+	required bool
+}
+
+// compile compiles the schemas of every operation in doc, whose JSON
+// encoding is raw, keyed by "METHOD /path" with Gin path syntax.
+func compile(raw []byte, doc *Document, ginPaths map[string]string) (map[string]*compiledOperation, error) {
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+	if err := c.AddResource(documentURL, v); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	out := map[string]*compiledOperation{}
+	for ginPath, path := range ginPaths {
+		for method, obj := range *doc.Paths[path] {
+			base := "#/paths/" + pointerEscape(path) + "/" + method
+			load := func(ptr string) (*jsonschema.Schema, error) {
+				sch, err := c.Compile(documentURL + base + ptr)
+				if err != nil {
+					return nil, fmt.Errorf("openapi: compile %s %s%s: %w", strings.ToUpper(method), path, ptr, err)
+				}
+				return sch, nil
+			}
+
+			op := &compiledOperation{query: map[string]*queryParam{}, responses: map[string]map[string]*jsonschema.Schema{}}
+			for i, p := range obj.Parameters {
+				if p.In != "query" {
+					continue
+				}
+				sch, err := load(fmt.Sprintf("/parameters/%d/schema", i))
+				if err != nil {
+					return nil, err
+				}
+				typ, _ := p.Schema.Type.(string)
+				op.query[p.Name] = &queryParam{schema: sch, typ: typ, required: p.Required}
+			}
+			if obj.RequestBody != nil {
+				if op.body, err = load("/requestBody/content/application~1json/schema"); err != nil {
+					return nil, err
+				}
+			}
+			for status, resp := range obj.Responses {
+				op.responses[status] = map[string]*jsonschema.Schema{}
+				for ct := range resp.Content {
+					sch, err := load("/responses/" + status + "/content/" + pointerEscape(ct) + "/schema")
+					if err != nil {
+						return nil, err
+					}
+					op.responses[status][ct] = sch
+				}
+			}
+			out[strings.ToUpper(method)+" "+ginPath] = op
+		}
+	}
+	return out, nil
+}
+
+// pointerEscape escapes a JSON pointer token (RFC 6901) for use in a URL
+// fragment.
+func pointerEscape(s string) string {
+	s = strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+	return strings.NewReplacer("{", "%7B", "}", "%7D").Replace(s)
+}
+
+// Validator returns a middleware that checks requests against the document
+// and rejects those that do not match with a 400 validation error naming the
+// offending fields, before the handler runs. Routes without an operation
+// pass through.
+//
+// With responses it also checks the JSON bodies handlers write and logs
+// mismatches; as that buffers every response, it is meant for development.
+func (s *Spec) Validator(responses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Request.Method + " " + c.FullPath()
+		op := s.operations[key]
+		if op == nil {
+			c.Next()
+			return
+		}
+		if err := op.validateRequest(c.Request); err != nil {
+			middleware.Abort(c, err)
+			return
+		}
+		if !responses {
+			c.Next()
+			return
+		}
+
+		rec := &recorder{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+		c.Writer = rec.ResponseWriter
+		if !rec.Written() {
+			// Nothing written yet: an error that ErrorHandler renders.
+			return
+		}
+		if fields, err := op.validateResponse(rec.Status(), rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+			logging.FromContext(c.Request.Context()).Error("openapi: response does not match the document",
+				"route", key, "status", rec.Status(), "error", err, "fields", fields)
+		}
+	}
+}
+
+// recorder keeps a copy of the response body.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// validateRequest checks the query parameters and body of req.
+func (op *compiledOperation) validateRequest(req *http.Request) error {
+	var fields []apperr.FieldError
+	query := req.URL.Query()
+	for name, p := range op.query {
+		values, ok := query[name]
+		if !ok {
+			if p.required {
+				fields = append(fields, apperr.FieldError{Field: name, Code: "required"})
+			}
+			continue
+		}
+		v, ok := coerce(values[0], p.typ)
+		if !ok {
+			fields = append(fields, apperr.FieldError{Field: name, Code: "type", Param: p.typ})
+			continue
+		}
+		fields = append(fields, fieldErrors(name, p.schema.Validate(v))...)
+	}
+
+	if op.body != nil {
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			return apperr.Invalid(err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(raw))
+		if len(bytes.TrimSpace(raw)) == 0 {
+			return apperr.Invalid(io.EOF)
+		}
+		v, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			e := apperr.Invalid(err)
+			if e.Code == apperr.CodeBadRequest {
+				e = &apperr.Error{Status: http.StatusBadRequest, Code: "malformed_json", Message: "request body is not valid JSON", Err: err}
+			}
+			return e
+		}
+		fields = append(fields, fieldErrors("", op.body.Validate(v))...)
+	}
+
+	if len(fields) > 0 {
+		return apperr.Validation("request does not match the API specification", fields...)
+	}
+	return nil
+}
+
+// validateResponse checks a response body against the schema of its status
+// and content type.
+func (op *compiledOperation) validateResponse(status int, contentType string, body []byte) ([]apperr.FieldError, error) {
+	byType, ok := op.responses[strconv.Itoa(status)]
+	if !ok {
+		byType = op.responses["default"]
+		if status < http.StatusBadRequest {
+			return nil, fmt.Errorf("undocumented status %d", status)
+		}
+	}
+	if len(byType) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return nil, errors.New("undocumented response body")
+		}
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	sch, ok := byType[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("undocumented content type %q", contentType)
+	}
+	if mediaType != "application/json" && mediaType != middleware.ProblemContentType {
+		return nil, nil
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if fields := fieldErrors("", sch.Validate(v)); len(fields) > 0 {
+		return fields, errors.New("body does not match the schema")
+	}
+	return nil, nil
+}
+
+// coerce converts a query parameter to the JSON type of its schema.
+func coerce(s, typ string) (any, bool) {
+	switch typ {
+	case "integer":
+		n, err := strconv.ParseInt(s, 10, 64)
+		return n, err == nil
+	case "number":
+		n, err := strconv.ParseFloat(s, 64)
+		return n, err == nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	}
+	return s, true
+}
+
+// fieldErrors flattens a jsonschema validation error into field errors, with
+// field paths like apperr.Invalid ("items[0].quantity") under prefix.
+func fieldErrors(prefix string, err error) []apperr.FieldError {
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+	var out []apperr.FieldError
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		field := fieldPath(prefix, e.InstanceLocation)
+		switch k := e.ErrorKind.(type) {
+		case *kind.Required:
+			for _, name := range k.Missing {
+				out = append(out, apperr.FieldError{Field: fieldPath(field, []string{name}), Code: "required"})
+			}
+			return
+		case *kind.Type:
+			if len(k.Want) == 1 && k.Want[0] == "null" {
+				// The null branch of a nullable schema; the other branch
+				// reports the actual problem.
+				return
+			}
+			out = append(out, apperr.FieldError{Field: field, Code: "type", Param: strings.Join(k.Want, ",")})
+			return
+		}
+		code, param := "invalid", ""
+		if kw := e.ErrorKind.KeywordPath(); len(kw) > 0 {
+			code = kw[len(kw)-1]
+		}
+		switch k := e.ErrorKind.(type) {
+		case *kind.MinLength:
+			param = strconv.Itoa(k.Want)
+		case *kind.MaxLength:
+			param = strconv.Itoa(k.Want)
+		case *kind.MinItems:
+			param = strconv.Itoa(k.Want)
+		case *kind.MaxItems:
+			param = strconv.Itoa(k.Want)
+		case *kind.Minimum:
+			param = k.Want.RatString()
+		case *kind.Maximum:
+			param = k.Want.RatString()
+		case *kind.ExclusiveMinimum:
+			param = k.Want.RatString()
+		case *kind.ExclusiveMaximum:
+			param = k.Want.RatString()
+		case *kind.Format:
+			param = k.Want
+		case *kind.Enum:
+			values := make([]string, len(k.Want))
+			for i, v := range k.Want {
+				values[i] = fmt.Sprint(v)
+			}
+			param = strings.Join(values, " ")
+		}
+		out = append(out, apperr.FieldError{Field: field, Code: code, Param: param})
+	}
+	walk(verr)
+	return out
+}
+
+// fieldPath appends JSON pointer tokens to a field path, e.g. "items",
+// ["0", "quantity"] gives "items[0].quantity".
+func fieldPath(prefix string, location []string) string {
+	path := prefix
+	for _, tok := range location {
+		if _, err := strconv.Atoi(tok); err == nil && path != "" {
+			path += "[" + tok + "]"
+			continue
+		}
+		if path != "" {
+			path += "."
+		}
+		path += tok
+	}
+	return path
+}
@@ -0,0 +1,223 @@
+// Package pagination pages through lists with keyset cursors. Lists are
+// ordered newest first by (created_at, id); a page ends with an opaque cursor
+// naming its last row, and the next page starts strictly after it, so rows
+// inserted meanwhile neither repeat nor shift later pages the way offsets do.
+//
+// Handlers parse Params with FromQuery and render the Page repositories
+// return:
+//
+//	{"items": [...], "nextCursor": "...", "total": 42}
+//
+// nextCursor is omitted on the last page and total unless ?total=true.
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+)
+
+const (
+	// DefaultLimit is the page size when the caller does not ask for one.
+	DefaultLimit = 20
+	// MaxLimit is the largest page size; FromQuery rejects larger limits.
+	MaxLimit = 100
+)
+
+// ErrInvalidCursor is returned for a cursor this package did not produce.
+var ErrInvalidCursor = apperr.Validation("invalid pagination cursor",
+	apperr.FieldError{Field: "cursor", Code: "invalid"})
+
+// Cursor is the position of a row in (created_at, id) order.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type cursorJSON struct {
+	T time.Time `json:"t"`
+	I string    `json:"i"`
+}
+
+// Encode returns c as an opaque, URL-safe string.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(cursorJSON{T: c.CreatedAt, I: c.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a cursor produced by Encode.
+func Decode(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursorJSON
+	if err := json.Unmarshal(b, &c); err != nil || c.I == "" || c.T.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: c.T, ID: c.I}, nil
+}
+
+// Params selects a page.
+type Params struct {
+	Limit int
+	// After is the cursor of the previous page's last row; nil for the
+	// first page.
+	After *Cursor
+	// Offset skips rows of the first page. It predates cursors and is
+	// ignored with one.
+	Offset int
+	// WithTotal asks for the number of rows in the whole list.
+	WithTotal bool
+}
+
+// FromQuery reads limit, cursor, offset and total from the query string.
+// Values that do not parse, and a limit outside 1..MaxLimit, are a
+// validation error; without a limit the page has defaultLimit rows.
+func FromQuery(c *gin.Context, defaultLimit int) (Params, error) {
+	var p Params
+	var err error
+	if p.Limit, err = queryValue(c, "limit", "integer", strconv.Atoi); err != nil {
+		return Params{}, err
+	}
+	if c.Query("limit") != "" {
+		if err := checkLimit(p.Limit); err != nil {
+			return Params{}, err
+		}
+	}
+	if p.Offset, err = queryValue(c, "offset", "integer", strconv.Atoi); err != nil {
+		return Params{}, err
+	}
+	if p.WithTotal, err = queryValue(c, "total", "boolean", strconv.ParseBool); err != nil {
+		return Params{}, err
+	}
+	if s := c.Query("cursor"); s != "" {
+		after, err := Decode(s)
+		if err != nil {
+			return Params{}, err
+		}
+		p.After = after
+	}
+	return p.Normalize(defaultLimit), nil
+}
+
+// queryValue parses the query parameter name with parse; an absent one is
+// the zero value.
+func queryValue[T any](c *gin.Context, name, typ string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	s := c.Query(name)
+	if s == "" {
+		return zero, nil
+	}
+	v, err := parse(s)
+	if err != nil {
+		e := apperr.Validation("invalid pagination parameter",
+			apperr.FieldError{Field: name, Code: "type", Param: typ})
+		e.Err = err
+		return zero, e
+	}
+	return v, nil
+}
+
+// checkLimit returns a validation error naming limit unless it is within
+// 1..MaxLimit.
+func checkLimit(limit int) error {
+	switch {
+	case limit < 1:
+		return apperr.Validation("invalid pagination parameter",
+			apperr.FieldError{Field: "limit", Code: "min", Param: "1"})
+	case limit > MaxLimit:
+		return apperr.Validation("invalid pagination parameter",
+			apperr.FieldError{Field: "limit", Code: "max", Param: strconv.Itoa(MaxLimit)})
+	}
+	return nil
+}
+
+// Normalize returns p with a limit of defaultLimit if it has none and a
+// non-negative offset that is zero after a cursor. Limits from clients are
+// checked by FromQuery; one above MaxLimit that reaches Normalize anyway is
+// lowered to it.
+func (p Params) Normalize(defaultLimit int) Params {
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+	if p.Offset < 0 || p.After != nil {
+		p.Offset = 0
+	}
+	return p
+}
+
+// Where returns the condition selecting the rows after p.After, given the
+// created_at and id columns (which may be qualified); it is "TRUE" for the
+// first page. Queries using it must end with
+//
+//	ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?
+//
+// and take their arguments from Args.
+func (p Params) Where(createdAt, id string) string {
+	if p.After == nil {
+		return "TRUE"
+	}
+	return "(" + createdAt + " < ? OR (" + createdAt + " = ? AND " + id + " < ?))"
+}
+
+// Args returns the arguments of a query built with Where: args (those of the
+// placeholders before the condition), the condition's, then LIMIT and OFFSET.
+// One row more than the limit is fetched to tell NewPage whether there is a
+// next page.
+func (p Params) Args(args ...any) []any {
+	if p.After != nil {
+		args = append(args, p.After.CreatedAt, p.After.CreatedAt, p.After.ID)
+	}
+	return append(args, p.Limit+1, p.Offset)
+}
+
+// Count runs query, which must select a single count, when p asks for the
+// total and returns nil otherwise.
+func (p Params) Count(ctx context.Context, db *sql.DB, query string, args ...any) (*int, error) {
+	if !p.WithTotal {
+		return nil, nil
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Page is one page of a list.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor fetches the next page; empty on the last one.
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Total is the length of the whole list, when asked for.
+	Total *int `json:"total,omitempty"`
+}
+
+// Row is a list item that knows its position in (created_at, id) order.
+type Row interface {
+	Cursor() Cursor
+}
+
+// NewPage returns the page of rows queried with p.Args.
+func NewPage[T Row](rows []T, p Params, total *int) *Page[T] {
+	page := &Page[T]{Items: rows, Total: total}
+	if len(rows) > p.Limit {
+		page.Items = rows[:p.Limit]
+		page.NextCursor = page.Items[p.Limit-1].Cursor().Encode()
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
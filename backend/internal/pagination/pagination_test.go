@@ -0,0 +1,77 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	mw "github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/pagination"
+)
+
+func TestFromQueryLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(mw.ErrorHandler())
+	router.GET("/items", func(c *gin.Context) {
+		page, err := pagination.FromQuery(c, pagination.DefaultLimit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"limit": page.Limit})
+	})
+
+	tests := []struct {
+		query     string
+		wantLimit int                // with status 200
+		wantField *apperr.FieldError // with status 400
+	}{
+		{query: "", wantLimit: pagination.DefaultLimit},
+		{query: "limit=1", wantLimit: 1},
+		{query: "limit=100", wantLimit: 100},
+		{query: "limit=101", wantField: &apperr.FieldError{Field: "limit", Code: "max", Param: "100"}},
+		{query: "limit=100000", wantField: &apperr.FieldError{Field: "limit", Code: "max", Param: "100"}},
+		{query: "limit=0", wantField: &apperr.FieldError{Field: "limit", Code: "min", Param: "1"}},
+		{query: "limit=-5", wantField: &apperr.FieldError{Field: "limit", Code: "min", Param: "1"}},
+		{query: "limit=ten", wantField: &apperr.FieldError{Field: "limit", Code: "type", Param: "integer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil))
+
+			if tt.wantField == nil {
+				var body struct{ Limit int }
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status %d: %s", rec.Code, rec.Body)
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Limit != tt.wantLimit {
+					t.Errorf("limit %d, want %d", body.Limit, tt.wantLimit)
+				}
+				return
+			}
+
+			var problem mw.Problem
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", rec.Code, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != mw.ProblemContentType {
+				t.Errorf("Content-Type %q, want %q", ct, mw.ProblemContentType)
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != apperr.CodeValidation || len(problem.Errors) != 1 || problem.Errors[0] != *tt.wantField {
+				t.Errorf("got %s %+v, want %s [%+v]", problem.Code, problem.Errors, apperr.CodeValidation, *tt.wantField)
+			}
+		})
+	}
+}
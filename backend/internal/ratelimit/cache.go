@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ttlCache keeps up to max strings for ttl each. Entries all live equally
+// long, so insertion order is expiry order: expired entries are dropped from
+// the front, and when the cache is full the oldest goes first.
+type ttlCache struct {
+	ttl time.Duration
+	max int
+
+	mu      sync.Mutex
+	order   *list.List // of *ttlEntry, oldest first
+	entries map[string]*list.Element
+}
+
+type ttlEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+func newTTLCache(ttl time.Duration, max int) *ttlCache {
+	return &ttlCache{ttl: ttl, max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the value of key unless it is missing or expired at now.
+func (c *ttlCache) get(key string, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*ttlEntry)
+	if now.After(e.expires) {
+		return "", false
+	}
+	return e.value, true
+}
+
+// set stores value under key until now plus the TTL.
+func (c *ttlCache) set(key, value string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		e := front.Value.(*ttlEntry)
+		if len(c.entries) < c.max && !now.After(e.expires) {
+			break
+		}
+		c.order.Remove(front)
+		delete(c.entries, e.key)
+	}
+	c.entries[key] = c.order.PushBack(&ttlEntry{key: key, value: value, expires: now.Add(c.ttl)})
+}
@@ -0,0 +1,237 @@
+// Package ratelimit throttles API calls with token buckets. Anonymous callers
+// are limited per client IP and authenticated ones per user; each route can
+// have its own limits, and limits of API-heavy routes grow with the
+// subscription plan of the caller's supplier organization. Buckets live in a Store, in memory or shared through
+// MySQL between instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/global-trade-hub/backend/internal/apperr"
+	"github.com/example/global-trade-hub/backend/internal/authz"
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+	"github.com/example/global-trade-hub/backend/internal/logging"
+)
+
+// Limit allows Requests calls per Per, refilled continuously; bursts of up to
+// Requests calls are allowed after a quiet period. The zero Limit means no
+// limit.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// PerMinute returns a Limit of n requests per minute.
+func PerMinute(n int) Limit { return Limit{Requests: n, Per: time.Minute} }
+
+// PerHour returns a Limit of n requests per hour.
+func PerHour(n int) Limit { return Limit{Requests: n, Per: time.Hour} }
+
+func (l Limit) unlimited() bool { return l.Requests <= 0 || l.Per <= 0 }
+
+// rate is the refill rate in tokens per second.
+func (l Limit) rate() float64 { return float64(l.Requests) / l.Per.Seconds() }
+
+// scale multiplies the number of requests by f, keeping the window.
+func (l Limit) scale(f float64) Limit {
+	if f <= 0 || l.unlimited() {
+		return l
+	}
+	return Limit{Requests: int(math.Ceil(float64(l.Requests) * f)), Per: l.Per}
+}
+
+// Rule is the limit of a route (or of all routes without a rule of their own).
+type Rule struct {
+	// Anonymous limits callers without credentials, per client IP.
+	Anonymous Limit
+	// Authenticated limits signed-in callers and API keys, per user.
+	Authenticated Limit
+	// Tiered scales Authenticated by the multiplier of the subscription plan
+	// of the caller's organization (see WithPlans).
+	Tiered bool
+}
+
+// Policy maps "METHOD /full/route/path" (gin's FullPath) to its rule. Each
+// listed route has its own buckets; all other routes share the default ones.
+type Policy map[string]Rule
+
+// PlanResolver returns the subscription plan of a supplier organization.
+type PlanResolver interface {
+	Plan(ctx context.Context, supplierID string) (string, error)
+}
+
+// PlanFunc adapts a function to PlanResolver.
+type PlanFunc func(ctx context.Context, supplierID string) (string, error)
+
+func (f PlanFunc) Plan(ctx context.Context, supplierID string) (string, error) {
+	return f(ctx, supplierID)
+}
+
+const (
+	// planTTL is how long organizations and plans are cached, so upgrades
+	// and membership changes take effect within it.
+	planTTL = time.Minute
+	// maxCachedPlans bounds each of the organization and plan caches.
+	maxCachedPlans = 10000
+)
+
+// Limiter enforces a Policy with buckets kept in a Store.
+type Limiter struct {
+	store  Store
+	def    Rule
+	policy Policy
+
+	suppliers   authz.SupplierResolver
+	plans       PlanResolver
+	multipliers map[string]float64
+	// orgCache maps users to their organization ("" for none) and
+	// planCache organizations to their plan.
+	orgCache  *ttlCache
+	planCache *ttlCache
+}
+
+// New returns a limiter applying policy, and def to unlisted routes.
+func New(store Store, def Rule, policy Policy) *Limiter {
+	return &Limiter{
+		store:     store,
+		def:       def,
+		policy:    policy,
+		orgCache:  newTTLCache(planTTL, maxCachedPlans),
+		planCache: newTTLCache(planTTL, maxCachedPlans),
+	}
+}
+
+// WithPlans enables tiered rules: the Authenticated limit of a member of a
+// supplier organization (found with suppliers) is multiplied by
+// multipliers[plan], plan being the organization's subscription. Both are
+// cached for a minute, so changes take effect shortly after they are made.
+func (l *Limiter) WithPlans(suppliers authz.SupplierResolver, plans PlanResolver, multipliers map[string]float64) {
+	l.suppliers = suppliers
+	l.plans = plans
+	l.multipliers = multipliers
+}
+
+// Verify reports policy entries that match no route in routes.
+func (p Policy) Verify(routes gin.RoutesInfo) error {
+	registered := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		registered[r.Method+" "+r.Path] = true
+	}
+	for key := range p {
+		if !registered[key] {
+			return fmt.Errorf("ratelimit: policy for unregistered route %s", key)
+		}
+	}
+	return nil
+}
+
+// Middleware takes a token from the caller's bucket for the matched route and
+// rejects the request with 429 when it is empty. Callers identified by
+// JWTAuth (claims in the context) are limited per user, others per client IP.
+// Responses carry RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers, and Retry-After when rejected (set by
+// middleware.ErrorHandler from apperr.RateLimited).
+//
+// If the store fails the request is let through: an outage of the shared
+// backend must not take the API down with it. A nil Limiter limits nothing.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		rule, ok := l.policy[route]
+		scope := route
+		if !ok {
+			rule, scope = l.def, "default"
+		}
+
+		ctx := c.Request.Context()
+		var (
+			limit    Limit
+			identity string
+		)
+		if raw, exists := c.Get("claims"); exists {
+			claims := raw.(*middleware.Claims)
+			limit, identity = rule.Authenticated, "user:"+claims.UserID
+			if rule.Tiered {
+				limit = limit.scale(l.multiplier(ctx, claims))
+			}
+		} else {
+			limit, identity = rule.Anonymous, "ip:"+c.ClientIP()
+		}
+		if limit.unlimited() {
+			c.Next()
+			return
+		}
+
+		res, err := l.store.Take(ctx, scope+"|"+identity, limit, time.Now())
+		if err != nil {
+			logging.FromContext(ctx).Warn("ratelimit: store failed, allowing request", "route", route, "error", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, seconds(limit.Per)))
+		if !res.Allowed {
+			middleware.Abort(c, apperr.RateLimited(res.RetryAfter))
+			return
+		}
+		c.Next()
+	}
+}
+
+// multiplier returns the multiplier of the plan of the caller's
+// organization, 1 without one.
+func (l *Limiter) multiplier(ctx context.Context, claims *middleware.Claims) float64 {
+	if l.plans == nil {
+		return 1
+	}
+	now := time.Now()
+
+	supplierID, ok := l.orgCache.get(claims.UserID, now)
+	if !ok {
+		var err error
+		supplierID, _, err = l.suppliers.SupplierMembership(ctx, claims.UserID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("ratelimit: failed to resolve supplier organization", "error", err)
+			return 1
+		}
+		l.orgCache.set(claims.UserID, supplierID, now)
+	}
+	if supplierID == "" {
+		return 1
+	}
+
+	plan, ok := l.planCache.get(supplierID, now)
+	if !ok {
+		var err error
+		plan, err = l.plans.Plan(ctx, supplierID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("ratelimit: failed to resolve subscription plan", "error", err)
+			return 1
+		}
+		l.planCache.set(supplierID, plan, now)
+	}
+
+	if m, ok := l.multipliers[plan]; ok {
+		return m
+	}
+	return 1
+}
+
+// seconds rounds d up to whole seconds, as the headers require.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/http/middleware"
+)
+
+func TestTTLCache(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		max     int
+		fill    int           // keys "0".."fill-1" are set, one every step
+		step    time.Duration // from start
+		at      time.Duration // after start, when key is read
+		key     string
+		want    string
+		wantOK  bool
+		wantLen int
+	}{
+		{name: "fresh entry", max: 10, fill: 3, step: time.Second, at: 3 * time.Second, key: "2", want: "v2", wantOK: true, wantLen: 3},
+		{name: "expired entry", max: 10, fill: 3, step: time.Second, at: time.Minute + 2*time.Second, key: "0", wantLen: 3},
+		{name: "oldest evicted when full", max: 2, fill: 3, step: time.Second, at: 3 * time.Second, key: "0", wantLen: 2},
+		{name: "newest kept when full", max: 2, fill: 3, step: time.Second, at: 3 * time.Second, key: "2", want: "v2", wantOK: true, wantLen: 2},
+		{name: "expired entries dropped on insert", max: 10, fill: 3, step: 61 * time.Second, at: 122 * time.Second, key: "2", want: "v2", wantOK: true, wantLen: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTTLCache(time.Minute, tt.max)
+			for i := 0; i < tt.fill; i++ {
+				c.set(strconv.Itoa(i), "v"+strconv.Itoa(i), start.Add(time.Duration(i)*tt.step))
+			}
+			got, ok := c.get(tt.key, start.Add(tt.at))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("get(%q) = %q, %v; want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+			}
+			if len(c.entries) != tt.wantLen || c.order.Len() != tt.wantLen {
+				t.Errorf("%d entries (%d in order), want %d", len(c.entries), c.order.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+// fakeSuppliers maps users to organizations and counts lookups.
+type fakeSuppliers struct {
+	orgs  map[string]string
+	calls int
+}
+
+func (f *fakeSuppliers) SupplierMembership(ctx context.Context, userID string) (string, string, error) {
+	f.calls++
+	return f.orgs[userID], "owner", nil
+}
+
+// fakePlans maps organizations to plans and counts lookups.
+type fakePlans struct {
+	plans map[string]string
+	calls map[string]int
+}
+
+func (f *fakePlans) Plan(ctx context.Context, supplierID string) (string, error) {
+	f.calls[supplierID]++
+	return f.plans[supplierID], nil
+}
+
+func TestMultiplierUsesPlanOfOrganization(t *testing.T) {
+	suppliers := &fakeSuppliers{orgs: map[string]string{
+		"owner":  "org-gold",
+		"member": "org-gold",
+		"solo":   "org-free",
+	}}
+	plans := &fakePlans{
+		plans: map[string]string{"org-gold": "gold", "org-free": "free"},
+		calls: map[string]int{},
+	}
+	l := New(NewMemoryStore(), Rule{}, nil)
+	l.WithPlans(suppliers, plans, map[string]float64{"free": 1, "gold": 5})
+
+	tests := []struct {
+		userID string
+		want   float64
+	}{
+		{userID: "owner", want: 5},
+		{userID: "member", want: 5},
+		{userID: "solo", want: 1},
+		{userID: "buyer", want: 1},
+		{userID: "owner", want: 5},
+	}
+	for _, tt := range tests {
+		if got := l.multiplier(context.Background(), &middleware.Claims{UserID: tt.userID}); got != tt.want {
+			t.Errorf("%s: multiplier %v, want %v", tt.userID, got, tt.want)
+		}
+	}
+
+	if plans.calls["org-gold"] != 1 {
+		t.Errorf("plan of org-gold resolved %d times, want once for both members", plans.calls["org-gold"])
+	}
+	if len(plans.calls) != 2 {
+		t.Errorf("plans resolved for %v, want only the two organizations", plans.calls)
+	}
+	if suppliers.calls != 4 {
+		t.Errorf("organizations resolved %d times, want once per user", suppliers.calls)
+	}
+}
@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/example/global-trade-hub/backend/internal/logging"
+)
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left after this request.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a token is available, when not Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	// Take refills the bucket for key as of now and takes one token from it.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// refill computes the bucket after adding the tokens earned since updated and
+// taking one token if possible.
+func refill(tokens float64, updated time.Time, limit Limit, now time.Time) (float64, Result) {
+	capacity := float64(limit.Requests)
+	rate := limit.rate()
+	if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens = math.Min(capacity, tokens+elapsed*rate)
+	}
+	// A plan downgrade can leave more tokens than the new capacity.
+	tokens = math.Min(tokens, capacity)
+
+	res := Result{}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.Reset = time.Duration((capacity - tokens) / rate * float64(time.Second))
+	return tokens, res
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// idle is when the bucket will be full again and can be forgotten.
+	idle time.Time
+}
+
+// memoryStore keeps buckets in process memory. Suitable for a single API
+// instance; with several, each enforces the limits on its own.
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+}
+
+// NewMemoryStore returns an in-memory store.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (m *memoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		m.buckets[key] = b
+	}
+	var res Result
+	b.tokens, res = refill(b.tokens, b.updated, limit, now)
+	b.updated = now
+	b.idle = now.Add(res.Reset)
+
+	// Full buckets hold no state worth keeping; sweep them now and then so
+	// one-off clients do not accumulate.
+	m.takes++
+	if m.takes%1000 == 0 {
+		for k, b := range m.buckets {
+			if !b.idle.After(now) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+	return res, nil
+}
+
+// mySQLStore shares buckets between API instances through the
+// rate_limit_buckets table.
+type mySQLStore struct {
+	db        *sql.DB
+	retention time.Duration
+
+	mu    sync.Mutex
+	takes int
+}
+
+// NewMySQLStore returns a MySQL-backed store. Buckets untouched for longer
+// than retention, which must exceed the longest limit window, are deleted.
+func NewMySQLStore(db *sql.DB, retention time.Duration) Store {
+	return &mySQLStore{db: db, retention: retention}
+}
+
+func (r *mySQLStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	// Create a full bucket first so that concurrent first requests lock the
+	// same row instead of racing on gap locks.
+	if _, err := tx.ExecContext(ctx,
+		`INSERT IGNORE INTO rate_limit_buckets (bucket_key, tokens, updated_at) VALUES (?, ?, ?)`,
+		key, float64(limit.Requests), now.UTC(),
+	); err != nil {
+		return Result{}, err
+	}
+	var (
+		tokens  float64
+		updated time.Time
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ? FOR UPDATE`,
+		key,
+	).Scan(&tokens, &updated)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errors.New("ratelimit: bucket vanished")
+		}
+		return Result{}, err
+	}
+
+	tokens, res := refill(tokens, updated, limit, now)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE rate_limit_buckets SET tokens = ?, updated_at = ? WHERE bucket_key = ?`,
+		tokens, now.UTC(), key,
+	); err != nil {
+		return Result{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Result{}, err
+	}
+
+	r.mu.Lock()
+	r.takes++
+	sweep := r.takes%1000 == 0
+	r.mu.Unlock()
+	if sweep {
+		if _, err := r.db.ExecContext(ctx,
+			`DELETE FROM rate_limit_buckets WHERE updated_at < ?`,
+			now.Add(-r.retention).UTC(),
+		); err != nil {
+			logging.FromContext(ctx).Warn("ratelimit: failed to delete idle buckets", "error", err)
+		}
+	}
+	return res, nil
+}
@@ -4,7 +4,7 @@
 -- 
 -- IMPORTANT:
 -- - All IDs are deterministic so they can be cleaned up by 009_demo_seed.down.sql.
--- - Password for all seeded users (EXCEPT master admin in code) is: password
+-- - Password for all seeded users is: password
 --   (bcrypt hash from public examples).
 
 START TRANSACTION;
@@ -0,0 +1,3 @@
+-- Accounts created by an operator (e.g. the bootstrap admin) must choose their
+-- own password before they can use the API.
+ALTER TABLE users ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE AFTER role;
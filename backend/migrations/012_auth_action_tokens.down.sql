@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_action_tokens;
+ALTER TABLE users DROP COLUMN email_verified_at;
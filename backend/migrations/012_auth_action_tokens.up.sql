@@ -0,0 +1,17 @@
+-- Email verification state lives on the user; supplier KYC rows in
+-- verifications.email_verified are kept in sync when the user verifies.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP NULL AFTER must_change_password;
+
+-- Single-use tokens for email verification and password reset. The token sent
+-- by email is a signed JWT whose jti is the row id; only its hash is stored.
+CREATE TABLE IF NOT EXISTS user_action_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_purpose (user_id, purpose),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,15 @@
+-- Failed login attempts for sliding-window throttling, keyed by
+-- "account:<email>" or "ip:<address>". Used when LOGIN_THROTTLE_STORE=mysql
+-- so that all API instances share the same counters.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    attempt_key VARCHAR(320) NOT NULL,
+    attempted_at TIMESTAMP(3) NOT NULL,
+    INDEX idx_key_time (attempt_key, attempted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Temporary lockouts, keyed like login_attempts.
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    attempt_key VARCHAR(320) PRIMARY KEY,
+    locked_until TIMESTAMP NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
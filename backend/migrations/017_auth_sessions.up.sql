@@ -0,0 +1,26 @@
+-- One row per login (refresh token family, id = family_id). Access tokens
+-- carry the session ID in their "sid" claim and are rejected once the session
+-- is revoked.
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    user_agent VARCHAR(512),
+    ip_address VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    INDEX idx_user_id (user_id),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Sessions that already exist as refresh token families.
+INSERT IGNORE INTO auth_sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at)
+SELECT latest.family_id, latest.user_id, latest.user_agent, latest.ip_address,
+       first.created_at, latest.created_at, latest.expires_at, latest.revoked_at
+FROM refresh_tokens latest
+JOIN (
+    SELECT family_id, MIN(created_at) AS created_at, MAX(created_at) AS last_created_at
+    FROM refresh_tokens
+    GROUP BY family_id
+) first ON first.family_id = latest.family_id AND first.last_created_at = latest.created_at;
@@ -0,0 +1,19 @@
+-- Staff sub-roles (moderator, kyc_reviewer, finance) do not fit the original
+-- role ENUM.
+ALTER TABLE users MODIFY COLUMN role VARCHAR(30) NOT NULL DEFAULT 'buyer';
+
+-- Admin and staff accounts are created from signed, expiring invitations.
+-- Only a SHA-256 hash of the emailed token is stored.
+CREATE TABLE IF NOT EXISTS staff_invitations (
+    id VARCHAR(36) PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(30) NOT NULL,
+    invited_by VARCHAR(36) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_email (email),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+ALTER TABLE messages DROP INDEX idx_sender_supplier_id, DROP COLUMN sender_supplier_id;
+ALTER TABLE rfq_responses DROP COLUMN responded_by;
+ALTER TABLE orders DROP COLUMN status_updated_by;
+ALTER TABLE products DROP COLUMN created_by, DROP COLUMN updated_by;
+DROP TABLE IF EXISTS supplier_invitations;
+DROP TABLE IF EXISTS supplier_members;
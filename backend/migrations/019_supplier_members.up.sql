@@ -0,0 +1,50 @@
+-- A supplier organization (suppliers row) has several user members, each with
+-- a team role: owner, manager, sales or viewer. A user belongs to at most one
+-- organization.
+CREATE TABLE IF NOT EXISTS supplier_members (
+    supplier_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by VARCHAR(36),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (supplier_id, user_id),
+    UNIQUE KEY uniq_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- The user who created each existing profile becomes its owner.
+INSERT IGNORE INTO supplier_members (supplier_id, user_id, role, created_at)
+SELECT id, user_id, 'owner', created_at FROM suppliers;
+
+-- Emailed invitations to join an organization. Only a SHA-256 hash of the
+-- token is stored.
+CREATE TABLE IF NOT EXISTS supplier_invitations (
+    id VARCHAR(36) PRIMARY KEY,
+    supplier_id VARCHAR(36) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by VARCHAR(36) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_supplier_id (supplier_id),
+    UNIQUE KEY uniq_token_hash (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Records are attributed to the organization; the acting member is kept
+-- alongside.
+ALTER TABLE products
+    ADD COLUMN created_by VARCHAR(36) NULL,
+    ADD COLUMN updated_by VARCHAR(36) NULL;
+ALTER TABLE orders ADD COLUMN status_updated_by VARCHAR(36) NULL;
+ALTER TABLE rfq_responses ADD COLUMN responded_by VARCHAR(36) NULL;
+ALTER TABLE messages ADD COLUMN sender_supplier_id VARCHAR(36) NULL, ADD INDEX idx_sender_supplier_id (sender_supplier_id);
+
+-- Older rows stored the owning user's ID as supplier_id.
+UPDATE products p JOIN suppliers s ON p.supplier_id = s.user_id
+SET p.created_by = s.user_id, p.supplier_id = s.id;
+UPDATE orders o JOIN suppliers s ON o.supplier_id = s.user_id SET o.supplier_id = s.id;
+UPDATE rfq_responses r JOIN suppliers s ON r.supplier_id = s.user_id
+SET r.responded_by = s.user_id, r.supplier_id = s.id;
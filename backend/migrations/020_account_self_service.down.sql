@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP INDEX idx_deletion_scheduled_at,
+    DROP COLUMN deleted_at,
+    DROP COLUMN deletion_scheduled_at,
+    DROP COLUMN avatar_url,
+    DROP COLUMN locale;
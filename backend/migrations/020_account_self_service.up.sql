@@ -0,0 +1,9 @@
+-- Self-service profile fields and account deletion. A deletion request is
+-- carried out (the account anonymized) once deletion_scheduled_at has passed;
+-- signing in before then cancels it.
+ALTER TABLE users
+    ADD COLUMN locale VARCHAR(20) NULL,
+    ADD COLUMN avatar_url TEXT NULL,
+    ADD COLUMN deletion_scheduled_at TIMESTAMP NULL,
+    ADD COLUMN deleted_at TIMESTAMP NULL,
+    ADD INDEX idx_deletion_scheduled_at (deletion_scheduled_at);
@@ -0,0 +1,36 @@
+-- Admin impersonation ("login as user"). Each row is one short-lived access
+-- token an admin obtained for a target user; tokens carry its ID in "sid" and
+-- the admin in the "act" claim.
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id VARCHAR(36) PRIMARY KEY,
+    actor_id VARCHAR(36) NOT NULL,
+    subject_id VARCHAR(36) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NULL,
+    INDEX idx_actor_id (actor_id),
+    INDEX idx_subject_id (subject_id),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Audit trail: every request made with an impersonation token, with both
+-- identities.
+CREATE TABLE IF NOT EXISTS impersonation_requests (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    impersonation_id VARCHAR(36) NOT NULL,
+    actor_id VARCHAR(36) NOT NULL,
+    subject_id VARCHAR(36) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    status INT NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    created_at TIMESTAMP(3) NOT NULL,
+    INDEX idx_impersonation_id (impersonation_id, created_at),
+    INDEX idx_actor_id (actor_id),
+    INDEX idx_subject_id (subject_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
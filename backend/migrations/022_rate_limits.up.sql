@@ -0,0 +1,9 @@
+-- Token buckets for API rate limiting, keyed by "<route>|ip:<address>" or
+-- "<route>|user:<id>". Used when RATE_LIMIT_STORE=mysql so that all API
+-- instances share the same limits.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    bucket_key VARCHAR(320) PRIMARY KEY,
+    tokens DOUBLE NOT NULL,
+    updated_at TIMESTAMP(3) NOT NULL,
+    INDEX idx_updated_at (updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
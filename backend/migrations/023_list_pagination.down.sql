@@ -0,0 +1,8 @@
+ALTER TABLE favorites DROP INDEX idx_favorites_user_created;
+ALTER TABLE reviews DROP INDEX idx_reviews_product_created, DROP INDEX idx_reviews_supplier_created;
+ALTER TABLE messages DROP INDEX idx_messages_conversation_created;
+ALTER TABLE notifications DROP INDEX idx_notifications_user_created;
+ALTER TABLE rfqs DROP INDEX idx_rfqs_created_at, DROP INDEX idx_rfqs_buyer_created, DROP INDEX idx_rfqs_supplier_created;
+ALTER TABLE orders DROP INDEX idx_orders_created_at, DROP INDEX idx_orders_buyer_created, DROP INDEX idx_orders_supplier_created;
+ALTER TABLE suppliers DROP INDEX idx_suppliers_created_at;
+ALTER TABLE products DROP INDEX idx_products_created_at;
@@ -0,0 +1,19 @@
+-- Indexes for keyset pagination: lists are read newest first by
+-- (created_at, id), optionally filtered by an owner column. InnoDB appends
+-- the primary key (id) to secondary indexes, so they cover the tiebreaker.
+ALTER TABLE products ADD INDEX idx_products_created_at (created_at);
+ALTER TABLE suppliers ADD INDEX idx_suppliers_created_at (created_at);
+ALTER TABLE orders
+    ADD INDEX idx_orders_created_at (created_at),
+    ADD INDEX idx_orders_buyer_created (buyer_id, created_at),
+    ADD INDEX idx_orders_supplier_created (supplier_id, created_at);
+ALTER TABLE rfqs
+    ADD INDEX idx_rfqs_created_at (created_at),
+    ADD INDEX idx_rfqs_buyer_created (buyer_id, created_at),
+    ADD INDEX idx_rfqs_supplier_created (supplier_id, created_at);
+ALTER TABLE notifications ADD INDEX idx_notifications_user_created (user_id, created_at);
+ALTER TABLE messages ADD INDEX idx_messages_conversation_created (conversation_id, created_at);
+ALTER TABLE reviews
+    ADD INDEX idx_reviews_product_created (product_id, created_at),
+    ADD INDEX idx_reviews_supplier_created (supplier_id, created_at);
+ALTER TABLE favorites ADD INDEX idx_favorites_user_created (user_id, created_at);
@@ -0,0 +1,13 @@
+-- Idempotency-Key records, keyed by SHA-256 of "<user id>, <key>". A record
+-- with status_code 0 is being handled; otherwise it holds the response that
+-- retries with the same key get back until expires_at. Used when
+-- IDEMPOTENCY_STORE=mysql so that all API instances recognize retries.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    record_key CHAR(64) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    status_code SMALLINT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body MEDIUMBLOB,
+    expires_at TIMESTAMP(3) NOT NULL,
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+-- Version of the schema: the number of the latest migration applied. Every
+-- migration from this one on ends by recording its number here, and the
+-- API's readiness probe fails while it is older than the binary expects
+-- (database.SchemaVersion).
+CREATE TABLE IF NOT EXISTS schema_version (
+    id TINYINT PRIMARY KEY,
+    version INT NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO schema_version (id, version) VALUES (1, 25)
+ON DUPLICATE KEY UPDATE version = GREATEST(version, 25);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS oidc_used_states;
+
+UPDATE schema_version SET version = 25 WHERE id = 1;
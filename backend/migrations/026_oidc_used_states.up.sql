@@ -0,0 +1,11 @@
+-- State tokens of OIDC sign-in callbacks that have already been redeemed,
+-- keyed by the token's ID, so a replayed callback is refused. Rows are only
+-- needed until the token expires.
+CREATE TABLE IF NOT EXISTS oidc_used_states (
+    id VARCHAR(36) PRIMARY KEY,
+    expires_at TIMESTAMP(3) NOT NULL,
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO schema_version (id, version) VALUES (1, 26)
+ON DUPLICATE KEY UPDATE version = GREATEST(version, 26);
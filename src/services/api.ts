@@ -30,6 +30,18 @@ export const clearTokens = (): void => {
   localStorage.removeItem(REFRESH_TOKEN_KEY);
 };
 
+// Error response body (RFC 9457 problem details)
+export interface ApiProblem {
+  type: string;
+  title: string;
+  status: number;
+  detail: string;
+  instance?: string;
+  code: string;
+  errors?: { field: string; code: string; param?: string }[];
+  requestId?: string;
+}
+
 // API Error class
 export class ApiError extends Error {
   constructor(
@@ -40,6 +52,16 @@ export class ApiError extends Error {
     super(message);
     this.name = 'ApiError';
   }
+
+  // Stable error code (e.g. "email_already_used") to translate messages by
+  get code(): string | undefined {
+    return (this.data as ApiProblem | undefined)?.code;
+  }
+
+  // Invalid fields of a validation error
+  get fieldErrors(): ApiProblem['errors'] {
+    return (this.data as ApiProblem | undefined)?.errors;
+  }
 }
 
 // Generic API request function
@@ -124,7 +146,7 @@ async function apiRequest<T>(
       const errorData = await response.json().catch(() => ({}));
       throw new ApiError(
         response.status,
-        errorData.error || `HTTP ${response.status}: ${response.statusText}`,
+        errorData.detail || `HTTP ${response.status}: ${response.statusText}`,
         errorData
       );
     }